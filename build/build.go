@@ -0,0 +1,246 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// DefaultTimeout is the default maximum time a build can take
+const DefaultTimeout = 2 * time.Minute
+
+// Toolchain describes how to build a program and how to read the compiler's output.
+//
+// CC, Go, Cargo, Javac and Make are provided. Custom toolchains can be implemented for other languages.
+type Toolchain interface {
+	// Command returns the program to run and its arguments
+	Command() (name string, args []string)
+
+	// ParseDiagnostics extracts warnings and errors from the combined stdout and stderr of the build
+	ParseDiagnostics(output string) []Diagnostic
+}
+
+// Options configures how a build is run
+type Options struct {
+	// WorkDir is the directory the build command is run in
+	WorkDir string
+
+	// Logger is used to print the build command and its diagnostics. Nothing is printed if nil, except debug messages
+	// (like cache hits), which go to the logger set by SetDebugLogger.
+	Logger *logger.Logger
+
+	// WarningsAsErrors makes the build fail if any warnings are reported
+	WarningsAsErrors bool
+
+	// Timeout defaults to DefaultTimeout
+	Timeout time.Duration
+
+	// Cache is used to reuse the outputs of identical builds, if the toolchain implements Cacheable. Builds aren't
+	// cached if nil.
+	Cache *Cache
+}
+
+// Result is the result of a successful build
+type Result struct {
+	// Output is the combined stdout and stderr of the build
+	Output string
+
+	Diagnostics []Diagnostic
+}
+
+// Warnings returns the warnings reported by the compiler
+func (r *Result) Warnings() []Diagnostic {
+	return filterBySeverity(r.Diagnostics, SeverityWarning)
+}
+
+// Error is returned by Run when a build fails
+type Error struct {
+	// Command is the build command, e.g. "clang -o main main.c"
+	Command string
+
+	// Output is the combined stdout and stderr of the build
+	Output string
+
+	Diagnostics []Diagnostic
+
+	// Err is the underlying error, nil if the build only failed because of warnings
+	Err error
+}
+
+// Errors returns the errors reported by the compiler
+func (e *Error) Errors() []Diagnostic {
+	return filterBySeverity(e.Diagnostics, SeverityError)
+}
+
+func (e *Error) Error() string {
+	var message string
+
+	switch {
+	case e.Err == nil:
+		message = fmt.Sprintf("build produced %d warning(s), and warnings are treated as errors", len(filterBySeverity(e.Diagnostics, SeverityWarning)))
+	case len(e.Errors()) > 0:
+		message = fmt.Sprintf("build failed with %d error(s)", len(e.Errors()))
+	default:
+		message = fmt.Sprintf("build failed: %v", e.Err)
+	}
+
+	message += fmt.Sprintf("\nCommand: %s", e.Command)
+
+	// Fall back to the raw output if the compiler's output couldn't be parsed
+	if len(e.Diagnostics) == 0 {
+		if output := strings.TrimSpace(e.Output); output != "" {
+			message += "\n" + output
+		}
+
+		return message
+	}
+
+	for _, diagnostic := range e.Diagnostics {
+		message += "\n" + diagnostic.String()
+	}
+
+	return message
+}
+
+// fallbackDebugLogger is used for debug messages of builds that don't set Options.Logger
+var fallbackDebugLogger atomic.Pointer[logger.Logger]
+
+// SetDebugLogger sets the logger used for debug messages (like cache hits) of builds that don't set Options.Logger,
+// e.g. runner.CompileC. The tester sets it to a logger that only prints in debug mode.
+func SetDebugLogger(l *logger.Logger) {
+	fallbackDebugLogger.Store(l)
+}
+
+// debugLogger returns the logger for debug messages: Logger, or the one set by SetDebugLogger
+func (o Options) debugLogger() *logger.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+
+	return fallbackDebugLogger.Load()
+}
+
+// Run runs the build described by toolchain.
+//
+// If the build fails (or reports warnings with Options.WarningsAsErrors), an *Error is returned.
+func Run(toolchain Toolchain, options Options) (*Result, error) {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	name, args := toolchain.Command()
+	commandString := strings.Join(append([]string{name}, args...), " ")
+
+	result, err := runWithCache(toolchain, commandString, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.WarningsAsErrors && len(result.Warnings()) > 0 {
+		return nil, &Error{Command: commandString, Output: result.Output, Diagnostics: result.Diagnostics}
+	}
+
+	return result, nil
+}
+
+// runWithCache restores the build's outputs from options.Cache if possible, and runs the build otherwise
+func runWithCache(toolchain Toolchain, commandString string, options Options) (*Result, error) {
+	cacheable, ok := toolchain.(Cacheable)
+	if !ok || options.Cache == nil {
+		return runCommand(toolchain, commandString, options)
+	}
+
+	key, err := options.Cache.key(cacheable, options.WorkDir)
+	if err != nil {
+		debugf(options.debugLogger(), "Not using build cache: %v", err)
+		return runCommand(toolchain, commandString, options)
+	}
+
+	if result, ok := options.Cache.restore(key, cacheable, options.WorkDir); ok {
+		debugf(options.debugLogger(), "Build cache hit for %q (key %s)", commandString, key[:12])
+		logDiagnostics(options.Logger, result.Diagnostics)
+		return result, nil
+	}
+
+	debugf(options.debugLogger(), "Build cache miss for %q (key %s)", commandString, key[:12])
+
+	result, err := runCommand(toolchain, commandString, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := options.Cache.store(key, cacheable, options.WorkDir, result); err != nil {
+		debugf(options.debugLogger(), "Failed to store build in cache: %v", err)
+	}
+
+	return result, nil
+}
+
+func runCommand(toolchain Toolchain, commandString string, options Options) (*Result, error) {
+	if options.Logger != nil {
+		options.Logger.Infof("$ %s", commandString)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
+	defer cancel()
+
+	name, args := toolchain.Command()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = options.WorkDir
+	cmd.Env = executable.GetSafeEnvironmentVariables()
+
+	outputBytes, err := cmd.CombinedOutput()
+	output := string(outputBytes)
+	diagnostics := toolchain.ParseDiagnostics(output)
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %v", options.Timeout)
+	}
+
+	logDiagnostics(options.Logger, diagnostics)
+
+	if err != nil {
+		return nil, &Error{Command: commandString, Output: output, Diagnostics: diagnostics, Err: err}
+	}
+
+	return &Result{Output: output, Diagnostics: diagnostics}, nil
+}
+
+func debugf(l *logger.Logger, fstring string, args ...any) {
+	if l != nil {
+		l.Debugf(fstring, args...)
+	}
+}
+
+func logDiagnostics(l *logger.Logger, diagnostics []Diagnostic) {
+	if l == nil {
+		return
+	}
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			l.Errorln(diagnostic.String())
+		} else {
+			l.Infoln(diagnostic.String())
+		}
+	}
+}
+
+func filterBySeverity(diagnostics []Diagnostic, severity Severity) []Diagnostic {
+	var filtered []Diagnostic
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == severity {
+			filtered = append(filtered, diagnostic)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,131 @@
+package build
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireCommand(t *testing.T, name string) {
+	if _, err := exec.LookPath(name); err != nil {
+		t.Skipf("%s not found in PATH", name)
+	}
+}
+
+func writeFile(t *testing.T, dir string, name string, contents string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+}
+
+func TestParseGCCDiagnostics(t *testing.T) {
+	output := `main.c: In function 'main':
+main.c:3:5: warning: implicit declaration of function 'prinf' [-Wimplicit-function-declaration]
+    3 |     prinf("hi");
+      |     ^~~~~
+main.c:4:1: error: expected ';' before '}' token
+main.c:1:10: fatal error: missing.h: No such file or directory
+Main.java:3: error: ';' expected
+src/main.rs:2:13: error[E0425]: cannot find value ` + "`x`" + ` in this scope
+error: could not compile ` + "`hello`"
+
+	assert.Equal(t, []Diagnostic{
+		{File: "main.c", Line: 3, Column: 5, Severity: SeverityWarning, Message: "implicit declaration of function 'prinf' [-Wimplicit-function-declaration]"},
+		{File: "main.c", Line: 4, Column: 1, Severity: SeverityError, Message: "expected ';' before '}' token"},
+		{File: "main.c", Line: 1, Column: 10, Severity: SeverityError, Message: "missing.h: No such file or directory"},
+		{File: "Main.java", Line: 3, Column: 0, Severity: SeverityError, Message: "';' expected"},
+		{File: "src/main.rs", Line: 2, Column: 13, Severity: SeverityError, Message: "cannot find value `x` in this scope"},
+	}, ParseGCCDiagnostics(output))
+}
+
+func TestParseGoDiagnostics(t *testing.T) {
+	output := "# example.com/hello\n./main.go:5:2: declared and not used: x\n./main.go:6:9: undefined: y\n"
+
+	assert.Equal(t, []Diagnostic{
+		{File: "./main.go", Line: 5, Column: 2, Severity: SeverityError, Message: "declared and not used: x"},
+		{File: "./main.go", Line: 6, Column: 9, Severity: SeverityError, Message: "undefined: y"},
+	}, ParseGoDiagnostics(output))
+}
+
+func TestDiagnosticString(t *testing.T) {
+	assert.Equal(t, "main.c:3:5: error: oops", Diagnostic{File: "main.c", Line: 3, Column: 5, Severity: SeverityError, Message: "oops"}.String())
+	assert.Equal(t, "Main.java:3: warning: oops", Diagnostic{File: "Main.java", Line: 3, Severity: SeverityWarning, Message: "oops"}.String())
+}
+
+func TestCCCommand(t *testing.T) {
+	name, args := CC{Sources: []string{"a.c", "b.c"}, Output: "app", Flags: []string{"-Wall"}, Sanitizers: []string{"address", "undefined"}}.Command()
+
+	assert.Equal(t, "clang", name)
+	assert.Equal(t, []string{"-o", "app", "a.c", "b.c", "-Wall", "-fsanitize=address,undefined", "-fno-omit-frame-pointer"}, args)
+}
+
+func TestCCCommand_DefaultOutput(t *testing.T) {
+	toolchain := CC{Compiler: "gcc", Sources: []string{"main.c"}}
+
+	_, args := toolchain.Command()
+	assert.Equal(t, []string{"-o", "a.out", "main.c"}, args)
+	assert.Equal(t, []string{"a.out"}, toolchain.Outputs())
+}
+
+func TestRun_CC(t *testing.T) {
+	requireCommand(t, "gcc")
+	dir := t.TempDir()
+	writeFile(t, dir, "main.c", "int main(void) {\n    int unused;\n    return 0;\n}\n")
+
+	toolchain := CC{Compiler: "gcc", Sources: []string{"main.c"}, Output: "main", Flags: []string{"-Wall"}}
+
+	result, err := Run(toolchain, Options{WorkDir: dir, Logger: logger.GetLogger(true, "[test] ")})
+	require.NoError(t, err)
+	require.Len(t, result.Warnings(), 1)
+	assert.Equal(t, 2, result.Warnings()[0].Line)
+	assert.FileExists(t, filepath.Join(dir, "main"))
+
+	_, err = Run(toolchain, Options{WorkDir: dir, WarningsAsErrors: true})
+	var buildError *Error
+	require.ErrorAs(t, err, &buildError)
+	assert.Nil(t, buildError.Err)
+	assert.Contains(t, err.Error(), "build produced 1 warning(s), and warnings are treated as errors")
+}
+
+func TestRun_CCError(t *testing.T) {
+	requireCommand(t, "gcc")
+	dir := t.TempDir()
+	writeFile(t, dir, "main.c", "int main(void) {\n    return 0\n}\n")
+
+	_, err := Run(CC{Compiler: "gcc", Sources: []string{"main.c"}, Output: "main"}, Options{WorkDir: dir})
+
+	var buildError *Error
+	require.ErrorAs(t, err, &buildError)
+	require.Len(t, buildError.Errors(), 1)
+	assert.Equal(t, "main.c", buildError.Errors()[0].File)
+	assert.Equal(t, 2, buildError.Errors()[0].Line)
+	assert.Contains(t, err.Error(), "build failed with 1 error(s)\nCommand: gcc -o main main.c\nmain.c:2:13: error: expected ';'")
+}
+
+func TestRun_Go(t *testing.T) {
+	requireCommand(t, "go")
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module example.com/hello\n\ngo 1.21\n")
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {\n\tx := 1\n}\n")
+
+	_, err := Run(Go{Output: "hello"}, Options{WorkDir: dir})
+
+	var buildError *Error
+	require.ErrorAs(t, err, &buildError)
+	assert.Equal(t, []Diagnostic{{File: "./main.go", Line: 4, Column: 2, Severity: SeverityError, Message: "declared and not used: x"}}, buildError.Diagnostics)
+}
+
+func TestRun_MakeWithoutDiagnostics(t *testing.T) {
+	requireCommand(t, "make")
+	dir := t.TempDir()
+	writeFile(t, dir, "Makefile", "all:\n\t@echo something went wrong && false\n")
+
+	_, err := Run(Make{}, Options{WorkDir: dir})
+	require.Error(t, err)
+
+	// The raw output is shown when there are no diagnostics to show
+	assert.Contains(t, err.Error(), "build failed: exit status 2\nCommand: make\nsomething went wrong")
+}
@@ -0,0 +1,240 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CacheDirEnvVar is the environment variable that sets the directory used by DefaultCache, so that builds are reused
+// across runs. If it isn't set, builds are only reused within a run.
+const CacheDirEnvVar = "BOOTLLM_BUILD_CACHE_DIR"
+
+// Cacheable is implemented by toolchains whose builds can be cached.
+//
+// The cache key is a hash of the build command, the compiler version and the contents of Inputs. On a cache hit,
+// Outputs are restored instead of running the build.
+type Cacheable interface {
+	Toolchain
+
+	// Inputs returns the files the build depends on, relative to workDir
+	Inputs(workDir string) ([]string, error)
+
+	// Outputs returns the files produced by the build, relative to the working directory
+	Outputs() []string
+
+	// VersionCommand returns the command that prints the compiler version, e.g. "gcc --version"
+	VersionCommand() (name string, args []string)
+}
+
+// Cache stores build outputs keyed by a hash of the build inputs
+type Cache struct {
+	dir string
+
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+// NewCache returns a cache that stores build outputs in dir. The directory is created if it doesn't exist.
+func NewCache(dir string) *Cache {
+	return &Cache{
+		dir:      dir,
+		versions: map[string]string{},
+	}
+}
+
+var (
+	defaultCache     *Cache
+	defaultCacheOnce sync.Once
+
+	// defaultCacheIsTemporary is true if defaultCache is in a temporary directory, removed by RemoveTemporaryCache
+	defaultCacheIsTemporary bool
+)
+
+// DefaultCache returns the cache shared by all builds in this process.
+//
+// Outputs are stored in a temporary directory, so they're only reused within this run. Call RemoveTemporaryCache
+// before exiting to remove it. If BOOTLLM_BUILD_CACHE_DIR is set, outputs are stored there instead and reused across
+// runs; that directory is never cleaned up. Returns nil (no caching) if the temporary directory can't be created.
+func DefaultCache() *Cache {
+	defaultCacheOnce.Do(func() {
+		if dir := os.Getenv(CacheDirEnvVar); dir != "" {
+			defaultCache = NewCache(dir)
+			return
+		}
+
+		dir, err := os.MkdirTemp("", "bootllm-build-cache-")
+		if err != nil {
+			return
+		}
+
+		defaultCache = NewCache(dir)
+		defaultCacheIsTemporary = true
+	})
+
+	return defaultCache
+}
+
+// RemoveTemporaryCache removes the temporary directory used by DefaultCache, if it was created. The directory set by
+// BOOTLLM_BUILD_CACHE_DIR is kept.
+func RemoveTemporaryCache() {
+	if defaultCache != nil && defaultCacheIsTemporary {
+		os.RemoveAll(defaultCache.dir)
+	}
+}
+
+// cachedBuild is stored alongside the outputs of a build
+type cachedBuild struct {
+	Output      string       `json:"output"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// key returns the cache key for building toolchain in workDir
+func (c *Cache) key(toolchain Cacheable, workDir string) (string, error) {
+	hash := sha256.New()
+
+	name, args := toolchain.Command()
+	fmt.Fprintf(hash, "command\x00%s\x00%s\x00", name, strings.Join(args, "\x00"))
+
+	version, err := c.compilerVersion(toolchain)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(hash, "version\x00%s\x00", version)
+
+	inputs, err := toolchain.Inputs(workDir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, input := range inputs {
+		file, err := os.Open(resolvePath(workDir, input))
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(hash, "input\x00%s\x00", input)
+		_, err = io.Copy(hash, file)
+		file.Close()
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// compilerVersion returns the output of the toolchain's version command, memoized per command
+func (c *Cache) compilerVersion(toolchain Cacheable) (string, error) {
+	name, args := toolchain.VersionCommand()
+	command := strings.Join(append([]string{name}, args...), " ")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version, ok := c.versions[command]; ok {
+		return version, nil
+	}
+
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to get compiler version using %q: %v", command, err)
+	}
+
+	c.versions[command] = string(output)
+	return string(output), nil
+}
+
+// restore copies the outputs stored under key to workDir. Returns false if key isn't in the cache.
+func (c *Cache) restore(key string, toolchain Cacheable, workDir string) (*Result, bool) {
+	entryDir := filepath.Join(c.dir, key)
+
+	metadata, err := os.ReadFile(filepath.Join(entryDir, "build.json"))
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cachedBuild
+	if err := json.Unmarshal(metadata, &cached); err != nil {
+		return nil, false
+	}
+
+	for i, output := range toolchain.Outputs() {
+		if err := copyFile(filepath.Join(entryDir, fmt.Sprintf("output-%d", i)), resolvePath(workDir, output)); err != nil {
+			return nil, false
+		}
+	}
+
+	return &Result{Output: cached.Output, Diagnostics: cached.Diagnostics}, true
+}
+
+// store copies the outputs of a successful build from workDir to the cache
+func (c *Cache) store(key string, toolchain Cacheable, workDir string, result *Result) error {
+	// Entries are written to a temporary directory and renamed, so that concurrent builds never see partial entries
+	tempDir, err := os.MkdirTemp(c.dir, key+".tmp")
+	if err != nil {
+		if err := os.MkdirAll(c.dir, 0755); err != nil {
+			return err
+		}
+
+		if tempDir, err = os.MkdirTemp(c.dir, key+".tmp"); err != nil {
+			return err
+		}
+	}
+	defer os.RemoveAll(tempDir)
+
+	for i, output := range toolchain.Outputs() {
+		if err := copyFile(resolvePath(workDir, output), filepath.Join(tempDir, fmt.Sprintf("output-%d", i))); err != nil {
+			return err
+		}
+	}
+
+	metadata, err := json.Marshal(cachedBuild{Output: result.Output, Diagnostics: result.Diagnostics})
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "build.json"), metadata, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tempDir, filepath.Join(c.dir, key))
+}
+
+// copyFile copies src to dst, keeping the file mode (so that executables stay executable)
+func copyFile(src string, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	// Remove the destination first, in case it's a running executable or read-only
+	os.Remove(dst)
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
+
+// resolvePath resolves path relative to workDir, unless it's absolute
+func resolvePath(workDir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(workDir, path)
+}
@@ -0,0 +1,148 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingToolchain is a cacheable toolchain that "compiles" input.txt by copying it to output.txt, and counts how
+// many times it was run
+type countingToolchain struct {
+	counterPath string
+	flag        string
+}
+
+func (c countingToolchain) Command() (string, []string) {
+	return "sh", []string{"-c", `echo run >> "$0" && cp input.txt output.txt && echo "input.txt:1:1: warning: copied"`, c.counterPath, c.flag}
+}
+
+func (c countingToolchain) ParseDiagnostics(output string) []Diagnostic {
+	return ParseGCCDiagnostics(output)
+}
+
+func (c countingToolchain) Inputs(workDir string) ([]string, error) {
+	return []string{"input.txt"}, nil
+}
+
+func (c countingToolchain) Outputs() []string {
+	return []string{"output.txt"}
+}
+
+func (c countingToolchain) VersionCommand() (string, []string) {
+	return "echo", []string{"1.0"}
+}
+
+func (c countingToolchain) runCount(t *testing.T) int {
+	data, err := os.ReadFile(c.counterPath)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	require.NoError(t, err)
+	return len(data) / len("run\n")
+}
+
+func TestCache(t *testing.T) {
+	workDir := t.TempDir()
+	toolchain := countingToolchain{counterPath: filepath.Join(t.TempDir(), "count")}
+	options := Options{WorkDir: workDir, Cache: NewCache(t.TempDir())}
+
+	writeFile(t, workDir, "input.txt", "v1")
+
+	result, err := Run(toolchain, options)
+	require.NoError(t, err)
+	assert.Equal(t, 1, toolchain.runCount(t))
+
+	// Identical inputs: the output is restored from the cache, along with diagnostics
+	require.NoError(t, os.Remove(filepath.Join(workDir, "output.txt")))
+	cachedResult, err := Run(toolchain, options)
+	require.NoError(t, err)
+	assert.Equal(t, 1, toolchain.runCount(t))
+	assert.Equal(t, result, cachedResult)
+	assert.FileExists(t, filepath.Join(workDir, "output.txt"))
+
+	// Cached warnings still fail the build with WarningsAsErrors
+	_, err = Run(toolchain, Options{WorkDir: workDir, Cache: options.Cache, WarningsAsErrors: true})
+	assert.Error(t, err)
+	assert.Equal(t, 1, toolchain.runCount(t))
+
+	// Changed inputs
+	writeFile(t, workDir, "input.txt", "v2")
+	_, err = Run(toolchain, options)
+	require.NoError(t, err)
+	assert.Equal(t, 2, toolchain.runCount(t))
+
+	output, err := os.ReadFile(filepath.Join(workDir, "output.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(output))
+
+	// Changed arguments
+	toolchain.flag = "-O2"
+	_, err = Run(toolchain, options)
+	require.NoError(t, err)
+	assert.Equal(t, 3, toolchain.runCount(t))
+}
+
+func TestCache_HitsLoggedWithoutLogger(t *testing.T) {
+	sink := logger.NewMemorySink()
+	SetDebugLogger(logger.GetLoggerWithSink(true, "[build] ", sink))
+	defer SetDebugLogger(nil)
+
+	workDir := t.TempDir()
+	toolchain := countingToolchain{counterPath: filepath.Join(t.TempDir(), "count")}
+	options := Options{WorkDir: workDir, Cache: NewCache(t.TempDir())}
+	writeFile(t, workDir, "input.txt", "v1")
+
+	for i := 0; i < 2; i++ {
+		_, err := Run(toolchain, options)
+		require.NoError(t, err)
+	}
+
+	assert.Contains(t, sink.String(), "Build cache miss")
+	assert.Contains(t, sink.String(), "Build cache hit")
+}
+
+func TestCache_SharedAcrossWorkDirs(t *testing.T) {
+	requireCommand(t, "gcc")
+	cache := NewCache(t.TempDir())
+	toolchain := CC{Compiler: "gcc", Sources: []string{"main.c"}, Output: "main"}
+
+	for i := 0; i < 2; i++ {
+		workDir := t.TempDir()
+		writeFile(t, workDir, "main.c", "int main(void) { return 0; }\n")
+
+		_, err := Run(toolchain, Options{WorkDir: workDir, Cache: cache})
+		require.NoError(t, err)
+
+		info, err := os.Stat(filepath.Join(workDir, "main"))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode().Perm()&0111, "restored executable should be executable")
+	}
+
+	entries, err := os.ReadDir(cache.dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestCache_HeadersArePartOfKey(t *testing.T) {
+	workDir := t.TempDir()
+	writeFile(t, workDir, "main.c", "#include \"util.h\"\n")
+	writeFile(t, workDir, "util.h", "#define X 1\n")
+
+	cache := NewCache(t.TempDir())
+	toolchain := CC{Sources: []string{"main.c"}, Output: "main"}
+	cache.versions["clang --version"] = "clang 1.0"
+
+	key1, err := cache.key(toolchain, workDir)
+	require.NoError(t, err)
+
+	writeFile(t, workDir, "util.h", "#define X 2\n")
+	key2, err := cache.key(toolchain, workDir)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, key1, key2)
+}
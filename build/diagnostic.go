@@ -0,0 +1,111 @@
+package build
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity is the severity of a Diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Diagnostic is a single warning or error reported by a compiler
+type Diagnostic struct {
+	File string
+	Line int
+
+	// Column is 0 if the compiler didn't report one
+	Column int
+
+	Severity Severity
+	Message  string
+}
+
+// String formats the diagnostic like compilers do, e.g. "main.c:3:5: error: expected ';'"
+func (d Diagnostic) String() string {
+	location := fmt.Sprintf("%s:%d", d.File, d.Line)
+	if d.Column > 0 {
+		location += fmt.Sprintf(":%d", d.Column)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", location, d.Severity, d.Message)
+}
+
+// gccStyleRegex matches diagnostics in the format used by gcc, clang, javac and cargo (with --message-format=short):
+//
+//	main.c:3:5: error: expected ';' before '}' token
+//	Main.java:3: error: ';' expected
+//	src/main.rs:2:13: error[E0425]: cannot find value `x` in this scope
+var gccStyleRegex = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)? (fatal error|error|warning|note)(?:\[[^\]]*\])?: (.*)$`)
+
+// goStyleRegex matches diagnostics reported by the Go compiler, which don't include a severity:
+//
+//	./main.go:5:2: declared and not used: x
+var goStyleRegex = regexp.MustCompile(`^(.+?\.go):(\d+):(\d+): (.*)$`)
+
+// ParseGCCDiagnostics parses diagnostics in the format used by gcc and clang. Lines that aren't diagnostics (source
+// excerpts, "In function" headers, etc.) are ignored.
+func ParseGCCDiagnostics(output string) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, line := range splitLines(output) {
+		match := gccStyleRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		severity := Severity(match[4])
+		if severity == "fatal error" {
+			severity = SeverityError
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			File:     match[1],
+			Line:     atoi(match[2]),
+			Column:   atoi(match[3]),
+			Severity: severity,
+			Message:  match[5],
+		})
+	}
+
+	return diagnostics
+}
+
+// ParseGoDiagnostics parses diagnostics reported by `go build`. All of them are errors.
+func ParseGoDiagnostics(output string) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, line := range splitLines(output) {
+		match := goStyleRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			File:     match[1],
+			Line:     atoi(match[2]),
+			Column:   atoi(match[3]),
+			Severity: SeverityError,
+			Message:  match[4],
+		})
+	}
+
+	return diagnostics
+}
+
+func splitLines(output string) []string {
+	return strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+}
+
+// atoi returns 0 for empty or invalid numbers
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,221 @@
+package build
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// CC compiles C/C++ sources using gcc or clang
+//
+//	result, err := build.Run(build.CC{
+//	    Sources:    []string{"main.c"},
+//	    Output:     "main",
+//	    Flags:      []string{"-Wall", "-std=c11"},
+//	    Sanitizers: []string{"address", "undefined"},
+//	}, build.Options{WorkDir: harness.SubmissionDir, Logger: harness.Logger})
+type CC struct {
+	// Compiler is the compiler to use ("gcc", "clang", "g++", etc.). Defaults to "clang".
+	Compiler string
+
+	Sources []string
+
+	// Output is the path of the compiled program. Defaults to "a.out", like the compiler itself.
+	Output string
+
+	Flags []string
+
+	// Sanitizers are passed as -fsanitize=..., e.g. "address", "undefined"
+	Sanitizers []string
+}
+
+func (c CC) Command() (string, []string) {
+	compiler := c.Compiler
+	if compiler == "" {
+		compiler = "clang"
+	}
+
+	// Flags come after the sources, so that linker flags like -lm work
+	args := append([]string{"-o", c.output()}, c.Sources...)
+	args = append(args, c.Flags...)
+	if len(c.Sanitizers) > 0 {
+		args = append(args, "-fsanitize="+strings.Join(c.Sanitizers, ","), "-fno-omit-frame-pointer")
+	}
+
+	return compiler, args
+}
+
+func (c CC) ParseDiagnostics(output string) []Diagnostic {
+	return ParseGCCDiagnostics(output)
+}
+
+// Inputs returns the sources and all header files in workDir
+func (c CC) Inputs(workDir string) ([]string, error) {
+	headers, err := findFiles(workDir, ".h", ".hh", ".hpp")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append([]string{}, c.Sources...), headers...), nil
+}
+
+func (c CC) Outputs() []string {
+	return []string{c.output()}
+}
+
+func (c CC) output() string {
+	if c.Output == "" {
+		return "a.out"
+	}
+
+	return c.Output
+}
+
+func (c CC) VersionCommand() (string, []string) {
+	name, _ := c.Command()
+	return name, []string{"--version"}
+}
+
+// Go builds a Go package using `go build`
+type Go struct {
+	// Package defaults to "."
+	Package string
+
+	Output string
+	Flags  []string
+}
+
+func (g Go) Command() (string, []string) {
+	pkg := g.Package
+	if pkg == "" {
+		pkg = "."
+	}
+
+	args := append([]string{"build", "-o", g.Output}, g.Flags...)
+	return "go", append(args, pkg)
+}
+
+func (g Go) ParseDiagnostics(output string) []Diagnostic {
+	return ParseGoDiagnostics(output)
+}
+
+// Inputs returns all Go files and module files in workDir
+func (g Go) Inputs(workDir string) ([]string, error) {
+	return findFiles(workDir, ".go", "go.mod", "go.sum")
+}
+
+func (g Go) Outputs() []string {
+	return []string{g.Output}
+}
+
+func (g Go) VersionCommand() (string, []string) {
+	return "go", []string{"version"}
+}
+
+// Cargo builds a Rust crate using `cargo build`
+type Cargo struct {
+	Release bool
+	Flags   []string
+}
+
+func (c Cargo) Command() (string, []string) {
+	// The short format prints one line per diagnostic, in the same format as gcc
+	args := []string{"build", "--message-format=short"}
+	if c.Release {
+		args = append(args, "--release")
+	}
+
+	return "cargo", append(args, c.Flags...)
+}
+
+func (c Cargo) ParseDiagnostics(output string) []Diagnostic {
+	return ParseGCCDiagnostics(output)
+}
+
+// Javac compiles Java sources using javac
+type Javac struct {
+	Sources []string
+
+	// OutputDir is passed as -d, if set
+	OutputDir string
+
+	Flags []string
+}
+
+func (j Javac) Command() (string, []string) {
+	args := append([]string{}, j.Flags...)
+	if j.OutputDir != "" {
+		args = append(args, "-d", j.OutputDir)
+	}
+
+	return "javac", append(args, j.Sources...)
+}
+
+func (j Javac) ParseDiagnostics(output string) []Diagnostic {
+	return ParseGCCDiagnostics(output)
+}
+
+// Make runs `make`. Diagnostics are parsed assuming make invokes gcc or clang.
+type Make struct {
+	// Targets to build, the default target is built if empty
+	Targets []string
+
+	Flags []string
+}
+
+func (m Make) Command() (string, []string) {
+	return "make", append(append([]string{}, m.Flags...), m.Targets...)
+}
+
+func (m Make) ParseDiagnostics(output string) []Diagnostic {
+	return ParseGCCDiagnostics(output)
+}
+
+// findFiles returns the paths (relative to dir) of files whose names end with one of suffixes, skipping hidden
+// directories like .git
+func findFiles(dir string, suffixes ...string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(entry.Name(), suffix) {
+				relativePath, err := filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+
+				files = append(files, relativePath)
+				break
+			}
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+// Shell runs an arbitrary build command using sh, e.g. the `build` command from bootllm.yml. Diagnostics are parsed
+// in both the gcc and the Go formats.
+type Shell struct {
+	Script string
+}
+
+func (s Shell) Command() (string, []string) {
+	return "sh", []string{"-c", s.Script}
+}
+
+func (s Shell) ParseDiagnostics(output string) []Diagnostic {
+	return append(ParseGCCDiagnostics(output), ParseGoDiagnostics(output)...)
+}
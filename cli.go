@@ -0,0 +1,387 @@
+package tester_utils
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bootllm/tester-utils/internal"
+	"github.com/bootllm/tester-utils/tester_context"
+	"github.com/bootllm/tester-utils/tester_definition"
+)
+
+// Exit codes returned by Run
+const (
+	ExitSuccess    = 0 // All stages passed
+	ExitFailure    = 1 // A stage or the build failed
+	ExitUsageError = 2 // Invalid command-line arguments
+)
+
+// Commands supported by Run
+const (
+	CommandRun     = "run"
+	CommandList    = "list"
+	CommandVersion = "version"
+	CommandDoctor  = "doctor"
+	CommandHelp    = "help"
+)
+
+// cliCommand describes a subcommand and the flags it accepts
+type cliCommand struct {
+	name        string
+	description string
+	flags       []string
+}
+
+var cliCommands = []cliCommand{
+	{CommandRun, "Run stages (default)", []string{"stage", "from", "to", "until", "exclude", "failed", "resume", "dir", "watch", "locale"}},
+	{CommandList, "List stages", []string{"json"}},
+	{CommandVersion, "Show version", nil},
+	{CommandDoctor, "Diagnose common problems with the environment", []string{"dir"}},
+	{CommandHelp, "Show this help message", nil},
+}
+
+// commandFlags are flags that select a command, kept for compatibility (e.g. `--list` is the same as `list`)
+var commandFlags = []struct {
+	flag    string
+	command string
+}{
+	{"help", CommandHelp},
+	{"version", CommandVersion},
+	{"list", CommandList},
+}
+
+// shorthandFlags maps shorthand flags to their long names
+var shorthandFlags = map[string]string{
+	"s": "stage",
+	"d": "dir",
+	"h": "help",
+	"v": "version",
+}
+
+// UsageError is returned by ParseArgs when the command line is invalid
+type UsageError struct {
+	Message string
+}
+
+func (e *UsageError) Error() string {
+	return e.Message
+}
+
+// CLIArgs holds parsed command-line arguments
+type CLIArgs struct {
+	Command string // Command to run, one of the Command* constants
+	Stage   string // Stages to run: comma-separated slugs, globs or /regexes/ (empty = run all)
+	From    string // Run stages starting at this slug
+	To      string // Run stages up to this slug
+	Until   string // Run all stages up to this slug, like the platform does
+	Exclude string // Stages to skip, same format as Stage
+	Rerun   string // "failed" or "resume" to use the results of previous runs (see tester_context.RerunFailed)
+	Dir     string // Working directory (empty = current dir)
+	JSON    bool   // Print the stage list as JSON
+	Watch   bool   // Re-run the selected stages whenever a file in the working directory changes
+	Locale  string // Language of tester messages, like "en" or "zh" (see i18n.ParseLocale)
+}
+
+// ParseArgs parses command-line arguments
+// Supports:
+//   - ./tester [stage]           # positional argument, same as `./tester run [stage]`
+//   - ./tester --stage <slug>    # flag
+//   - ./tester --stage 'mario-*'  # glob, /regex/ or comma-separated list
+//   - ./tester --from a --to b   # range (inclusive)
+//   - ./tester --until <slug>    # all stages up to <slug>
+//   - ./tester --exclude <slug>  # skip stages
+//   - ./tester --failed          # only stages that didn't pass last time
+//   - ./tester --resume          # continue from the first stage that didn't pass
+//   - ./tester -d <dir>          # specify directory
+//   - ./tester --watch hello     # re-run on file changes
+//   - ./tester --locale zh       # show tester messages in Chinese
+//   - ./tester list|version|doctor|help
+//
+// Flags and arguments can be given in any order. Unknown flags, flags that don't apply to the command and unexpected
+// arguments return a *UsageError.
+func ParseArgs(args []string) (CLIArgs, error) {
+	result := CLIArgs{}
+	var help, version, list, failed, resume bool
+
+	// Create a new FlagSet to avoid global state
+	fs := flag.NewFlagSet("tester", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&result.Stage, "stage", "", "Stages to run")
+	fs.StringVar(&result.Stage, "s", "", "Stages to run (shorthand)")
+	fs.StringVar(&result.From, "from", "", "Run stages starting at this slug")
+	fs.StringVar(&result.To, "to", "", "Run stages up to this slug")
+	fs.StringVar(&result.Until, "until", "", "Run all stages up to this slug")
+	fs.StringVar(&result.Exclude, "exclude", "", "Stages to skip")
+	fs.BoolVar(&failed, "failed", false, "Run only stages that didn't pass last time")
+	fs.BoolVar(&resume, "resume", false, "Continue from the first stage that didn't pass last time")
+	fs.StringVar(&result.Dir, "dir", "", "Working directory")
+	fs.StringVar(&result.Dir, "d", "", "Working directory (shorthand)")
+	fs.BoolVar(&help, "help", false, "Show help")
+	fs.BoolVar(&help, "h", false, "Show help (shorthand)")
+	fs.BoolVar(&version, "version", false, "Show version")
+	fs.BoolVar(&version, "v", false, "Show version (shorthand)")
+	fs.BoolVar(&list, "list", false, "List stages")
+	fs.BoolVar(&result.JSON, "json", false, "Print the stage list as JSON (with list)")
+	fs.BoolVar(&result.Watch, "watch", false, "Re-run stages when files change")
+	fs.StringVar(&result.Locale, "locale", "", "Language of tester messages")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return CLIArgs{}, newFlagError(fs, err)
+	}
+
+	setFlags := []string{}
+	fs.Visit(func(f *flag.Flag) {
+		name := longFlagName(f.Name)
+		if !slices.Contains(setFlags, name) {
+			setFlags = append(setFlags, name)
+		}
+	})
+
+	if len(positional) > 0 && findCommand(positional[0]) != nil {
+		result.Command = positional[0]
+		positional = positional[1:]
+	}
+
+	for _, commandFlag := range commandFlags {
+		if !slices.Contains(setFlags, commandFlag.flag) {
+			continue
+		}
+
+		// --help wins over any command, so that `tester list --help` works
+		if result.Command == CommandHelp || commandFlag.command == CommandHelp {
+			result.Command = CommandHelp
+		} else if result.Command != "" && result.Command != commandFlag.command {
+			return CLIArgs{}, &UsageError{Message: fmt.Sprintf("--%s can't be used with the %s command", commandFlag.flag, result.Command)}
+		} else {
+			result.Command = commandFlag.command
+		}
+
+		setFlags = slices.DeleteFunc(setFlags, func(name string) bool { return name == commandFlag.flag })
+	}
+
+	if result.Command == "" {
+		result.Command = CommandRun
+	}
+
+	// Help ignores everything else
+	if result.Command == CommandHelp {
+		return CLIArgs{Command: CommandHelp}, nil
+	}
+
+	command := findCommand(result.Command)
+	for _, name := range setFlags {
+		if !slices.Contains(command.flags, name) {
+			return CLIArgs{}, &UsageError{Message: fmt.Sprintf("--%s can't be used with the %s command", name, command.name)}
+		}
+	}
+
+	switch {
+	case failed && resume:
+		return CLIArgs{}, &UsageError{Message: "--failed and --resume can't be used together"}
+	case failed:
+		result.Rerun = tester_context.RerunFailed
+	case resume:
+		result.Rerun = tester_context.RerunResume
+	}
+
+	// Positional stage shortcut: `./tester hello` is the same as `./tester --stage hello`
+	if command.name == CommandRun && result.Stage == "" && len(positional) > 0 {
+		result.Stage = positional[0]
+		positional = positional[1:]
+	}
+
+	if len(positional) > 0 {
+		return CLIArgs{}, newUnexpectedArgumentError(command.name, positional[0])
+	}
+
+	return result, nil
+}
+
+// parseInterspersed parses flags with fs, allowing flags after positional arguments (the flag package stops at the
+// first positional argument). Arguments after "--" are always positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		if consumed := args[:len(args)-len(rest)]; len(consumed) > 0 && consumed[len(consumed)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// newFlagError converts errors from the flag package into a UsageError with a suggestion for misspelled flags
+func newFlagError(fs *flag.FlagSet, err error) *UsageError {
+	message := err.Error()
+
+	if name, ok := strings.CutPrefix(message, "flag provided but not defined: -"); ok {
+		longNames := []string{}
+		fs.VisitAll(func(f *flag.Flag) {
+			if len(f.Name) > 1 {
+				longNames = append(longNames, f.Name)
+			}
+		})
+
+		message = fmt.Sprintf("unknown flag %s", displayFlagName(name))
+		if match, ok := internal.ClosestMatch(name, longNames); ok {
+			message += fmt.Sprintf(". Did you mean %q?", "--"+match)
+		}
+
+		return &UsageError{Message: message}
+	}
+
+	if name, ok := strings.CutPrefix(message, "flag needs an argument: -"); ok {
+		return &UsageError{Message: fmt.Sprintf("flag %s needs a value", displayFlagName(name))}
+	}
+
+	return &UsageError{Message: message}
+}
+
+func newUnexpectedArgumentError(command string, argument string) *UsageError {
+	message := fmt.Sprintf("unexpected argument %q", argument)
+
+	if command == CommandRun {
+		message += ". To run several stages, use --stage with a comma-separated list (e.g. --stage hello,mario)"
+	} else {
+		message += fmt.Sprintf(". The %s command doesn't take arguments", command)
+	}
+
+	commandNames := make([]string, len(cliCommands))
+	for i, c := range cliCommands {
+		commandNames[i] = c.name
+	}
+
+	return &UsageError{Message: message + internal.DidYouMean(argument, commandNames)}
+}
+
+func findCommand(name string) *cliCommand {
+	for i := range cliCommands {
+		if cliCommands[i].name == name {
+			return &cliCommands[i]
+		}
+	}
+
+	return nil
+}
+
+func longFlagName(name string) string {
+	if longName, ok := shorthandFlags[name]; ok {
+		return longName
+	}
+
+	return name
+}
+
+// displayFlagName returns the flag as users usually type it: -s, --stage
+func displayFlagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+
+	return "--" + name
+}
+
+// MergeArgsIntoEnv merges CLI args into env map (CLI args take precedence)
+func MergeArgsIntoEnv(args CLIArgs, env map[string]string) map[string]string {
+	result := make(map[string]string)
+	for k, v := range env {
+		result[k] = v
+	}
+
+	if args.Stage != "" {
+		result["BOOTLLM_STAGE"] = args.Stage
+	}
+	if args.From != "" {
+		result["BOOTLLM_STAGE_FROM"] = args.From
+	}
+	if args.To != "" {
+		result["BOOTLLM_STAGE_TO"] = args.To
+	}
+	if args.Until != "" {
+		result["BOOTLLM_STAGE_UNTIL"] = args.Until
+	}
+	if args.Exclude != "" {
+		result["BOOTLLM_STAGE_EXCLUDE"] = args.Exclude
+	}
+	if args.Rerun != "" {
+		result["BOOTLLM_RERUN"] = args.Rerun
+	}
+	if args.Dir != "" {
+		result["BOOTLLM_REPOSITORY_DIR"] = args.Dir
+	}
+	if args.Locale != "" {
+		result["BOOTLLM_LOCALE"] = args.Locale
+	}
+
+	return result
+}
+
+// getEnvMap converts os.Environ() to a map
+func getEnvMap() map[string]string {
+	env := make(map[string]string)
+	for _, e := range os.Environ() {
+		pair := strings.SplitN(e, "=", 2)
+		if len(pair) == 2 {
+			env[pair[0]] = pair[1]
+		}
+	}
+	return env
+}
+
+// printUsage prints help message
+func printUsage(w io.Writer, definition tester_definition.TesterDefinition) {
+	fmt.Fprintln(w, "Usage: tester [command] [options] [stage]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, command := range cliCommands {
+		fmt.Fprintf(w, "  %-8s  %s\n", command.name, command.description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "  -s, --stage <slug>  Run specific stages (comma list, glob like 'mario-*' or /regex/)")
+	fmt.Fprintln(w, "  --from <slug>       Run stages starting at <slug>")
+	fmt.Fprintln(w, "  --to <slug>         Run stages up to <slug> (with --from for a range)")
+	fmt.Fprintln(w, "  --until <slug>      Run all stages up to and including <slug>")
+	fmt.Fprintln(w, "  --exclude <slugs>   Skip stages (same format as --stage)")
+	fmt.Fprintln(w, "  --failed            Run only stages that didn't pass last time")
+	fmt.Fprintln(w, "  --resume            Continue from the first stage that didn't pass last time")
+	fmt.Fprintln(w, "  -d, --dir <path>    Set working directory (default: current dir)")
+	fmt.Fprintln(w, "  --watch             Re-run stages whenever a file changes")
+	fmt.Fprintln(w, "  --locale <en|zh>    Language of tester messages (default: BOOTLLM_LOCALE or bootllm.yml)")
+	fmt.Fprintln(w, "  --json              Print the stage list as JSON (with list)")
+	fmt.Fprintln(w, "  -h, --help          Show this help message")
+	fmt.Fprintln(w, "  -v, --version       Show version")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Examples:")
+	fmt.Fprintln(w, "  tester              # Run all stages")
+	fmt.Fprintln(w, "  tester hello        # Run 'hello' stage")
+	fmt.Fprintln(w, "  tester -s hello     # Same as above")
+	fmt.Fprintln(w, "  tester --from hello --to mario  # Run a range of stages")
+	fmt.Fprintln(w, "  tester --until mario --exclude hello")
+	fmt.Fprintln(w, "  tester --watch hello  # Re-run 'hello' on every save")
+	fmt.Fprintln(w, "  tester list         # Show stage details")
+	fmt.Fprintln(w, "  tester doctor       # Check your environment")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes: 0 = passed, 1 = failed, 2 = invalid arguments")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Available stages:")
+	for _, tc := range definition.TestCases {
+		fmt.Fprintf(w, "  %s\n", tc.Slug)
+	}
+}
@@ -0,0 +1,94 @@
+package tester_utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected CLIArgs
+	}{
+		{[]string{}, CLIArgs{Command: CommandRun}},
+		{[]string{"hello"}, CLIArgs{Command: CommandRun, Stage: "hello"}},
+		{[]string{"run", "hello"}, CLIArgs{Command: CommandRun, Stage: "hello"}},
+		{[]string{"-s", "hello", "-d", "./app"}, CLIArgs{Command: CommandRun, Stage: "hello", Dir: "./app"}},
+		{[]string{"hello", "--dir", "./app"}, CLIArgs{Command: CommandRun, Stage: "hello", Dir: "./app"}},
+		{[]string{"--from", "hello", "--until", "cash", "--exclude", "mario-*"}, CLIArgs{Command: CommandRun, From: "hello", Until: "cash", Exclude: "mario-*"}},
+		{[]string{"--failed"}, CLIArgs{Command: CommandRun, Rerun: "failed"}},
+		{[]string{"--resume", "--until", "cash"}, CLIArgs{Command: CommandRun, Rerun: "resume", Until: "cash"}},
+		{[]string{"--", "--weird-slug"}, CLIArgs{Command: CommandRun, Stage: "--weird-slug"}},
+		{[]string{"list", "--json"}, CLIArgs{Command: CommandList, JSON: true}},
+		{[]string{"--list", "--json"}, CLIArgs{Command: CommandList, JSON: true}},
+		{[]string{"version"}, CLIArgs{Command: CommandVersion}},
+		{[]string{"-v"}, CLIArgs{Command: CommandVersion}},
+		{[]string{"doctor", "-d", "./app"}, CLIArgs{Command: CommandDoctor, Dir: "./app"}},
+		{[]string{"list", "--help"}, CLIArgs{Command: CommandHelp}},
+		{[]string{"help"}, CLIArgs{Command: CommandHelp}},
+		{[]string{"--locale", "zh", "hello"}, CLIArgs{Command: CommandRun, Stage: "hello", Locale: "zh"}},
+	}
+
+	for _, tt := range tests {
+		args, err := ParseArgs(tt.args)
+		if assert.NoError(t, err, "args=%q", tt.args) {
+			assert.Equal(t, tt.expected, args, "args=%q", tt.args)
+		}
+	}
+}
+
+func TestParseArgs_Errors(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--stgae", "hello"}, `unknown flag --stgae. Did you mean "--stage"?`},
+		{[]string{"-x"}, "unknown flag -x"},
+		{[]string{"--stage"}, "flag --stage needs a value"},
+		{[]string{"hello", "mario"}, `unexpected argument "mario". To run several stages, use --stage`},
+		{[]string{"-s", "hello", "mario"}, `unexpected argument "mario"`},
+		{[]string{"list", "hello"}, `unexpected argument "hello". The list command doesn't take arguments`},
+		{[]string{"hello", "lsit"}, `Did you mean "list"?`},
+		{[]string{"--json"}, "--json can't be used with the run command"},
+		{[]string{"list", "--stage", "hello"}, "--stage can't be used with the list command"},
+		{[]string{"run", "--list"}, "--list can't be used with the run command"},
+		{[]string{"--failed", "--resume"}, "--failed and --resume can't be used together"},
+		{[]string{"list", "--locale", "zh"}, "--locale can't be used with the list command"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseArgs(tt.args)
+		if assert.Error(t, err, "args=%q", tt.args) {
+			assert.IsType(t, &UsageError{}, err)
+			assert.Contains(t, err.Error(), tt.expected, "args=%q", tt.args)
+		}
+	}
+}
+
+func TestMergeArgsIntoEnv(t *testing.T) {
+	args, err := ParseArgs([]string{"--to", "cash", "hello,credit"})
+	assert.NoError(t, err)
+
+	env := MergeArgsIntoEnv(args, map[string]string{"BOOTLLM_STAGE": "mario", "OTHER": "1"})
+	assert.Equal(t, "cash", env["BOOTLLM_STAGE_TO"])
+	assert.Equal(t, "hello,credit", env["BOOTLLM_STAGE"])
+	assert.Equal(t, "1", env["OTHER"])
+
+	args, err = ParseArgs([]string{"--locale", "zh"})
+	assert.NoError(t, err)
+	assert.Equal(t, "zh", MergeArgsIntoEnv(args, map[string]string{"BOOTLLM_LOCALE": "en"})["BOOTLLM_LOCALE"])
+}
+
+func TestRunUsageErrorExitCode(t *testing.T) {
+	assert.Equal(t, ExitUsageError, Run([]string{"--stgae", "hello"}, listDefinition))
+}
+
+func TestPrintUsage(t *testing.T) {
+	var buf bytes.Buffer
+	printUsage(&buf, listDefinition)
+
+	assert.Contains(t, buf.String(), "doctor    Diagnose common problems")
+	assert.Contains(t, buf.String(), "  mario-less\n")
+}
@@ -0,0 +1,319 @@
+package tester_utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/tester_context"
+	"github.com/bootllm/tester-utils/tester_definition"
+	"github.com/creack/pty"
+)
+
+// doctorStatus is the outcome of a `tester doctor` check
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarning
+	doctorError
+)
+
+// doctorCheck is the result of a single `tester doctor` check
+type doctorCheck struct {
+	Name   string
+	Status doctorStatus
+	Detail string // What was found
+	Fix    string // How to fix the problem, if Status isn't doctorOK
+}
+
+// installHints suggests how to install common commands, shown when they're missing from PATH
+var installHints = map[string]string{
+	"cc":      "Install a C compiler, e.g. `sudo apt install build-essential` or `xcode-select --install`",
+	"gcc":     "Install gcc, e.g. `sudo apt install build-essential`",
+	"clang":   "Install clang, e.g. `sudo apt install clang` or `xcode-select --install`",
+	"make":    "Install make, e.g. `sudo apt install make`",
+	"go":      "Install Go from https://go.dev/dl/",
+	"cargo":   "Install Rust with rustup from https://rustup.rs/",
+	"python3": "Install Python 3, e.g. `sudo apt install python3`",
+	"javac":   "Install a JDK, e.g. `sudo apt install default-jdk`",
+}
+
+// runDoctor checks the environment for common problems (missing files, permissions, compilers, ...) and prints a
+// report to w, with a fix for each problem. Returns ExitFailure if any check fails.
+func runDoctor(w io.Writer, env map[string]string, definition tester_definition.TesterDefinition) int {
+	submissionDirCheck := checkSubmissionDir(env)
+	checks := []doctorCheck{submissionDirCheck}
+
+	if submissionDirCheck.Status != doctorError {
+		context, err := tester_context.GetTesterContext(env, definition)
+		checks = append(checks, checkConfigFile(submissionDirFromEnv(env), err))
+
+		// The remaining checks depend on bootllm.yml
+		if err == nil {
+			checks = append(checks, checkExecutable(context, definition)...)
+			checks = append(checks, checkRequiredCommands(context, definition)...)
+		}
+	}
+
+	checks = append(checks, checkPTY(), checkMemoryLimits())
+
+	return printDoctorReport(w, checks)
+}
+
+func printDoctorReport(w io.Writer, checks []doctorCheck) int {
+	errorCount, warningCount := 0, 0
+
+	for _, check := range checks {
+		symbol := "✓"
+
+		switch check.Status {
+		case doctorWarning:
+			symbol = "!"
+			warningCount++
+		case doctorError:
+			symbol = "✗"
+			errorCount++
+		}
+
+		fmt.Fprintf(w, "%s %s: %s\n", symbol, check.Name, check.Detail)
+		if check.Status != doctorOK && check.Fix != "" {
+			fmt.Fprintf(w, "  Fix: %s\n", check.Fix)
+		}
+	}
+
+	fmt.Fprintln(w)
+
+	switch {
+	case errorCount > 0:
+		fmt.Fprintf(w, "%d problem(s) and %d warning(s) found.\n", errorCount, warningCount)
+		return ExitFailure
+	case warningCount > 0:
+		fmt.Fprintf(w, "No problems found, %d warning(s).\n", warningCount)
+	default:
+		fmt.Fprintln(w, "No problems found.")
+	}
+
+	return ExitSuccess
+}
+
+func submissionDirFromEnv(env map[string]string) string {
+	if dir, ok := env["BOOTLLM_REPOSITORY_DIR"]; ok {
+		return dir
+	}
+
+	return "."
+}
+
+func checkSubmissionDir(env map[string]string) doctorCheck {
+	check := doctorCheck{Name: "Submission directory"}
+
+	dir := submissionDirFromEnv(env)
+	absoluteDir, err := filepath.Abs(dir)
+	if err != nil {
+		absoluteDir = dir
+	}
+
+	info, err := os.Stat(dir)
+	switch {
+	case err != nil:
+		check.Status = doctorError
+		check.Detail = fmt.Sprintf("%s (%v)", absoluteDir, err)
+		check.Fix = "Run the tester from your project directory, or pass it with --dir <path>"
+	case !info.IsDir():
+		check.Status = doctorError
+		check.Detail = fmt.Sprintf("%s is not a directory", absoluteDir)
+		check.Fix = "Pass your project directory with --dir <path>"
+	default:
+		check.Detail = absoluteDir
+	}
+
+	return check
+}
+
+// checkConfigFile reports whether bootllm.yml exists, and contextErr (the error from loading the tester context) if
+// it's invalid
+func checkConfigFile(submissionDir string, contextErr error) doctorCheck {
+	check := doctorCheck{Name: "bootllm.yml"}
+
+	_, statErr := os.Stat(filepath.Join(submissionDir, "bootllm.yml"))
+
+	switch {
+	case contextErr != nil:
+		check.Status = doctorError
+		check.Detail = contextErr.Error()
+		check.Fix = "Fix the problem above and run doctor again"
+	case errors.Is(statErr, fs.ErrNotExist):
+		check.Detail = "not found (optional, using defaults)"
+	default:
+		check.Detail = "found and valid"
+	}
+
+	return check
+}
+
+// checkExecutable reports how the student's program is started: either the run command from bootllm.yml, or the
+// executable file (ExecutableFileName, or LegacyExecutableFileName if only that exists) and its permissions
+func checkExecutable(context tester_context.TesterContext, definition tester_definition.TesterDefinition) []doctorCheck {
+	if context.RunCommand != "" {
+		return []doctorCheck{{Name: "Run command", Detail: context.RunCommand}}
+	}
+
+	if definition.ExecutableFileName == "" {
+		return nil
+	}
+
+	check := doctorCheck{Name: "Executable"}
+	checks := []doctorCheck{}
+
+	isLegacy := definition.LegacyExecutableFileName != "" && filepath.Base(context.ExecutablePath) == definition.LegacyExecutableFileName
+	if definition.LegacyExecutableFileName != "" && !isLegacy {
+		legacyPath := filepath.Join(context.SubmissionDir, definition.LegacyExecutableFileName)
+		if _, err := os.Stat(legacyPath); err == nil {
+			checks = append(checks, doctorCheck{
+				Name:   "Legacy executable",
+				Status: doctorWarning,
+				Detail: fmt.Sprintf("%s is ignored, since %s is used instead", legacyPath, definition.ExecutableFileName),
+				Fix:    fmt.Sprintf("Remove %s to avoid confusion", legacyPath),
+			})
+		}
+	}
+
+	info, err := os.Stat(context.ExecutablePath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		check.Status = doctorError
+		check.Detail = fmt.Sprintf("%s not found", context.ExecutablePath)
+		check.Fix = fmt.Sprintf("Create %s in your project directory, or set `run` in bootllm.yml", definition.ExecutableFileName)
+	case err != nil:
+		check.Status = doctorError
+		check.Detail = fmt.Sprintf("%s (%v)", context.ExecutablePath, err)
+		check.Fix = "Check the permissions of your project directory"
+	case info.IsDir():
+		check.Status = doctorError
+		check.Detail = fmt.Sprintf("%s is a directory", context.ExecutablePath)
+		check.Fix = fmt.Sprintf("Rename the directory, so that %s can be a file", definition.ExecutableFileName)
+	case info.Mode().Perm()&0111 == 0:
+		check.Status = doctorError
+		check.Detail = fmt.Sprintf("%s (%s) is not executable", context.ExecutablePath, info.Mode().Perm())
+		check.Fix = fmt.Sprintf("chmod +x %s", context.ExecutablePath)
+	default:
+		check.Detail = fmt.Sprintf("%s (%s)", context.ExecutablePath, info.Mode().Perm())
+	}
+
+	if isLegacy && check.Status == doctorOK {
+		check.Status = doctorWarning
+		check.Detail += fmt.Sprintf(", using the legacy name instead of %s", definition.ExecutableFileName)
+		check.Fix = fmt.Sprintf("Rename %s to %s", definition.LegacyExecutableFileName, definition.ExecutableFileName)
+	}
+
+	return append([]doctorCheck{check}, checks...)
+}
+
+// checkRequiredCommands checks that the tester's RequiredCommands, and the commands used by the build and run
+// commands, are on PATH
+func checkRequiredCommands(context tester_context.TesterContext, definition tester_definition.TesterDefinition) []doctorCheck {
+	commands := slices.Clone(definition.RequiredCommands)
+
+	for _, script := range []string{context.BuildCommand, context.RunCommand} {
+		if command := scriptCommand(script); command != "" && !slices.Contains(commands, command) {
+			commands = append(commands, command)
+		}
+	}
+
+	checks := []doctorCheck{}
+	for _, command := range commands {
+		check := doctorCheck{Name: command}
+
+		if path, err := exec.LookPath(command); err != nil {
+			check.Status = doctorError
+			check.Detail = "not found on PATH"
+			check.Fix = installHints[command]
+			if check.Fix == "" {
+				check.Fix = fmt.Sprintf("Install %s and make sure it's on your PATH", command)
+			}
+		} else {
+			check.Detail = path
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// scriptCommand returns the program run by a shell script like "FOO=1 cc -o main *.c", or "" if it runs a file in
+// the submission (like "./main")
+func scriptCommand(script string) string {
+	for _, field := range strings.Fields(script) {
+		if strings.Contains(field, "=") {
+			continue
+		}
+
+		if strings.Contains(field, "/") {
+			return ""
+		}
+
+		return field
+	}
+
+	return ""
+}
+
+func checkPTY() doctorCheck {
+	check := doctorCheck{Name: "PTY"}
+
+	ptyFile, ttyFile, err := pty.Open()
+	if err != nil {
+		check.Status = doctorWarning
+		check.Detail = fmt.Sprintf("can't open a pseudo-terminal (%v)", err)
+		check.Fix = "Stages that run your program in a terminal will fail. Run the tester outside of restricted containers, or mount /dev/pts"
+		return check
+	}
+
+	ptyFile.Close()
+	ttyFile.Close()
+
+	check.Detail = "available"
+	return check
+}
+
+func checkMemoryLimits() doctorCheck {
+	check := doctorCheck{Name: "Memory limits"}
+
+	if err := executable.CheckMemoryLimitSupport(); err != nil {
+		check.Status = doctorWarning
+		check.Detail = err.Error()
+		check.Fix = "Programs that use too much memory may not be stopped. Run the tester on Linux to enforce memory limits"
+		if runtime.GOOS == "linux" {
+			check.Fix = "Programs that use too much memory may not be stopped. Use a kernel with /proc/<pid>/task/<tid>/children (CONFIG_PROC_CHILDREN)"
+		}
+		return check
+	}
+
+	check.Detail = "supported"
+	if cgroupMemoryControllerAvailable() {
+		check.Detail += " (cgroup v2 memory controller available)"
+	}
+
+	return check
+}
+
+// cgroupMemoryControllerAvailable returns true if the cgroup v2 memory controller is enabled. Only reported for
+// information: limits are enforced through /proc.
+func cgroupMemoryControllerAvailable() bool {
+	controllers, err := os.ReadFile("/sys/fs/cgroup/cgroup.controllers")
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(strings.Fields(string(controllers)), "memory")
+}
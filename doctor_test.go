@@ -0,0 +1,118 @@
+package tester_utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootllm/tester-utils/tester_definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var doctorDefinition = tester_definition.TesterDefinition{
+	ExecutableFileName:       "your_program.sh",
+	LegacyExecutableFileName: "spawn_server.sh",
+	TestCases:                []tester_definition.TestCase{{Slug: "hello", TestFunc: passFunc}},
+}
+
+func runDoctorInDir(t *testing.T, dir string, definition tester_definition.TesterDefinition) (int, string) {
+	var buf bytes.Buffer
+	exitCode := runDoctor(&buf, map[string]string{"BOOTLLM_REPOSITORY_DIR": dir}, definition)
+
+	return exitCode, buf.String()
+}
+
+func TestDoctor(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "your_program.sh"), []byte("#!/bin/sh\n"), 0755))
+
+	exitCode, output := runDoctorInDir(t, dir, doctorDefinition)
+
+	assert.Equal(t, ExitSuccess, exitCode, output)
+	assert.Contains(t, output, "✓ Submission directory: "+dir)
+	assert.Contains(t, output, "✓ bootllm.yml: not found (optional, using defaults)")
+	assert.Contains(t, output, "✓ Executable: "+filepath.Join(dir, "your_program.sh")+" (-rwxr-xr-x)")
+	assert.Contains(t, output, "PTY: ")
+	assert.Contains(t, output, "Memory limits: ")
+}
+
+func TestDoctor_MissingSubmissionDir(t *testing.T) {
+	exitCode, output := runDoctorInDir(t, "./does-not-exist", doctorDefinition)
+
+	assert.Equal(t, ExitFailure, exitCode)
+	assert.Contains(t, output, "✗ Submission directory")
+	assert.Contains(t, output, "Fix: Run the tester from your project directory")
+	assert.NotContains(t, output, "Executable")
+}
+
+func TestDoctor_ExecutableNotFound(t *testing.T) {
+	exitCode, output := runDoctorInDir(t, t.TempDir(), doctorDefinition)
+
+	assert.Equal(t, ExitFailure, exitCode)
+	assert.Contains(t, output, "your_program.sh not found")
+	assert.Contains(t, output, "Fix: Create your_program.sh in your project directory, or set `run` in bootllm.yml")
+}
+
+func TestDoctor_ExecutableNotExecutable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "your_program.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0644))
+
+	exitCode, output := runDoctorInDir(t, dir, doctorDefinition)
+
+	assert.Equal(t, ExitFailure, exitCode)
+	assert.Contains(t, output, "✗ Executable: "+path+" (-rw-r--r--) is not executable")
+	assert.Contains(t, output, "Fix: chmod +x "+path)
+}
+
+func TestDoctor_LegacyExecutable(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spawn_server.sh"), []byte("#!/bin/sh\n"), 0755))
+
+	exitCode, output := runDoctorInDir(t, dir, doctorDefinition)
+	assert.Equal(t, ExitSuccess, exitCode, output)
+	assert.Contains(t, output, "! Executable: "+filepath.Join(dir, "spawn_server.sh"))
+	assert.Contains(t, output, "Fix: Rename spawn_server.sh to your_program.sh")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "your_program.sh"), []byte("#!/bin/sh\n"), 0755))
+
+	_, output = runDoctorInDir(t, dir, doctorDefinition)
+	assert.Contains(t, output, "! Legacy executable: "+filepath.Join(dir, "spawn_server.sh")+" is ignored")
+}
+
+func TestDoctor_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bootllm.yml"), []byte("debgu: true\n"), 0644))
+
+	exitCode, output := runDoctorInDir(t, dir, doctorDefinition)
+
+	assert.Equal(t, ExitFailure, exitCode)
+	assert.Contains(t, output, "✗ bootllm.yml: Invalid bootllm.yml:")
+	assert.Contains(t, output, `Did you mean "debug"?`)
+}
+
+func TestDoctor_RequiredCommands(t *testing.T) {
+	dir := t.TempDir()
+	config := "build: FOO=1 sh -c true\nrun: ./main\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bootllm.yml"), []byte(config), 0644))
+
+	definition := doctorDefinition
+	definition.RequiredCommands = []string{"definitely-not-a-command", "clang"}
+
+	exitCode, output := runDoctorInDir(t, dir, definition)
+
+	assert.Equal(t, ExitFailure, exitCode)
+	assert.Contains(t, output, "✓ Run command: ./main")
+	assert.Contains(t, output, "✗ definitely-not-a-command: not found on PATH")
+	assert.Contains(t, output, "Fix: Install definitely-not-a-command and make sure it's on your PATH")
+	assert.Contains(t, output, "✓ sh: ")
+}
+
+func TestScriptCommand(t *testing.T) {
+	assert.Equal(t, "cc", scriptCommand("cc -o main *.c -lm"))
+	assert.Equal(t, "python3", scriptCommand("PYTHONUNBUFFERED=1 python3 main.py"))
+	assert.Equal(t, "", scriptCommand("./main"))
+	assert.Equal(t, "", scriptCommand(""))
+}
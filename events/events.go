@@ -0,0 +1,167 @@
+// Package events emits a stream of newline-delimited JSON events describing a run, used by the worker in
+// BOOTLLM_STREAM_LOGS mode to render live progress without parsing log text.
+//
+// Each line is one Event. The stream of a run looks like:
+//
+//	{"type":"stage_started","stage":"hello","title":"Hello",...}
+//	{"type":"log","stage":"hello","level":"info","message":"Running tests for Hello",...}
+//	{"type":"program_output","stage":"hello","command":"./hello","stream":"stdout","output":"hello, world\n",...}
+//	{"type":"check_passed","stage":"hello","check":"exit code 0",...}
+//	{"type":"stage_failed","stage":"mario","error":"expected exit code 0, got 1",...}
+//	{"type":"run_finished","status":"failed","stages":[...],...}
+//
+// Events are only emitted after Enable. Stages run one at a time, so events emitted after stage_started (until the next
+// stage_started or run_finished) belong to that stage.
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bootllm/tester-utils/redact"
+)
+
+// Event types
+const (
+	TypeStageStarted  = "stage_started"
+	TypeLog           = "log"
+	TypeProgramOutput = "program_output"
+	TypeCheckPassed   = "check_passed"
+	TypeStageFailed   = "stage_failed"
+	TypeRunFinished   = "run_finished"
+)
+
+// Stage and run statuses, the same as in the run history
+const (
+	StatusPassed = "passed"
+	StatusFailed = "failed"
+	StatusNotRun = "not_run"
+)
+
+// Event is a single line of the stream. Only the fields relevant to Type are set.
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	// Stage is the slug of the stage the event belongs to. Set automatically, empty outside of stages.
+	Stage string `json:"stage,omitempty"`
+
+	// Title and LogPrefix are set for stage_started
+	Title     string `json:"title,omitempty"`
+	LogPrefix string `json:"log_prefix,omitempty"`
+
+	// Level (debug, info, success, error, hint, critical or plain), Prefix and Message are set for log
+	Level   string `json:"level,omitempty"`
+	Prefix  string `json:"prefix,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Command, Stream (stdout or stderr) and Output are set for program_output
+	Command string `json:"command,omitempty"`
+	Stream  string `json:"stream,omitempty"`
+	Output  string `json:"output,omitempty"`
+
+	// Check describes the check for check_passed, like "exit code 0"
+	Check string `json:"check,omitempty"`
+
+	// Error is set for stage_failed, and for run_finished if the run couldn't start
+	Error string `json:"error,omitempty"`
+
+	// Hints revealed for the failure, set for stage_failed
+	Hints []string `json:"hints,omitempty"`
+
+	// DurationMs is set for stage_failed
+	DurationMs int64 `json:"duration_ms,omitempty"`
+
+	// Status (passed or failed), Stages and Summary are set for run_finished
+	Status  string        `json:"status,omitempty"`
+	Stages  []StageResult `json:"stages,omitempty"`
+	Summary *Summary      `json:"summary,omitempty"`
+}
+
+// StageResult is the outcome of a stage, listed in run_finished
+type StageResult struct {
+	Slug       string `json:"slug"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Summary counts the stages of a run by status, for run_finished
+type Summary struct {
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	NotRun int `json:"not_run"`
+}
+
+var (
+	mutex        sync.Mutex
+	writer       io.Writer
+	currentStage string
+)
+
+// Enable starts writing events to w
+func Enable(w io.Writer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	writer = w
+	currentStage = ""
+}
+
+// Disable stops writing events
+func Disable() {
+	Enable(nil)
+}
+
+// Enabled returns true if events are being written. Callers can check it to avoid building events for nothing.
+func Enabled() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return writer != nil
+}
+
+// Emit writes event as a line of JSON, if events are enabled. Time and Stage are filled in if empty, and secrets are
+// masked (see the redact package).
+func Emit(event Event) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if writer == nil {
+		return
+	}
+
+	switch event.Type {
+	case TypeStageStarted:
+		currentStage = event.Stage
+	case TypeRunFinished:
+		currentStage = ""
+	}
+
+	if event.Stage == "" {
+		event.Stage = currentStage
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	event.Message = redact.String(event.Message)
+	event.Prefix = redact.String(event.Prefix)
+	event.Command = redact.String(event.Command)
+	event.Output = redact.String(event.Output)
+	event.Check = redact.String(event.Check)
+	event.Error = redact.String(event.Error)
+	event.Hints = redact.Strings(event.Hints)
+
+	// Program output often contains <, > and &, which don't need escaping outside of HTML
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(event); err != nil {
+		return
+	}
+
+	writer.Write(line.Bytes())
+}
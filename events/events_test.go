@@ -0,0 +1,85 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bootllm/tester-utils/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// record enables events for the duration of the test, and returns the buffer they're written to
+func record(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	Enable(&buf)
+	t.Cleanup(Disable)
+
+	return &buf
+}
+
+// decode parses every line written to buf
+func decode(t *testing.T, buf *bytes.Buffer) []Event {
+	decoded := []Event{}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		var event Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event), "line=%q", line)
+		decoded = append(decoded, event)
+	}
+
+	return decoded
+}
+
+func TestEmitWhenDisabled(t *testing.T) {
+	assert.False(t, Enabled())
+	Emit(Event{Type: TypeLog, Message: "dropped"})
+}
+
+func TestEmitFillsStage(t *testing.T) {
+	buf := record(t)
+	assert.True(t, Enabled())
+
+	Emit(Event{Type: TypeLog, Level: "info", Message: "before stages"})
+	Emit(Event{Type: TypeStageStarted, Stage: "hello", Title: "Hello"})
+	Emit(Event{Type: TypeCheckPassed, Check: "exit code 0"})
+	Emit(Event{Type: TypeStageStarted, Stage: "mario", Title: "Mario"})
+	Emit(Event{Type: TypeStageFailed, Error: "expected exit code 0, got 1"})
+	Emit(Event{Type: TypeRunFinished, Status: StatusFailed, Summary: &Summary{Passed: 1, Failed: 1}})
+
+	decoded := decode(t, buf)
+	require.Len(t, decoded, 6)
+
+	stages := []string{}
+	for _, event := range decoded {
+		assert.False(t, event.Time.IsZero())
+		stages = append(stages, event.Stage)
+	}
+	assert.Equal(t, []string{"", "hello", "hello", "mario", "mario", ""}, stages)
+	assert.Equal(t, &Summary{Passed: 1, Failed: 1}, decoded[5].Summary)
+}
+
+func TestEventJSON(t *testing.T) {
+	buf := record(t)
+
+	Emit(Event{Type: TypeProgramOutput, Stage: "hello", Command: "./hello", Stream: "stdout", Output: "hi\n"})
+
+	line := buf.String()
+	assert.True(t, strings.HasSuffix(line, "}\n"))
+	assert.Equal(t, 1, strings.Count(line, "\n"), "one event per line")
+	assert.Contains(t, line, `"type":"program_output","time":"`)
+	assert.Contains(t, line, `"stage":"hello","command":"./hello","stream":"stdout","output":"hi\n"}`)
+	assert.NotContains(t, line, "summary", "unset fields are omitted")
+}
+
+func TestEmitRedactsSecrets(t *testing.T) {
+	buf := record(t)
+	redact.AddSecrets("hunter2")
+	t.Cleanup(redact.Reset)
+
+	Emit(Event{Type: TypeProgramOutput, Command: "./login hunter2", Output: "hunter2\n", Hints: []string{"not hunter2"}})
+
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Equal(t, "./login [REDACTED]", decode(t, buf)[0].Command)
+}
@@ -1,7 +1,6 @@
 package executable
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -12,8 +11,10 @@ import (
 
 	"io"
 	"os/exec"
+	"sync/atomic"
 	"syscall"
 
+	"github.com/bootllm/tester-utils/i18n"
 	"github.com/bootllm/tester-utils/linewriter"
 )
 
@@ -22,6 +23,10 @@ type Executable struct {
 	// Path is the path to the executable.
 	Path string
 
+	// Args are passed to the executable before the arguments given to Start or Run. This is useful for running
+	// interpreters or shell commands, e.g. Path "sh" with Args ["-c", "python3 main.py \"$@\"", "sh"].
+	Args []string
+
 	// TimeoutInMilliseconds is the maximum time the process can run.
 	TimeoutInMilliseconds int
 
@@ -36,6 +41,10 @@ type Executable struct {
 	// WorkingDir can be set before calling Start or Run to customize the working directory of the executable.
 	WorkingDir string
 
+	// Env holds extra environment variables ("KEY=value") passed to the process, on top of the safe environment
+	// variables inherited from the tester.
+	Env []string
+
 	// Process is the os.Process object for the executable.
 	// TODO: See if this actually needs to be exported?
 	Process *os.Process
@@ -44,19 +53,20 @@ type Executable struct {
 	loggerFunc func(string)
 
 	// These are set & removed together
-	atleastOneReadDone bool
+	atleastOneReadDone atomic.Bool
 	memoryMonitor      *memoryMonitor // Monitors process memory usage and kills if limit exceeded
 	cmd                *exec.Cmd
 	ctxCancelFunc      context.CancelFunc
 	ctxWithTimeout     context.Context
 	readDone           chan bool
-	stderrBuffer       *bytes.Buffer
+	stderrBuffer       *lockedBuffer
 	stderrBytes        []byte
 	stderrLineWriter   *linewriter.LineWriter
 	stdioHandler       stdioHandler
-	stdoutBuffer       *bytes.Buffer
+	stdoutBuffer       *lockedBuffer
 	stdoutBytes        []byte
 	stdoutLineWriter   *linewriter.LineWriter
+	untrackProcess     func()
 }
 
 // ExecutableResult holds the result of an executable run
@@ -87,11 +97,13 @@ func nullLogger(msg string) {
 func (e *Executable) Clone() *Executable {
 	return &Executable{
 		Path:                  e.Path,
+		Args:                  append([]string{}, e.Args...),
 		TimeoutInMilliseconds: e.TimeoutInMilliseconds,
 		loggerFunc:            e.loggerFunc,
 		WorkingDir:            e.WorkingDir,
 		ShouldUsePty:          e.ShouldUsePty,
 		MemoryLimitInBytes:    e.MemoryLimitInBytes,
+		Env:                   append([]string{}, e.Env...),
 	}
 }
 
@@ -118,12 +130,39 @@ func NewVerboseExecutable(path string, loggerFunc func(string)) *Executable {
 	}
 }
 
+// SetLoggerFunc sets the function called with each line of output from the executable, like NewVerboseExecutable
+func (e *Executable) SetLoggerFunc(loggerFunc func(string)) {
+	e.loggerFunc = loggerFunc
+}
+
 func (e *Executable) isRunning() bool {
 	return e.cmd != nil
 }
 
 func (e *Executable) HasExited() bool {
-	return e.atleastOneReadDone
+	return e.atleastOneReadDone.Load()
+}
+
+// StdoutSoFar returns a copy of the stdout captured so far. Safe to call while the program is running.
+//
+// Returns nil if the program hasn't been started.
+func (e *Executable) StdoutSoFar() []byte {
+	if e.stdoutBuffer == nil {
+		return nil
+	}
+
+	return e.stdoutBuffer.BytesCopy()
+}
+
+// StderrSoFar returns a copy of the stderr captured so far. Safe to call while the program is running.
+//
+// Returns nil if the program hasn't been started.
+func (e *Executable) StderrSoFar() []byte {
+	if e.stderrBuffer == nil {
+		return nil
+	}
+
+	return e.stderrBuffer.BytesCopy()
 }
 
 func (e *Executable) initializeStdioHandler() {
@@ -138,25 +177,25 @@ func (e *Executable) Start(args ...string) error {
 	var err error
 
 	if e.isRunning() {
-		return errors.New("process already in progress")
+		return i18n.Errorf("process already in progress")
 	}
 
 	// Get the absolute path for e.Path
 	absolutePath, err := resolveAbsolutePath(e.Path)
 
 	if err != nil {
-		return fmt.Errorf("%s not found", filepath.Base(e.Path))
+		return i18n.Errorf("%s not found", filepath.Base(e.Path))
 	}
 
 	fileInfo, err := os.Stat(absolutePath)
 
 	if err != nil {
-		return fmt.Errorf("%s not found", filepath.Base(e.Path))
+		return i18n.Errorf("%s not found", filepath.Base(e.Path))
 	}
 
 	// Check executable permission
 	if fileInfo.Mode().Perm()&0111 == 0 || fileInfo.IsDir() {
-		return fmt.Errorf("%s (resolved to %s) is not an executable file", e.Path, absolutePath)
+		return i18n.Errorf("%s (resolved to %s) is not an executable file", e.Path, absolutePath)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.TimeoutInMilliseconds)*time.Millisecond)
@@ -168,22 +207,22 @@ func (e *Executable) Start(args ...string) error {
 	// which would otherwise be treated as a command to look up in PATH.
 	commandName := absolutePath
 
-	cmd := exec.CommandContext(ctx, commandName, args...)
-	cmd.Env = getSafeEnvironmentVariables()
+	cmd := exec.CommandContext(ctx, commandName, append(append([]string{}, e.Args...), args...)...)
+	cmd.Env = append(GetSafeEnvironmentVariables(), e.Env...)
 	cmd.Dir = e.WorkingDir
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 	e.memoryMonitor = newMemoryMonitor(e.MemoryLimitInBytes)
 
 	e.readDone = make(chan bool)
-	e.atleastOneReadDone = false
+	e.atleastOneReadDone.Store(false)
 
 	e.stdoutBytes = []byte{}
-	e.stdoutBuffer = bytes.NewBuffer(e.stdoutBytes)
+	e.stdoutBuffer = newLockedBuffer(e.stdoutBytes)
 	e.stdoutLineWriter = linewriter.New(newLoggerWriter(e.loggerFunc), 500*time.Millisecond)
 
 	e.stderrBytes = []byte{}
-	e.stderrBuffer = bytes.NewBuffer(e.stderrBytes)
+	e.stderrBuffer = newLockedBuffer(e.stderrBytes)
 	e.stderrLineWriter = linewriter.New(newLoggerWriter(e.loggerFunc), 500*time.Millisecond)
 
 	// Initialize stdio handler
@@ -217,6 +256,7 @@ func (e *Executable) Start(args ...string) error {
 
 	// At this point, it is safe to set e.cmd as cmd, if any of the above steps fail, we don't want to leave e.cmd in an inconsistent state
 	e.cmd = cmd
+	e.untrackProcess = TrackProcessGroup(cmd.Process.Pid)
 
 	// Start memory monitoring for RSS-based memory limiting (Linux only, no-op on other platforms)
 	e.memoryMonitor.start(cmd.Process.Pid)
@@ -245,7 +285,7 @@ func (e *Executable) setupIORelay(source io.Reader, destination1 io.Writer, dest
 			e.loggerFunc("Warning: Logs exceeded allowed limit, output might be truncated.\n")
 		}
 
-		e.atleastOneReadDone = true
+		e.atleastOneReadDone.Store(true)
 		e.readDone <- true
 		io.Copy(io.Discard, source) // Let's drain the stream in case any content is leftover
 	}()
@@ -281,7 +321,7 @@ func (e *Executable) RunWithStdin(stdin []byte, args ...string) (ExecutableResul
 // The process must be started with Start() first.
 func (e *Executable) WriteStdin(data []byte) error {
 	if e.stdioHandler == nil {
-		return errors.New("process not started")
+		return i18n.Errorf("process not started")
 	}
 	_, err := e.stdioHandler.GetStdin().Write(data)
 	return err
@@ -315,6 +355,16 @@ func formatBytesHumanReadable(bytes int64) string {
 // ErrMemoryLimitExceeded is returned when a process exceeds its memory limit
 var ErrMemoryLimitExceeded = errors.New("process exceeded memory limit")
 
+// ErrTimeout is returned when a process runs longer than TimeoutInMilliseconds. Its message is translated to the
+// current locale, so check for it with errors.Is instead of comparing messages.
+var ErrTimeout error = timeoutError{}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string {
+	return i18n.T("execution timed out")
+}
+
 // Wait waits for the program to finish and returns the result.
 func (e *Executable) Wait() (ExecutableResult, error) {
 	defer func() {
@@ -322,8 +372,9 @@ func (e *Executable) Wait() (ExecutableResult, error) {
 
 		e.memoryMonitor.stop()
 		e.stdioHandler.CloseParentStreams()
+		e.untrackProcess()
 
-		e.atleastOneReadDone = false
+		e.atleastOneReadDone.Store(false)
 		e.cmd = nil
 		e.ctxCancelFunc = nil
 		e.ctxWithTimeout = nil
@@ -336,6 +387,7 @@ func (e *Executable) Wait() (ExecutableResult, error) {
 		e.stderrLineWriter = nil
 		e.readDone = nil
 		e.stdioHandler = nil
+		e.untrackProcess = nil
 	}()
 
 	e.stdioHandler.TerminateStdin()
@@ -376,12 +428,12 @@ func (e *Executable) Wait() (ExecutableResult, error) {
 	}
 
 	if e.ctxWithTimeout.Err() == context.DeadlineExceeded {
-		return ExecutableResult{}, fmt.Errorf("execution timed out")
+		return ExecutableResult{}, ErrTimeout
 	}
 
 	// Check if process was killed due to OOM (exit code 137 = 128 + SIGKILL)
 	if e.memoryMonitor.wasOOMKilled() {
-		return result, fmt.Errorf("process exceeded memory limit (%s): %w", formatBytesHumanReadable(e.MemoryLimitInBytes), ErrMemoryLimitExceeded)
+		return result, i18n.Errorf("process exceeded memory limit (%s): %w", formatBytesHumanReadable(e.MemoryLimitInBytes), ErrMemoryLimitExceeded)
 	}
 
 	return result, nil
@@ -409,7 +461,7 @@ func (e *Executable) Kill() error {
 	case <-time.After(2 * time.Second):
 		cmd := e.cmd
 		if cmd != nil {
-			err = fmt.Errorf("program failed to exit in 2 seconds after receiving sigterm")
+			err = i18n.Errorf("program failed to exit in 2 seconds after receiving sigterm")
 			syscall.Kill(cmd.Process.Pid, syscall.SIGKILL)  // Don't know if this is required
 			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) // Kill the whole process group
 
@@ -420,8 +472,8 @@ func (e *Executable) Kill() error {
 	return err
 }
 
-// getSafeEnvironmentVariables filters out environment variables starting with BOOTLLM_SECRET
-func getSafeEnvironmentVariables() []string {
+// GetSafeEnvironmentVariables filters out environment variables starting with BOOTLLM_SECRET
+func GetSafeEnvironmentVariables() []string {
 	allEnvVars := os.Environ()
 	safeEnvVars := make([]string, 0, len(allEnvVars))
 
@@ -67,6 +67,20 @@ func TestRun(t *testing.T) {
 	assert.Equal(t, "hey\n", string(result.Stdout))
 }
 
+func TestRunWithArgs(t *testing.T) {
+	e := NewExecutable("sh")
+	e.Args = []string{"-c", `echo "$0:$*"`, "prog"}
+
+	result, err := e.Run("a", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "prog:a b\n", string(result.Stdout))
+
+	// Args are kept when cloning
+	result, err = e.Clone().Run("c")
+	assert.NoError(t, err)
+	assert.Equal(t, "prog:c\n", string(result.Stdout))
+}
+
 func TestOutputCapture(t *testing.T) {
 	// Stdout capture
 	e := NewExecutable("./test_helpers/stdout_echo.sh")
@@ -183,7 +197,7 @@ func TestRunWithStdinTimeout(t *testing.T) {
 
 	result, err := e.RunWithStdin([]byte(""), "10")
 	assert.Error(t, err)
-	assert.Equal(t, err.Error(), "execution timed out")
+	assert.ErrorIs(t, err, ErrTimeout)
 
 	result, err = e.RunWithStdin([]byte(""), "0.02")
 	assert.NoError(t, err)
@@ -276,3 +290,20 @@ func TestPathResolutionWithDifferentWorkingDir(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "test-message\n", string(result.Stdout))
 }
+
+func TestKillRunningProcessGroups(t *testing.T) {
+	e := NewExecutable("sleep")
+	e.TimeoutInMilliseconds = 10000
+
+	startTime := time.Now()
+	assert.NoError(t, e.Start("10"))
+
+	KillRunningProcessGroups()
+	_, err := e.Wait()
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(startTime), 2*time.Second)
+
+	runningProcessGroupsMutex.Lock()
+	defer runningProcessGroupsMutex.Unlock()
+	assert.NotContains(t, runningProcessGroups, e.Process.Pid)
+}
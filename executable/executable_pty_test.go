@@ -187,7 +187,7 @@ func TestRunWithStdinTimeoutInPty(t *testing.T) {
 
 	result, err := e.RunWithStdin([]byte(""), "10")
 	assert.Error(t, err)
-	assert.Equal(t, err.Error(), "execution timed out")
+	assert.ErrorIs(t, err, ErrTimeout)
 
 	result, err = e.RunWithStdin([]byte(""), "0.01") // Reduced sleep time to 10ms
 	assert.NoError(t, err)
@@ -185,3 +185,19 @@ func getChildPIDs(pid int) ([]int, error) {
 	return children, nil
 }
 
+// CheckMemoryLimitSupport returns an error if memory limits (MemoryLimitInBytes) can't be enforced on this system.
+// Limits are enforced by polling the RSS of the process tree in /proc.
+func CheckMemoryLimitSupport() error {
+	pid := os.Getpid()
+
+	if _, err := getProcessRSS(pid); err != nil {
+		return fmt.Errorf("can't read memory usage from /proc: %v", err)
+	}
+
+	// Children are found through /proc/<pid>/task/<tid>/children, which needs CONFIG_PROC_CHILDREN
+	if _, err := os.Stat(fmt.Sprintf("/proc/%d/task/%d/children", pid, pid)); err != nil {
+		return fmt.Errorf("can't find child processes in /proc, so memory used by child processes isn't counted: %v", err)
+	}
+
+	return nil
+}
@@ -2,6 +2,11 @@
 
 package executable
 
+import (
+	"fmt"
+	"runtime"
+)
+
 // memoryMonitor is a no-op on non-Linux platforms
 type memoryMonitor struct{}
 
@@ -20,3 +25,8 @@ func (m *memoryMonitor) wasOOMKilled() bool {
 
 // stop is a no-op on non-Linux platforms
 func (m *memoryMonitor) stop() {}
+
+// CheckMemoryLimitSupport always returns an error on non-Linux platforms, where memory limits are ignored
+func CheckMemoryLimitSupport() error {
+	return fmt.Errorf("memory limits aren't supported on %s, so they're ignored", runtime.GOOS)
+}
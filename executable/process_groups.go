@@ -0,0 +1,42 @@
+package executable
+
+import (
+	"sync"
+	"syscall"
+)
+
+// runningProcessGroups holds the IDs of the process groups of programs that have been started but not waited for.
+//
+// Programs run in their own process groups, so they don't receive the signals sent by the terminal (e.g. on Ctrl+C).
+// Testers that handle such signals use KillRunningProcessGroups to avoid leaving orphaned programs behind.
+var (
+	runningProcessGroups      = map[int]bool{}
+	runningProcessGroupsMutex sync.Mutex
+)
+
+// TrackProcessGroup records that the process group pgid is running, until the returned function is called. Programs
+// started with Executable are tracked automatically, this is for programs started in their own process group with
+// exec.Cmd directly.
+func TrackProcessGroup(pgid int) (untrack func()) {
+	runningProcessGroupsMutex.Lock()
+	defer runningProcessGroupsMutex.Unlock()
+
+	runningProcessGroups[pgid] = true
+
+	return func() {
+		runningProcessGroupsMutex.Lock()
+		defer runningProcessGroupsMutex.Unlock()
+
+		delete(runningProcessGroups, pgid)
+	}
+}
+
+// KillRunningProcessGroups kills the process groups of all programs that are still running
+func KillRunningProcessGroups() {
+	runningProcessGroupsMutex.Lock()
+	defer runningProcessGroupsMutex.Unlock()
+
+	for pgid := range runningProcessGroups {
+		syscall.Kill(-pgid, syscall.SIGKILL)
+	}
+}
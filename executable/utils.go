@@ -1,12 +1,14 @@
 package executable
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"sync"
 
 	"github.com/mattn/go-isatty"
 )
@@ -73,3 +75,33 @@ func resolveAbsolutePath(path string) (absolutePath string, err error) {
 	// So, we convert the relative path to the absolute path
 	return filepath.Abs(executablePath)
 }
+
+// lockedBuffer is a bytes.Buffer that can be read from while the IO relay is writing to it
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer *bytes.Buffer
+}
+
+func newLockedBuffer(buf []byte) *lockedBuffer {
+	return &lockedBuffer{buffer: bytes.NewBuffer(buf)}
+}
+
+func (b *lockedBuffer) Write(p []byte) (n int, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+// Bytes returns the underlying bytes. Only safe to use once all writes are done.
+func (b *lockedBuffer) Bytes() []byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Bytes()
+}
+
+// BytesCopy returns a copy of the bytes written so far
+func (b *lockedBuffer) BytesCopy() []byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return bytes.Clone(b.buffer.Bytes())
+}
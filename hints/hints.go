@@ -0,0 +1,119 @@
+// Package hints attaches advice to test failures. Hints can be attached to a specific check (see runner.Runner.Hint),
+// carried by an error type (see Hinter), or matched against errors with rules declared in the tester definition.
+//
+// A hint can have several levels, revealed progressively as a stage keeps failing: the first level after the first
+// failure, the second after the second consecutive failure, and so on.
+package hints
+
+import (
+	"errors"
+	"strings"
+)
+
+// Hint is a piece of advice, revealed one level at a time
+type Hint struct {
+	// Levels are revealed progressively. Earlier levels should be gentle nudges, later ones more explicit.
+	Levels []string
+}
+
+// New returns a hint with the given levels
+func New(levels ...string) Hint {
+	return Hint{Levels: levels}
+}
+
+// IsEmpty returns true if the hint has no levels
+func (h Hint) IsEmpty() bool {
+	return len(h.Levels) == 0
+}
+
+// Reveal returns the levels revealed after failures consecutive failures (1 for the first failure). All levels are
+// revealed once failures reaches len(Levels).
+func (h Hint) Reveal(failures int) []string {
+	return h.Levels[:min(max(failures, 1), len(h.Levels))]
+}
+
+// Hinter is implemented by errors that carry their own hint, like runner.Mismatch
+type Hinter interface {
+	Hint() Hint
+}
+
+// Rule attaches a hint to errors it matches
+type Rule struct {
+	Match func(err error) bool
+	Hint  Hint
+}
+
+// OnError returns a rule matching errors of type T (anywhere in the error chain). Example:
+//
+//	hints.OnError[*runner.ExitCodeMismatch]("Remember to return 1 from main when the input is invalid")
+func OnError[T error](levels ...string) Rule {
+	return Rule{
+		Match: func(err error) bool {
+			var target T
+			return errors.As(err, &target)
+		},
+		Hint: New(levels...),
+	}
+}
+
+// OnMessage returns a rule matching errors whose message contains substring
+func OnMessage(substring string, levels ...string) Rule {
+	return Rule{
+		Match: func(err error) bool {
+			return strings.Contains(err.Error(), substring)
+		},
+		Hint: New(levels...),
+	}
+}
+
+// hintedError is an error with a hint attached by Attach
+type hintedError struct {
+	err  error
+	hint Hint
+}
+
+func (e *hintedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *hintedError) Unwrap() error {
+	return e.err
+}
+
+func (e *hintedError) Hint() Hint {
+	return e.hint
+}
+
+// Attach returns err with a hint attached. The error message is unchanged, and errors.Is / errors.As still see err.
+// Returns nil if err is nil.
+func Attach(err error, levels ...string) error {
+	if err == nil || len(levels) == 0 {
+		return err
+	}
+
+	return &hintedError{err: err, hint: New(levels...)}
+}
+
+// For returns the hint for err: levels carried by the error chain (from Attach or Hinter, outermost first), followed
+// by the levels of the first matching rule
+func For(err error, rules ...Rule) Hint {
+	hint := Hint{}
+	if err == nil {
+		return hint
+	}
+
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if hinter, ok := current.(Hinter); ok {
+			hint.Levels = append(hint.Levels, hinter.Hint().Levels...)
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Match != nil && rule.Match(err) {
+			hint.Levels = append(hint.Levels, rule.Hint.Levels...)
+			break
+		}
+	}
+
+	return hint
+}
@@ -0,0 +1,65 @@
+package hints
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type exitCodeError struct{ code int }
+
+func (e *exitCodeError) Error() string { return fmt.Sprintf("exit code %d", e.code) }
+
+type helpfulError struct{}
+
+func (e *helpfulError) Error() string { return "helpful" }
+func (e *helpfulError) Hint() Hint    { return New("from the error type") }
+
+func TestReveal(t *testing.T) {
+	hint := New("nudge", "clue", "answer")
+
+	assert.Equal(t, []string{"nudge"}, hint.Reveal(0))
+	assert.Equal(t, []string{"nudge"}, hint.Reveal(1))
+	assert.Equal(t, []string{"nudge", "clue"}, hint.Reveal(2))
+	assert.Equal(t, []string{"nudge", "clue", "answer"}, hint.Reveal(3))
+	assert.Equal(t, []string{"nudge", "clue", "answer"}, hint.Reveal(10))
+	assert.Empty(t, Hint{}.Reveal(1))
+}
+
+func TestAttach(t *testing.T) {
+	assert.Nil(t, Attach(nil, "hint"))
+
+	original := &exitCodeError{code: 1}
+	assert.Same(t, original, Attach(original), "no levels means nothing to attach")
+
+	err := Attach(original, "check your return value")
+	assert.Equal(t, "exit code 1", err.Error())
+	assert.ErrorIs(t, err, original)
+
+	var target *exitCodeError
+	assert.True(t, errors.As(err, &target))
+}
+
+func TestFor(t *testing.T) {
+	rules := []Rule{
+		OnMessage("timed out", "is your program waiting for input?"),
+		OnError[*exitCodeError]("return 1 on invalid input"),
+		OnError[*exitCodeError]("never used, only the first matching rule applies"),
+	}
+
+	assert.True(t, For(nil, rules...).IsEmpty())
+	assert.True(t, For(errors.New("other"), rules...).IsEmpty())
+
+	assert.Equal(t, []string{"is your program waiting for input?"}, For(errors.New("timed out"), rules...).Levels)
+
+	wrapped := fmt.Errorf("stage failed: %w", &exitCodeError{code: 1})
+	assert.Equal(t, []string{"return 1 on invalid input"}, For(wrapped, rules...).Levels)
+
+	// Hints carried by the error come first, outermost first
+	err := Attach(Attach(&exitCodeError{code: 1}, "inner"), "outer")
+	assert.Equal(t, []string{"outer", "inner", "return 1 on invalid input"}, For(err, rules...).Levels)
+
+	assert.Equal(t, []string{"from the error type"}, For(&helpfulError{}).Levels)
+}
@@ -0,0 +1,210 @@
+package http_client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// DefaultTimeout is the default time to wait for a complete response
+const DefaultTimeout = 5 * time.Second
+
+// Request is an HTTP request to be sent to the program under test
+type Request struct {
+	Method  string
+	Path    string
+	Headers map[string]string
+	Body    []byte
+}
+
+// Client sends HTTP/1.1 requests to the program under test.
+//
+// Requests are written and responses are parsed by hand (instead of using net/http), so that malformed responses
+// from hand-written servers result in precise error messages.
+//
+//	client := http_client.NewClient("localhost:4221", harness.Logger)
+//	response, err := client.Get("/echo/abc")
+//	if err != nil {
+//	    return err
+//	}
+//	if err := response.ExpectStatus(200); err != nil {
+//	    return err
+//	}
+//	return response.ExpectBody([]byte("abc"))
+type Client struct {
+	// Timeout is the maximum time to wait for a complete response. Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	address string
+	logger  *logger.Logger
+}
+
+// NewClient returns a client that sends requests to address (e.g. "localhost:4221")
+func NewClient(address string, logger *logger.Logger) *Client {
+	return &Client{
+		Timeout: DefaultTimeout,
+		address: address,
+		logger:  quietIfNil(logger),
+	}
+}
+
+// quietIfNil returns l, or a logger that doesn't print anything if l is nil
+func quietIfNil(l *logger.Logger) *logger.Logger {
+	if l == nil {
+		return logger.GetQuietLogger("")
+	}
+
+	return l
+}
+
+// Get sends a GET request for path
+func (c *Client) Get(path string) (*Response, error) {
+	return c.Do(Request{Method: "GET", Path: path})
+}
+
+// Do sends request over a new connection and parses the response
+func (c *Client) Do(request Request) (*Response, error) {
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+	if request.Path == "" {
+		request.Path = "/"
+	}
+
+	rawRequest := buildRawRequest(c.address, request)
+
+	c.logger.Infof("$ curl -X %s http://%s%s", request.Method, c.address, request.Path)
+
+	conn, err := net.DialTimeout("tcp", c.address, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", c.address, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(c.Timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write(rawRequest); err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+
+	response, rawResponse, err := c.readResponse(conn, request.Method == "HEAD")
+	if err != nil {
+		logTranscript(c.logger, rawRequest, rawResponse)
+		return nil, err
+	}
+
+	response.rawRequest = rawRequest
+	response.logger = c.logger
+
+	c.logger.Debugf("Received response with status %d %s", response.StatusCode, response.Reason)
+	return response, nil
+}
+
+// readResponse reads from conn until a complete response has been parsed
+func (c *Client) readResponse(conn net.Conn, isHeadRequest bool) (*Response, []byte, error) {
+	data := []byte{}
+	buf := make([]byte, 4096)
+
+	for {
+		n, readErr := conn.Read(buf)
+		data = append(data, buf[:n]...)
+
+		isComplete := errors.Is(readErr, io.EOF)
+		if isComplete && len(data) == 0 {
+			return nil, data, fmt.Errorf("connection closed by server without sending a response")
+		}
+
+		if len(data) > 0 {
+			// parseResponse always returns either a response or a ParseError once isComplete is true
+			response, err := parseResponse(data, isComplete, isHeadRequest)
+			if err == nil {
+				return response, data, nil
+			}
+			if !errors.Is(err, errIncompleteResponse) {
+				return nil, data, err
+			}
+		}
+
+		if readErr != nil {
+			if errors.Is(readErr, os.ErrDeadlineExceeded) {
+				if len(data) == 0 {
+					return nil, data, fmt.Errorf("timed out after %v waiting for a response, no bytes received", c.Timeout)
+				}
+				return nil, data, fmt.Errorf("timed out after %v waiting for a complete response, received %q so far", c.Timeout, data)
+			}
+			return nil, data, fmt.Errorf("failed to read response: %v", readErr)
+		}
+	}
+}
+
+// buildRawRequest serializes request as HTTP/1.1
+func buildRawRequest(address string, request Request) []byte {
+	headers := map[string]string{
+		"Host":       address,
+		"User-Agent": "bootllm-tester",
+		"Connection": "close",
+	}
+	if len(request.Body) > 0 {
+		headers["Content-Length"] = fmt.Sprintf("%d", len(request.Body))
+	}
+	for name, value := range request.Headers {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+
+	// Sort headers so that requests are deterministic (useful for fixtures)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "%s %s HTTP/1.1\r\n", request.Method, request.Path)
+	for _, name := range names {
+		fmt.Fprintf(buf, "%s: %s\r\n", name, headers[name])
+	}
+	buf.WriteString("\r\n")
+	buf.Write(request.Body)
+
+	return buf.Bytes()
+}
+
+// logTranscript logs the raw request and response, used when a request fails or an assertion doesn't pass
+func logTranscript(l *logger.Logger, rawRequest []byte, rawResponse []byte) {
+	l.Infof("Request:")
+	for _, line := range transcriptLines(rawRequest) {
+		l.Plainln("> " + line)
+	}
+
+	if len(rawResponse) == 0 {
+		l.Infof("Response: (no bytes received)")
+		return
+	}
+
+	l.Infof("Response:")
+	for _, line := range transcriptLines(rawResponse) {
+		l.Plainln("< " + line)
+	}
+}
+
+// transcriptLines splits raw HTTP bytes into lines, showing non-printable characters in the body escaped
+func transcriptLines(raw []byte) []string {
+	lines := strings.Split(strings.TrimSuffix(string(raw), "\r\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		quoted := fmt.Sprintf("%q", line)
+		lines[i] = quoted[1 : len(quoted)-1]
+	}
+	return lines
+}
@@ -0,0 +1,160 @@
+package http_client
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startRawServer starts a server that reads one request and replies with rawResponse before closing the connection
+func startRawServer(t *testing.T, rawResponse string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			reader := bufio.NewReader(conn)
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil || line == "\r\n" {
+					break
+				}
+			}
+
+			conn.Write([]byte(rawResponse))
+			conn.Close()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func newTestClient(address string) *Client {
+	return NewClient(address, logger.GetLogger(true, "[test] "))
+}
+
+func TestGet(t *testing.T) {
+	address := startRawServer(t, "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nContent-Length: 3\r\n\r\nabc")
+
+	response, err := newTestClient(address).Get("/echo/abc")
+	require.NoError(t, err)
+
+	assert.NoError(t, response.ExpectStatus(200))
+	assert.NoError(t, response.ExpectHeader("content-type", "text/plain"))
+	assert.NoError(t, response.ExpectBody([]byte("abc")))
+	assert.NoError(t, response.ExpectBodyRegex(`^a.c$`))
+	assert.NoError(t, response.ExpectHeaderAbsent("Content-Encoding"))
+
+	assert.Error(t, response.ExpectStatus(404))
+	assert.Error(t, response.ExpectHeader("Content-Type", "application/json"))
+	assert.Error(t, response.ExpectBody([]byte("abcd")))
+}
+
+func TestNilLogger(t *testing.T) {
+	address := startRawServer(t, "HTTP/1.1 200 OK\r\nContent-Length: 3\r\n\r\nabc")
+
+	response, err := NewClient(address, nil).Get("/")
+	require.NoError(t, err)
+
+	assert.NoError(t, response.ExpectStatus(200))
+	assert.Error(t, response.ExpectStatus(404))
+}
+
+func TestChunkedBody(t *testing.T) {
+	address := startRawServer(t, "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n2\r\nde\r\n0\r\n\r\n")
+
+	response, err := newTestClient(address).Get("/")
+	require.NoError(t, err)
+	assert.Equal(t, "abcde", string(response.Body))
+}
+
+func TestBodyDelimitedByClose(t *testing.T) {
+	address := startRawServer(t, "HTTP/1.0 200 OK\r\n\r\nhello")
+
+	response, err := newTestClient(address).Get("/")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(response.Body))
+	assert.Equal(t, "HTTP/1.0", response.Version)
+}
+
+func TestExpectJSONSubset(t *testing.T) {
+	body := `{"name":"Alice","age":30,"tags":["admin","dev"]}`
+	address := startRawServer(t, fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(body), body))
+
+	response, err := newTestClient(address).Get("/user")
+	require.NoError(t, err)
+
+	assert.NoError(t, response.ExpectJSONSubset(`{"name": "Alice", "tags": ["admin", "dev"]}`))
+
+	err = response.ExpectJSONSubset(`{"name": "Bob"}`)
+	assert.EqualError(t, err, `JSON mismatch at $.name: expected "Bob", got "Alice"`)
+
+	err = response.ExpectJSONSubset(`{"email": "a@b.c"}`)
+	assert.EqualError(t, err, `JSON mismatch at $: expected key "email" to be present`)
+
+	err = response.ExpectJSONSubset(`{"tags": ["admin"]}`)
+	assert.EqualError(t, err, `JSON mismatch at $.tags: expected an array of length 1, got 2`)
+}
+
+func TestMalformedResponses(t *testing.T) {
+	tests := []struct {
+		rawResponse     string
+		expectedMessage string
+		expectedOffset  int
+	}{
+		{"HTTP/1.1 200 OK\nContent-Length: 0\r\n\r\n", `expected status line to end with \r\n, found \n without \r`, 15},
+		{"HTTP/2 200 OK\r\n\r\n", `expected HTTP version (HTTP/1.1 or HTTP/1.0), got "HTTP/2"`, 0},
+		{"HTTP/1.1 OK\r\n\r\n", `expected 3-digit status code, got "OK"`, 9},
+		{"HTTP/1.1 200 OK\r\nContent-Type text/plain\r\n\r\n", `expected header in the form "Name: value", got "Content-Type text/plain"`, 17},
+		{"HTTP/1.1 200 OK\r\nContent-Length: abc\r\n\r\n", `invalid Content-Length header value "abc"`, 40},
+		{"HTTP/1.1 200 OK\r\nContent-Length: 10\r\n\r\nabc", `Content-Length is 10, but only 3 body bytes were received before the connection was closed`, 42},
+		{"HTTP/1.1 200 OK\r\nContent-Length: 3\r\n", `unexpected end of response while reading header`, 36},
+		{"HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n7fffffffffffffff\r\nabc", `expected chunk of 9223372036854775807 bytes followed by \r\n`, 68},
+	}
+
+	for _, tt := range tests {
+		address := startRawServer(t, tt.rawResponse)
+
+		_, err := newTestClient(address).Get("/")
+
+		var parseError *ParseError
+		if assert.ErrorAs(t, err, &parseError, tt.rawResponse) {
+			assert.Equal(t, tt.expectedMessage, parseError.Message, tt.rawResponse)
+			assert.Equal(t, tt.expectedOffset, parseError.Offset, tt.rawResponse)
+		}
+	}
+}
+
+func TestParseErrorHighlightsOffset(t *testing.T) {
+	err := &ParseError{Data: []byte("HTTP/1.1 OK\r\n"), Offset: 9, Message: "expected 3-digit status code"}
+	assert.Equal(t, "Received invalid HTTP response: expected 3-digit status code\nReceived: \"HTTP/1.1 OK\\r\\n\"\n                    ^ error", err.Error())
+}
+
+func TestNoResponse(t *testing.T) {
+	address := startRawServer(t, "")
+
+	_, err := newTestClient(address).Get("/")
+	assert.EqualError(t, err, "connection closed by server without sending a response")
+}
+
+func TestBuildRawRequest(t *testing.T) {
+	raw := buildRawRequest("localhost:4221", Request{
+		Method:  "POST",
+		Path:    "/files/a",
+		Headers: map[string]string{"content-type": "text/plain"},
+		Body:    []byte("hi"),
+	})
+
+	assert.Equal(t, "POST /files/a HTTP/1.1\r\nConnection: close\r\nContent-Length: 2\r\nContent-Type: text/plain\r\nHost: localhost:4221\r\nUser-Agent: bootllm-tester\r\n\r\nhi", string(raw))
+}
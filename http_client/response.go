@@ -0,0 +1,208 @@
+package http_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// Response is a parsed HTTP response received from the program under test
+type Response struct {
+	// Version is the HTTP version from the status line. Example: "HTTP/1.1"
+	Version string
+
+	// StatusCode is the status code from the status line. Example: 200
+	StatusCode int
+
+	// Reason is the reason phrase from the status line. Example: "OK"
+	Reason string
+
+	Headers http.Header
+	Body    []byte
+
+	// RawBytes is the response exactly as received
+	RawBytes []byte
+
+	rawRequest []byte
+	logger     *logger.Logger
+
+	// hasLoggedTranscript ensures the transcript is only logged once, even if multiple assertions fail
+	hasLoggedTranscript bool
+}
+
+// ExpectStatus checks the status code
+func (r *Response) ExpectStatus(expected int) error {
+	if r.StatusCode != expected {
+		return r.fail(fmt.Errorf("expected status code %d, got %d", expected, r.StatusCode))
+	}
+
+	r.logger.Successf("✓ Received status code %d", expected)
+	return nil
+}
+
+// ExpectHeader checks that the header name is present and equal to expected
+func (r *Response) ExpectHeader(name string, expected string) error {
+	values := r.Headers.Values(name)
+	if len(values) == 0 {
+		return r.fail(fmt.Errorf("expected header %q to be present, got headers: %s", name, r.formatHeaderNames()))
+	}
+
+	if values[0] != expected {
+		return r.fail(fmt.Errorf("expected header %q to be %q, got %q", name, expected, values[0]))
+	}
+
+	r.logger.Successf("✓ %s header is %q", http.CanonicalHeaderKey(name), expected)
+	return nil
+}
+
+// ExpectHeaderAbsent checks that the header name isn't present
+func (r *Response) ExpectHeaderAbsent(name string) error {
+	if values := r.Headers.Values(name); len(values) > 0 {
+		return r.fail(fmt.Errorf("expected header %q to be absent, got %q", name, values[0]))
+	}
+
+	return nil
+}
+
+// ExpectBody checks that the body is exactly equal to expected
+func (r *Response) ExpectBody(expected []byte) error {
+	if !bytes.Equal(r.Body, expected) {
+		return r.fail(fmt.Errorf("expected body %q, got %q", expected, r.Body))
+	}
+
+	r.logger.Successf("✓ Body is %q", expected)
+	return nil
+}
+
+// ExpectBodyRegex checks that the body matches pattern
+func (r *Response) ExpectBodyRegex(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid regex pattern: %v", err)
+	}
+
+	if !re.Match(r.Body) {
+		return r.fail(fmt.Errorf("expected body to match %q, got %q", pattern, r.Body))
+	}
+
+	r.logger.Successf("✓ Body matches %q", pattern)
+	return nil
+}
+
+// ExpectJSONSubset checks that the body is valid JSON and contains expectedJSON.
+//
+// Objects match if every key in expectedJSON is present with a matching value (extra keys are allowed). Arrays must
+// have the same length, and their elements must match in order. Other values must be equal.
+//
+//	response.ExpectJSONSubset(`{"name": "Alice", "tags": ["admin"]}`)
+func (r *Response) ExpectJSONSubset(expectedJSON string) error {
+	var expected any
+	if err := json.Unmarshal([]byte(expectedJSON), &expected); err != nil {
+		return fmt.Errorf("invalid expected JSON: %v", err)
+	}
+
+	var actual any
+	if err := json.Unmarshal(r.Body, &actual); err != nil {
+		return r.fail(fmt.Errorf("expected body to be valid JSON, got %q (%v)", r.Body, err))
+	}
+
+	if path, message := findJSONSubsetMismatch(expected, actual, "$"); message != "" {
+		return r.fail(fmt.Errorf("JSON mismatch at %s: %s", path, message))
+	}
+
+	r.logger.Successf("✓ Body contains expected JSON")
+	return nil
+}
+
+// findJSONSubsetMismatch returns the path and description of the first mismatch, or an empty message if actual
+// contains expected.
+func findJSONSubsetMismatch(expected any, actual any, path string) (string, string) {
+	switch expected := expected.(type) {
+	case map[string]any:
+		actualObject, ok := actual.(map[string]any)
+		if !ok {
+			return path, fmt.Sprintf("expected an object, got %s", formatJSON(actual))
+		}
+
+		keys := make([]string, 0, len(expected))
+		for key := range expected {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			actualValue, found := actualObject[key]
+			if !found {
+				return path, fmt.Sprintf("expected key %q to be present", key)
+			}
+
+			if mismatchPath, message := findJSONSubsetMismatch(expected[key], actualValue, path+"."+key); message != "" {
+				return mismatchPath, message
+			}
+		}
+
+		return "", ""
+	case []any:
+		actualArray, ok := actual.([]any)
+		if !ok {
+			return path, fmt.Sprintf("expected an array, got %s", formatJSON(actual))
+		}
+
+		if len(actualArray) != len(expected) {
+			return path, fmt.Sprintf("expected an array of length %d, got %d", len(expected), len(actualArray))
+		}
+
+		for i := range expected {
+			if mismatchPath, message := findJSONSubsetMismatch(expected[i], actualArray[i], fmt.Sprintf("%s[%d]", path, i)); message != "" {
+				return mismatchPath, message
+			}
+		}
+
+		return "", ""
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return path, fmt.Sprintf("expected %s, got %s", formatJSON(expected), formatJSON(actual))
+		}
+
+		return "", ""
+	}
+}
+
+func formatJSON(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+func (r *Response) formatHeaderNames() string {
+	if len(r.Headers) == 0 {
+		return "(none)"
+	}
+
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// fail logs the request/response transcript (once) and returns err
+func (r *Response) fail(err error) error {
+	if !r.hasLoggedTranscript {
+		logTranscript(r.logger, r.rawRequest, r.RawBytes)
+		r.hasLoggedTranscript = true
+	}
+
+	return err
+}
@@ -0,0 +1,245 @@
+package http_client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bootllm/tester-utils/inspectable_byte_string"
+)
+
+// errIncompleteResponse is returned by parseResponse if more bytes are needed
+var errIncompleteResponse = errors.New("incomplete response")
+
+// ParseError is returned when the response sent by the program isn't valid HTTP.
+//
+// Hand-written servers often get small details wrong (e.g. "\n" instead of "\r\n"), so the error points to the exact
+// offset in the raw response where parsing failed.
+type ParseError struct {
+	// Data is the raw response received so far
+	Data []byte
+
+	// Offset is the index in Data where parsing failed
+	Offset int
+
+	// Message describes what was expected at Offset
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	formatted := inspectable_byte_string.NewInspectableByteString(e.Data).FormatWithHighlightedOffset(e.Offset, "error", "Received: ", "")
+	return fmt.Sprintf("Received invalid HTTP response: %s\n%s", e.Message, formatted)
+}
+
+// responseParser parses an HTTP/1.x response, keeping track of the current offset for error messages
+type responseParser struct {
+	data   []byte
+	offset int
+
+	// isComplete is true when no more bytes will be received (i.e. the connection was closed)
+	isComplete bool
+
+	// isHeadRequest is true when the response is to a HEAD request, which never has a body
+	isHeadRequest bool
+}
+
+// parseResponse parses data into a Response.
+//
+// Returns errIncompleteResponse if data is a valid prefix of a response and isComplete is false.
+func parseResponse(data []byte, isComplete bool, isHeadRequest bool) (*Response, error) {
+	p := &responseParser{data: data, isComplete: isComplete, isHeadRequest: isHeadRequest}
+	return p.parse()
+}
+
+func (p *responseParser) parse() (*Response, error) {
+	response := &Response{Headers: http.Header{}}
+
+	if err := p.parseStatusLine(response); err != nil {
+		return nil, err
+	}
+
+	if err := p.parseHeaders(response); err != nil {
+		return nil, err
+	}
+
+	if err := p.parseBody(response); err != nil {
+		return nil, err
+	}
+
+	response.RawBytes = p.data[:p.offset]
+	return response, nil
+}
+
+// parseStatusLine parses a line like "HTTP/1.1 200 OK\r\n"
+func (p *responseParser) parseStatusLine(response *Response) error {
+	line, lineEndOffset, err := p.readLine("status line")
+	if err != nil {
+		return err
+	}
+
+	lineStartOffset := p.offset
+
+	version, rest, found := strings.Cut(line, " ")
+	if version != "HTTP/1.1" && version != "HTTP/1.0" {
+		return p.errorAt(lineStartOffset, fmt.Sprintf("expected HTTP version (HTTP/1.1 or HTTP/1.0), got %q", version))
+	}
+	if !found {
+		return p.errorAt(lineStartOffset+len(version), "expected space after HTTP version")
+	}
+
+	statusCodeOffset := lineStartOffset + len(version) + 1
+	statusCodeString, reason, _ := strings.Cut(rest, " ")
+	statusCode, err := strconv.Atoi(statusCodeString)
+	if err != nil || len(statusCodeString) != 3 {
+		return p.errorAt(statusCodeOffset, fmt.Sprintf("expected 3-digit status code, got %q", statusCodeString))
+	}
+
+	response.Version = version
+	response.StatusCode = statusCode
+	response.Reason = reason
+
+	p.offset = lineEndOffset
+	return nil
+}
+
+// parseHeaders parses header lines until an empty line
+func (p *responseParser) parseHeaders(response *Response) error {
+	for {
+		line, lineEndOffset, err := p.readLine("header")
+		if err != nil {
+			return err
+		}
+
+		if line == "" {
+			p.offset = lineEndOffset
+			return nil
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return p.errorAt(p.offset, fmt.Sprintf("expected header in the form \"Name: value\", got %q", line))
+		}
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return p.errorAt(p.offset, fmt.Sprintf("invalid header name %q", name))
+		}
+
+		response.Headers.Add(name, strings.TrimSpace(value))
+		p.offset = lineEndOffset
+	}
+}
+
+// parseBody parses the body based on Content-Length or Transfer-Encoding
+func (p *responseParser) parseBody(response *Response) error {
+	if p.isHeadRequest || response.StatusCode == 204 || response.StatusCode == 304 || response.StatusCode/100 == 1 {
+		return nil
+	}
+
+	if strings.EqualFold(response.Headers.Get("Transfer-Encoding"), "chunked") {
+		return p.parseChunkedBody(response)
+	}
+
+	if contentLengthValues := response.Headers.Values("Content-Length"); len(contentLengthValues) > 0 {
+		contentLength, err := strconv.Atoi(contentLengthValues[0])
+		if err != nil || contentLength < 0 {
+			return p.errorAt(p.offset, fmt.Sprintf("invalid Content-Length header value %q", contentLengthValues[0]))
+		}
+
+		remaining := len(p.data) - p.offset
+		if remaining < contentLength {
+			if p.isComplete {
+				return p.errorAt(len(p.data), fmt.Sprintf("Content-Length is %d, but only %d body bytes were received before the connection was closed", contentLength, remaining))
+			}
+			return errIncompleteResponse
+		}
+
+		response.Body = p.data[p.offset : p.offset+contentLength]
+		p.offset += contentLength
+		return nil
+	}
+
+	// No Content-Length or Transfer-Encoding: the body is delimited by the connection being closed
+	if !p.isComplete {
+		return errIncompleteResponse
+	}
+
+	response.Body = p.data[p.offset:]
+	p.offset = len(p.data)
+	return nil
+}
+
+// parseChunkedBody parses a body with "Transfer-Encoding: chunked"
+func (p *responseParser) parseChunkedBody(response *Response) error {
+	body := []byte{}
+
+	for {
+		sizeLine, lineEndOffset, err := p.readLine("chunk size")
+		if err != nil {
+			return err
+		}
+
+		sizeString, _, _ := strings.Cut(sizeLine, ";") // Ignore chunk extensions
+		size, err := strconv.ParseInt(strings.TrimSpace(sizeString), 16, 64)
+		if err != nil || size < 0 {
+			return p.errorAt(p.offset, fmt.Sprintf("expected hexadecimal chunk size, got %q", sizeLine))
+		}
+		p.offset = lineEndOffset
+
+		if size == 0 {
+			// Skip trailers until the final empty line
+			for {
+				line, lineEndOffset, err := p.readLine("chunked body trailer")
+				if err != nil {
+					return err
+				}
+				p.offset = lineEndOffset
+				if line == "" {
+					response.Body = body
+					return nil
+				}
+			}
+		}
+
+		// Compared before computing the end offset, since a huge size would overflow it
+		if size > int64(len(p.data)-p.offset-2) {
+			if p.isComplete {
+				return p.errorAt(len(p.data), fmt.Sprintf("expected chunk of %d bytes followed by \\r\\n", size))
+			}
+			return errIncompleteResponse
+		}
+
+		chunkEndOffset := p.offset + int(size)
+		if !bytes.Equal(p.data[chunkEndOffset:chunkEndOffset+2], []byte("\r\n")) {
+			return p.errorAt(chunkEndOffset, fmt.Sprintf("expected \\r\\n after chunk of %d bytes", size))
+		}
+
+		body = append(body, p.data[p.offset:chunkEndOffset]...)
+		p.offset = chunkEndOffset + 2
+	}
+}
+
+// readLine returns the line starting at p.offset (without "\r\n") and the offset just after "\r\n".
+//
+// It doesn't advance p.offset, so that errors about the line can point to its start.
+func (p *responseParser) readLine(description string) (string, int, error) {
+	index := bytes.Index(p.data[p.offset:], []byte("\n"))
+	if index == -1 {
+		if p.isComplete {
+			return "", 0, p.errorAt(len(p.data), fmt.Sprintf("unexpected end of response while reading %s", description))
+		}
+		return "", 0, errIncompleteResponse
+	}
+
+	newlineOffset := p.offset + index
+	if index == 0 || p.data[newlineOffset-1] != '\r' {
+		return "", 0, p.errorAt(newlineOffset, fmt.Sprintf("expected %s to end with \\r\\n, found \\n without \\r", description))
+	}
+
+	return string(p.data[p.offset : newlineOffset-1]), newlineOffset + 1, nil
+}
+
+func (p *responseParser) errorAt(offset int, message string) *ParseError {
+	return &ParseError{Data: p.data, Offset: offset, Message: message}
+}
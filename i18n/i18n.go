@@ -0,0 +1,124 @@
+// Package i18n translates user-facing tester messages.
+//
+// Messages are identified by their English format string, so code reads the same as it did before translation:
+//
+//	return i18n.Errorf("expected exit code %d, got %d", expected, actual)
+//
+// Translations are registered per locale with Register (testers can also use TesterDefinition.Messages). Messages
+// without a translation in the current locale are shown in English.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Locale is a language code, like "en" or "zh"
+type Locale string
+
+const (
+	English Locale = "en"
+	Chinese Locale = "zh"
+)
+
+var (
+	mutex         sync.RWMutex
+	currentLocale = English
+	catalogs      = map[Locale]map[string]string{}
+)
+
+// Register adds translations for locale, keyed by the English format string. Registering a message again replaces
+// the previous translation, so testers can override the built-in ones.
+func Register(locale Locale, messages map[string]string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if catalogs[locale] == nil {
+		catalogs[locale] = map[string]string{}
+	}
+
+	for message, translation := range messages {
+		catalogs[locale][message] = translation
+	}
+}
+
+// SetLocale sets the locale used by T and Errorf
+func SetLocale(locale Locale) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	currentLocale = locale
+}
+
+// CurrentLocale returns the locale used by T and Errorf
+func CurrentLocale() Locale {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	return currentLocale
+}
+
+// SupportedLocales returns English and every locale with registered translations, sorted
+func SupportedLocales() []Locale {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	locales := []Locale{English}
+	for locale := range catalogs {
+		if locale != English {
+			locales = append(locales, locale)
+		}
+	}
+	slices.Sort(locales)
+
+	return locales
+}
+
+// ParseLocale returns the supported locale for a value like "zh", "zh-CN" or "zh_CN.UTF-8". Only the language is
+// used, so "zh-TW" is also Chinese.
+func ParseLocale(value string) (Locale, error) {
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(value)), ".")
+	language, _, _ = strings.Cut(language, "_")
+	language, _, _ = strings.Cut(language, "-")
+
+	supportedLocales := SupportedLocales()
+	if slices.Contains(supportedLocales, Locale(language)) {
+		return Locale(language), nil
+	}
+
+	names := make([]string, len(supportedLocales))
+	for i, locale := range supportedLocales {
+		names[i] = string(locale)
+	}
+
+	return "", fmt.Errorf("unsupported locale %q (supported: %s)", value, strings.Join(names, ", "))
+}
+
+// T translates message to the current locale and formats it with args like fmt.Sprintf. Without args, message is
+// returned as is (so it may contain %).
+func T(message string, args ...any) string {
+	translated := translate(message)
+	if len(args) == 0 {
+		return translated
+	}
+
+	return fmt.Sprintf(translated, args...)
+}
+
+// Errorf translates format to the current locale and returns an error like fmt.Errorf (so %w can be used)
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(translate(format), args...)
+}
+
+func translate(message string) string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	if translation, ok := catalogs[currentLocale][message]; ok {
+		return translation
+	}
+
+	return message
+}
@@ -0,0 +1,102 @@
+package i18n
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useLocale sets the locale for the duration of the test
+func useLocale(t *testing.T, locale Locale) {
+	previousLocale := CurrentLocale()
+	SetLocale(locale)
+	t.Cleanup(func() { SetLocale(previousLocale) })
+}
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Locale
+	}{
+		{"en", English},
+		{"EN", English},
+		{"en_US.UTF-8", English},
+		{"zh", Chinese},
+		{"zh-CN", Chinese},
+		{"zh_CN.UTF-8", Chinese},
+		{" zh-Hans ", Chinese},
+	}
+
+	for _, tt := range tests {
+		locale, err := ParseLocale(tt.value)
+		if assert.NoError(t, err, "value=%q", tt.value) {
+			assert.Equal(t, tt.expected, locale, "value=%q", tt.value)
+		}
+	}
+
+	_, err := ParseLocale("fr")
+	assert.EqualError(t, err, `unsupported locale "fr" (supported: en, zh)`)
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "expected exit code 0, got 1", T("expected exit code %d, got %d", 0, 1))
+	assert.Equal(t, "100% untranslated", T("100% untranslated"))
+
+	useLocale(t, Chinese)
+	assert.Equal(t, "期望退出码为 0，实际为 1", T("expected exit code %d, got %d", 0, 1))
+	assert.Equal(t, "测试通过。", T("Test passed."))
+
+	// Messages without a translation fall back to English
+	assert.Equal(t, "no translation for 3", T("no translation for %d", 3))
+}
+
+func TestErrorf(t *testing.T) {
+	useLocale(t, Chinese)
+
+	limitErr := errors.New("limit")
+	err := Errorf("process exceeded memory limit (%s): %w", "1 MB", limitErr)
+
+	assert.EqualError(t, err, "进程超出内存限制（1 MB）：limit")
+	assert.ErrorIs(t, err, limitErr)
+}
+
+func TestRegister(t *testing.T) {
+	const locale Locale = "xx-test"
+
+	Register(locale, map[string]string{"Hello, %s": "Hi %s"})
+	defer func() {
+		mutex.Lock()
+		delete(catalogs, locale)
+		mutex.Unlock()
+	}()
+
+	assert.Contains(t, SupportedLocales(), locale)
+
+	useLocale(t, locale)
+	assert.Equal(t, "Hi Alice", T("Hello, %s", "Alice"))
+
+	// Registering again overrides the previous translation
+	Register(locale, map[string]string{"Hello, %s": "Hey %s"})
+	assert.Equal(t, "Hey Alice", T("Hello, %s", "Alice"))
+}
+
+func TestChineseMessagesKeepPlaceholders(t *testing.T) {
+	for message, translation := range chineseMessages {
+		require.Equal(t, placeholders(message), placeholders(translation), "message=%q", message)
+	}
+}
+
+// placeholders returns the formatting verbs in format, like ["%d", "%q"]
+func placeholders(format string) []string {
+	verbs := []string{}
+	for i := 0; i < len(format)-1; i++ {
+		if format[i] == '%' {
+			verbs = append(verbs, format[i:i+2])
+			i++
+		}
+	}
+
+	return verbs
+}
@@ -0,0 +1,70 @@
+package i18n
+
+func init() {
+	Register(Chinese, chineseMessages)
+}
+
+// chineseMessages are the built-in Chinese translations. Placeholders must match the English message in order and type.
+var chineseMessages = map[string]string{
+	// runner
+	"expected %q, got %q":                        "期望输出 %q，实际输出 %q",
+	"expected exit code %d, got %d":              "期望退出码为 %d，实际为 %d",
+	"Stderr: %s":                                 "标准错误输出：%s",
+	"expected output to contain %q":              "期望输出包含 %q",
+	"expected output to match pattern %q":        "期望输出匹配模式 %q",
+	"output mismatch":                            "输出不匹配",
+	"output did not match hidden expected value": "输出与隐藏的期望值不匹配",
+	"invalid regex pattern: %v":                  "无效的正则表达式：%v",
+	"expected program to reject input and wait for more, but it exited": "期望程序拒绝该输入并等待新的输入，但程序退出了",
+	"program not started, call Start() first":                           "程序尚未启动，请先调用 Start()",
+	"program not yet executed":                                          "程序尚未运行",
+	"failed to send input: %v":                                          "发送输入失败：%v",
+	"failed to compile %s: %s":                                          "编译 %s 失败：%s",
+	"pipeline requires at least one command":                            "管道至少需要一个命令",
+	"pipeline not yet executed":                                         "管道尚未运行",
+	"pipeline has no command at index %d":                               "管道中没有索引为 %d 的命令",
+	"expected %d exit codes for pipeline, got %d":                       "管道需要 %d 个退出码，实际给出 %d 个",
+	"pipeline command #%d (%s): %s":                                     "管道第 %d 个命令（%s）：%s",
+	"%s is not an executable file":                                      "%s 不是可执行文件",
+
+	// text_diff_visualizer
+	"expected trailing newline":                                    "期望输出以换行符结尾",
+	"unexpected trailing newline":                                  "输出末尾多了换行符",
+	"unexpected blank line %d":                                     "第 %d 行是多余的空行",
+	"missing blank line (line %d of the expected output)":          "缺少空行（期望输出的第 %d 行）",
+	"line %d ends with \\r\\n (Windows line ending), expected \\n": "第 %d 行以 \\r\\n（Windows 换行符）结尾，期望为 \\n",
+	"line %d ends with \\n, expected \\r\\n":                       "第 %d 行以 \\n 结尾，期望为 \\r\\n",
+	"extra spaces on blank line %d":                                "第 %d 行应为空行，但有多余的空格",
+	"missing spaces on blank line %d":                              "第 %d 行缺少空格",
+	"line %d is indented with tabs, expected spaces":               "第 %d 行使用制表符缩进，期望使用空格",
+	"line %d is indented with spaces, expected tabs":               "第 %d 行使用空格缩进，期望使用制表符",
+	"extra spaces at start of line %d":                             "第 %d 行开头有多余的空格",
+	"missing spaces at start of line %d":                           "第 %d 行开头缺少空格",
+	"extra spaces at end of line %d":                               "第 %d 行末尾有多余的空格",
+	"missing spaces at end of line %d":                             "第 %d 行末尾缺少空格",
+	"spaces between words differ on line %d":                       "第 %d 行单词之间的空格不同",
+
+	// executable
+	"execution timed out": "运行超时",
+	"%s not found":        "找不到 %s",
+	"%s (resolved to %s) is not an executable file":               "%s（解析为 %s）不是可执行文件",
+	"process already in progress":                                 "进程已在运行",
+	"process not started":                                         "进程尚未启动",
+	"process exceeded memory limit (%s): %w":                      "进程超出内存限制（%s）：%w",
+	"program failed to exit in 2 seconds after receiving sigterm": "程序收到 SIGTERM 后 2 秒内未退出",
+
+	// test_runner
+	"Running tests for %s":                "正在运行 %s 的测试",
+	"timed out, test exceeded %d seconds": "超时，测试超过了 %d 秒",
+	"Test passed.":                        "测试通过。",
+	"Test failed":                         "测试失败",
+	"Hint: %s":                            "提示：%s",
+	"Hint %d/%d: %s":                      "提示 %d/%d：%s",
+	"Another hint will be shown if this stage fails again.":                                           "如果该 stage 再次失败，将显示下一条提示。",
+	"(%d lines of output hidden since the stage passed, set debug: true in bootllm.yml to show them)": "（该 stage 已通过，隐藏了 %d 行输出，在 bootllm.yml 中设置 debug: true 可显示）",
+
+	// tester
+	"Build succeeded.": "构建成功。",
+	"BootLLM internal error. Error fetching tester context: %v":   "BootLLM 内部错误。获取 tester 上下文失败：%v",
+	"BootLLM internal error. Error validating tester context: %v": "BootLLM 内部错误。校验 tester 上下文失败：%v",
+}
@@ -0,0 +1,63 @@
+package internal
+
+import "strings"
+
+// ClosestMatch returns the candidate closest to input (by edit distance, ignoring case), if it's close enough to be a
+// likely typo. Used for "did you mean" suggestions.
+func ClosestMatch(input string, candidates []string) (string, bool) {
+	bestMatch := ""
+	bestDistance := -1
+
+	for _, candidate := range candidates {
+		distance := levenshteinDistance(strings.ToLower(input), strings.ToLower(candidate))
+		if bestDistance == -1 || distance < bestDistance {
+			bestMatch = candidate
+			bestDistance = distance
+		}
+	}
+
+	// Allow roughly one typo per 3 characters, and at least 2 (e.g. a swapped pair of letters)
+	maxDistance := max(2, len(input)/3)
+	if bestDistance == -1 || bestDistance > maxDistance {
+		return "", false
+	}
+
+	return bestMatch, true
+}
+
+// DidYouMean returns ` Did you mean "x"?` if a close match for input is found in candidates, or "" otherwise
+func DidYouMean(input string, candidates []string) string {
+	if match, ok := ClosestMatch(input, candidates); ok {
+		return ` Did you mean "` + match + `"?`
+	}
+
+	return ""
+}
+
+func levenshteinDistance(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(br)]
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"debug", "language", "build", "run"}
+
+	tests := []struct {
+		input         string
+		expected      string
+		expectedFound bool
+	}{
+		{"debgu", "debug", true},
+		{"Debug", "debug", true},
+		{"langauge", "language", true},
+		{"buidl", "build", true},
+		{"rn", "run", true},
+		{"timeout", "", false},
+		{"", "run", false},
+	}
+
+	for _, tt := range tests {
+		match, found := ClosestMatch(tt.input, candidates)
+		assert.Equal(t, tt.expectedFound, found, "input=%q", tt.input)
+		if tt.expectedFound {
+			assert.Equal(t, tt.expected, match, "input=%q", tt.input)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	assert.Equal(t, ` Did you mean "debug"?`, DidYouMean("debgu", []string{"debug"}))
+	assert.Equal(t, "", DidYouMean("xyz", []string{"debug"}))
+}
+
+func TestLevenshteinDistance(t *testing.T) {
+	assert.Equal(t, 0, levenshteinDistance("abc", "abc"))
+	assert.Equal(t, 3, levenshteinDistance("", "abc"))
+	assert.Equal(t, 1, levenshteinDistance("abc", "abd"))
+	assert.Equal(t, 2, levenshteinDistance("debgu", "debug"))
+	assert.Equal(t, 3, levenshteinDistance("kitten", "sitting"))
+}
@@ -3,11 +3,11 @@ package logger
 import (
 	"fmt"
 	"io"
-	"log"
-	"os"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/bootllm/tester-utils/redact"
 	"github.com/fatih/color"
 )
 
@@ -30,30 +30,10 @@ func colorize(colorToUse color.Attribute, fstring string, args ...any) []string
 	return colorizedLines
 }
 
-func debugColorize(fstring string, args ...any) []string {
-	return colorize(color.FgCyan, fstring, args...)
-}
-
-func infoColorize(fstring string, args ...any) []string {
-	return colorize(color.FgHiBlue, fstring, args...)
-}
-
-func successColorize(fstring string, args ...any) []string {
-	return colorize(color.FgHiGreen, fstring, args...)
-}
-
-func errorColorize(fstring string, args ...any) []string {
-	return colorize(color.FgHiRed, fstring, args...)
-}
-
 func yellowColorize(fstring string, args ...any) []string {
 	return colorize(color.FgYellow, fstring, args...)
 }
 
-func plainColorize(fstring string, args ...any) []string {
-	return colorize(color.Reset, fstring, args...)
-}
-
 // globalLogMutex serializes all logging operations for this package
 var globalLogMutex sync.Mutex
 
@@ -69,9 +49,9 @@ func (s syncWriter) Write(p []byte) (n int, err error) {
 	return n, err
 }
 
-// Logger is a wrapper around log.Logger with the following features:
+// Logger writes messages to a Sink, with the following features:
 //   - Supports a prefix
-//   - Adds colors to the output
+//   - Adds colors to the output (with the default text sink)
 //   - Debug mode (all logs, debug and above)
 //   - Quiet mode (only critical logs)
 //   - Serialized writes for all loggers in this package
@@ -88,36 +68,58 @@ type Logger struct {
 	// secondaryPrefixes is a slice of prefixes that are printed after Logger.prefix
 	secondaryPrefixes []string
 
-	logger log.Logger
+	// sink receives all messages
+	sink Sink
 }
 
-// GetLogger Returns a logger.
+// GetLogger Returns a logger writing to os.Stdout (or log events in streaming mode, see events.Enable).
 func GetLogger(isDebug bool, prefix string) *Logger {
 	color.NoColor = false
-	coloredPrefix := yellowColorize("%s", prefix)[0]
+	return GetLoggerWithSink(isDebug, prefix, defaultSink())
+}
+
+// GetLoggerWithWriter returns a logger writing colored text to w
+func GetLoggerWithWriter(isDebug bool, prefix string, w io.Writer) *Logger {
+	return GetLoggerWithSink(isDebug, prefix, NewTextSink(w))
+}
+
+// GetLoggerWithSink returns a logger writing to sink. Example, to capture logs in a test:
+//
+//	sink := logger.NewMemorySink()
+//	client := tcp_client.Connect(address, logger.GetLoggerWithSink(true, "[test] ", sink))
+//	...
+//	assert.Contains(t, sink.String(), "[test] Connected")
+func GetLoggerWithSink(isDebug bool, prefix string, sink Sink) *Logger {
 	return &Logger{
-		logger:  *log.New(syncWriter{writer: os.Stdout}, coloredPrefix, 0),
 		IsDebug: isDebug,
 		prefix:  prefix,
+		sink:    sink,
 	}
 }
 
-// Clone clones a given logger
+// Clone clones a given logger. The clone writes to the same sink.
 func (l *Logger) Clone() *Logger {
 	secondaryPrefixesCopy := make([]string, len(l.secondaryPrefixes))
 	copy(secondaryPrefixesCopy, l.secondaryPrefixes)
 
-	newSyncWriter := syncWriter{writer: os.Stdout}
-	newColoredPrefix := yellowColorize("%s", l.prefix)[0]
-
-	cloned := &Logger{
-		logger:            *log.New(newSyncWriter, newColoredPrefix, 0),
+	return &Logger{
 		IsDebug:           l.IsDebug,
 		IsQuiet:           l.IsQuiet,
 		prefix:            l.prefix,
 		secondaryPrefixes: secondaryPrefixesCopy,
+		sink:              l.sink,
 	}
-	cloned.updateLoggerPrefix()
+}
+
+// Sink returns the sink the logger writes to
+func (l *Logger) Sink() Sink {
+	return l.sink
+}
+
+// WithSink returns a clone of the logger writing to sink
+func (l *Logger) WithSink(sink Sink) *Logger {
+	cloned := l.Clone()
+	cloned.sink = sink
 
 	return cloned
 }
@@ -138,7 +140,6 @@ func (l *Logger) GetLastSecondaryPrefix() string {
 // UpdateSecondaryPrefixes replaces all secondary prefixes with the new one
 func (l *Logger) UpdateSecondaryPrefixes(prefixes []string) {
 	l.secondaryPrefixes = prefixes
-	l.updateLoggerPrefix()
 }
 
 // UpdateLastSecondaryPrefix updates the secondary prefix at the top of SecondaryPrefixes stack
@@ -150,26 +151,32 @@ func (l *Logger) UpdateLastSecondaryPrefix(newPrefix string) {
 // ResetSecondaryPrefixes clears all secondary prefixes
 func (l *Logger) ResetSecondaryPrefixes() {
 	l.secondaryPrefixes = []string{}
-	l.updateLoggerPrefix()
 }
 
-// updateLoggerPrefix updates the logger's prefix based on all secondary prefixes
-func (l *Logger) updateLoggerPrefix() {
-	if len(l.secondaryPrefixes) == 0 {
-		l.logger.SetPrefix(yellowColorize("%s", l.prefix)[0])
-	} else {
-		fullPrefix := l.prefix
-		for _, secondaryPrefix := range l.secondaryPrefixes {
-			fullPrefix += fmt.Sprintf("[%s] ", secondaryPrefix)
-		}
-		l.logger.SetPrefix(yellowColorize("%s", fullPrefix)[0])
+// fullPrefix returns the prefix followed by all secondary prefixes
+func (l *Logger) fullPrefix() string {
+	fullPrefix := l.prefix
+	for _, secondaryPrefix := range l.secondaryPrefixes {
+		fullPrefix += fmt.Sprintf("[%s] ", secondaryPrefix)
+	}
+
+	return fullPrefix
+}
+
+// print formats a message (fstring is used as is without args), masks secrets (see the redact package) and writes it
+// to the sink
+func (l *Logger) print(level Level, fstring string, args ...any) {
+	message := fstring
+	if len(args) > 0 {
+		message = fmt.Sprintf(fstring, args...)
 	}
+
+	l.sink.Write(Entry{Time: time.Now(), Level: level, Prefix: redact.String(l.fullPrefix()), Message: redact.String(message)})
 }
 
 // PushSecondaryPrefix pushes a new secondary prefix to secondaryPrefixes
 func (l *Logger) PushSecondaryPrefix(prefix string) {
 	l.secondaryPrefixes = append(l.secondaryPrefixes, prefix)
-	l.updateLoggerPrefix()
 }
 
 // PopSecondaryPrefix removes the secondary prefix from the top of secondaryPrefixes
@@ -179,7 +186,6 @@ func (l *Logger) PopSecondaryPrefix() string {
 	}
 	lastPrefix := l.secondaryPrefixes[len(l.secondaryPrefixes)-1]
 	l.secondaryPrefixes = l.secondaryPrefixes[:len(l.secondaryPrefixes)-1]
-	l.updateLoggerPrefix()
 	return lastPrefix
 }
 
@@ -194,12 +200,16 @@ func (l *Logger) WithAdditionalSecondaryPrefix(prefix string, fn func()) {
 // GetQuietLogger Returns a logger that only emits critical logs. Useful for anti-cheat stages.
 func GetQuietLogger(prefix string) *Logger {
 	color.NoColor = false
-	coloredPrefix := yellowColorize("%s", prefix)[0]
+	return GetQuietLoggerWithSink(prefix, defaultSink())
+}
+
+// GetQuietLoggerWithSink returns a logger like GetQuietLogger, writing to sink
+func GetQuietLoggerWithSink(prefix string, sink Sink) *Logger {
 	return &Logger{
-		logger:  *log.New(syncWriter{writer: os.Stdout}, coloredPrefix, 0),
 		IsDebug: false,
 		IsQuiet: true,
 		prefix:  prefix,
+		sink:    sink,
 	}
 }
 
@@ -208,18 +218,15 @@ func (l *Logger) Successf(fstring string, args ...any) {
 		return
 	}
 
-	for _, line := range successColorize(fstring, args...) {
-		l.logger.Println(line)
-	}
+	l.print(LevelSuccess, fstring, args...)
 }
 
 func (l *Logger) Successln(msg string) {
 	if l.IsQuiet {
 		return
 	}
-	for _, line := range successColorize("%s", msg) {
-		l.logger.Println(line)
-	}
+
+	l.print(LevelSuccess, "%s", msg)
 }
 
 func (l *Logger) Infof(fstring string, args ...any) {
@@ -227,9 +234,7 @@ func (l *Logger) Infof(fstring string, args ...any) {
 		return
 	}
 
-	for _, line := range infoColorize(fstring, args...) {
-		l.logger.Println(line)
-	}
+	l.print(LevelInfo, fstring, args...)
 }
 
 func (l *Logger) Infoln(msg string) {
@@ -237,9 +242,24 @@ func (l *Logger) Infoln(msg string) {
 		return
 	}
 
-	for _, line := range infoColorize("%s", msg) {
-		l.logger.Println(line)
+	l.print(LevelInfo, "%s", msg)
+}
+
+// Hintf is used for hints shown after a failure, in a color distinct from errors
+func (l *Logger) Hintf(fstring string, args ...any) {
+	if l.IsQuiet {
+		return
+	}
+
+	l.print(LevelHint, fstring, args...)
+}
+
+func (l *Logger) Hintln(msg string) {
+	if l.IsQuiet {
+		return
 	}
+
+	l.print(LevelHint, "%s", msg)
 }
 
 // Criticalf is to be used only in anti-cheat stages
@@ -248,9 +268,7 @@ func (l *Logger) Criticalf(fstring string, args ...any) {
 		panic("Critical is only for quiet loggers")
 	}
 
-	for _, line := range errorColorize(fstring, args...) {
-		l.logger.Println(line)
-	}
+	l.print(LevelCritical, fstring, args...)
 }
 
 // Criticalln is to be used only in anti-cheat stages
@@ -259,9 +277,7 @@ func (l *Logger) Criticalln(msg string) {
 		panic("Critical is only for quiet loggers")
 	}
 
-	for _, line := range errorColorize("%s", msg) {
-		l.logger.Println(line)
-	}
+	l.print(LevelCritical, "%s", msg)
 }
 
 func (l *Logger) Errorf(fstring string, args ...any) {
@@ -269,9 +285,7 @@ func (l *Logger) Errorf(fstring string, args ...any) {
 		return
 	}
 
-	for _, line := range errorColorize(fstring, args...) {
-		l.logger.Println(line)
-	}
+	l.print(LevelError, fstring, args...)
 }
 
 func (l *Logger) Errorln(msg string) {
@@ -279,9 +293,7 @@ func (l *Logger) Errorln(msg string) {
 		return
 	}
 
-	for _, line := range errorColorize("%s", msg) {
-		l.logger.Println(line)
-	}
+	l.print(LevelError, "%s", msg)
 }
 
 func (l *Logger) Debugf(fstring string, args ...any) {
@@ -289,9 +301,7 @@ func (l *Logger) Debugf(fstring string, args ...any) {
 		return
 	}
 
-	for _, line := range debugColorize(fstring, args...) {
-		l.logger.Println(line)
-	}
+	l.print(LevelDebug, fstring, args...)
 }
 
 func (l *Logger) Debugln(msg string) {
@@ -299,21 +309,13 @@ func (l *Logger) Debugln(msg string) {
 		return
 	}
 
-	for _, line := range debugColorize("%s", msg) {
-		l.logger.Println(line)
-	}
+	l.print(LevelDebug, "%s", msg)
 }
 
 func (l *Logger) Plainf(fstring string, args ...any) {
-	formattedString := fmt.Sprintf(fstring, args...)
-
-	for _, line := range plainColorize("%s", formattedString) {
-		l.logger.Println(line)
-	}
+	l.print(LevelPlain, "%s", fmt.Sprintf(fstring, args...))
 }
 
 func (l *Logger) Plainln(msg string) {
-	for _, line := range plainColorize("%s", msg) {
-		l.logger.Println(line)
-	}
+	l.print(LevelPlain, "%s", msg)
 }
@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bootllm/tester-utils/events"
+	"github.com/fatih/color"
+)
+
+// Level is the level of a log message
+type Level string
+
+const (
+	LevelDebug    Level = "debug"
+	LevelInfo     Level = "info"
+	LevelSuccess  Level = "success"
+	LevelError    Level = "error"
+	LevelHint     Level = "hint"
+	LevelCritical Level = "critical"
+	LevelPlain    Level = "plain"
+)
+
+// levelColors are the colors used by text sinks for each level
+var levelColors = map[Level]color.Attribute{
+	LevelDebug:    color.FgCyan,
+	LevelInfo:     color.FgHiBlue,
+	LevelSuccess:  color.FgHiGreen,
+	LevelError:    color.FgHiRed,
+	LevelHint:     color.FgHiMagenta,
+	LevelCritical: color.FgHiRed,
+	LevelPlain:    color.Reset,
+}
+
+// Entry is a single log message, passed to a Sink
+type Entry struct {
+	Time  time.Time
+	Level Level
+
+	// Prefix is the logger's prefix followed by its secondary prefixes, like "[stage-1] [your_program] "
+	Prefix string
+
+	// Message may span several lines
+	Message string
+}
+
+// Sink receives the messages written by a Logger. Sinks can be shared by several loggers, so Write must be safe to
+// call concurrently.
+type Sink interface {
+	Write(entry Entry)
+}
+
+// textSink writes messages as text, one line per line of the message, each starting with the prefix
+type textSink struct {
+	writer  io.Writer
+	colored bool
+}
+
+// NewTextSink returns a sink writing colored text to w (unless colors are disabled with color.NoColor), the format
+// loggers use by default
+func NewTextSink(w io.Writer) Sink {
+	return textSink{writer: syncWriter{writer: w}, colored: true}
+}
+
+// NewPlainTextSink returns a sink writing text without colors to w, for files
+func NewPlainTextSink(w io.Writer) Sink {
+	return textSink{writer: syncWriter{writer: w}, colored: false}
+}
+
+func (s textSink) Write(entry Entry) {
+	prefix := entry.Prefix
+	if s.colored {
+		prefix = yellowColorize("%s", prefix)[0]
+	}
+
+	var text strings.Builder
+	for _, line := range strings.Split(entry.Message, "\n") {
+		if s.colored {
+			line = colorize(levelColors[entry.Level], "%s", line)[0]
+		}
+
+		text.WriteString(prefix + line + "\n")
+	}
+
+	// A single write, so lines of a message aren't interleaved with other messages
+	s.writer.Write([]byte(text.String()))
+}
+
+// jsonSink writes messages as newline-delimited JSON
+type jsonSink struct {
+	writer io.Writer
+}
+
+// jsonEntry is the format of entries written by jsonSink
+type jsonEntry struct {
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Prefix  string    `json:"prefix,omitempty"`
+	Message string    `json:"message"`
+}
+
+// NewJSONSink returns a sink writing each message to w as a line of JSON, with the fields time, level, prefix and
+// message
+func NewJSONSink(w io.Writer) Sink {
+	return jsonSink{writer: syncWriter{writer: w}}
+}
+
+func (s jsonSink) Write(entry Entry) {
+	line, err := json.Marshal(jsonEntry{
+		Time:    entry.Time.UTC(),
+		Level:   entry.Level,
+		Prefix:  strings.TrimSpace(entry.Prefix),
+		Message: entry.Message,
+	})
+	if err != nil {
+		return
+	}
+
+	s.writer.Write(append(line, '\n'))
+}
+
+// eventSink emits messages as log events (see the events package)
+type eventSink struct{}
+
+func (eventSink) Write(entry Entry) {
+	events.Emit(events.Event{
+		Type:    events.TypeLog,
+		Time:    entry.Time.UTC(),
+		Level:   string(entry.Level),
+		Prefix:  strings.TrimSpace(entry.Prefix),
+		Message: entry.Message,
+	})
+}
+
+// MemorySink keeps messages in memory, to inspect them later (e.g. in tests)
+type MemorySink struct {
+	mutex   sync.Mutex
+	entries []Entry
+}
+
+// NewMemorySink returns an empty MemorySink
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Write(entry Entry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries = append(s.entries, entry)
+}
+
+// Entries returns a copy of the messages written so far
+func (s *MemorySink) Entries() []Entry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Entry{}, s.entries...)
+}
+
+// String returns the messages written so far as text without colors, like NewPlainTextSink would write them
+func (s *MemorySink) String() string {
+	var text strings.Builder
+	sink := NewPlainTextSink(&text)
+
+	for _, entry := range s.Entries() {
+		sink.Write(entry)
+	}
+
+	return text.String()
+}
+
+// teeSink writes messages to several sinks
+type teeSink []Sink
+
+// NewTeeSink returns a sink writing every message to all of sinks, e.g. to the terminal and a file:
+//
+//	logger.NewTeeSink(logger.NewTextSink(os.Stdout), logger.NewPlainTextSink(file))
+func NewTeeSink(sinks ...Sink) Sink {
+	return teeSink(sinks)
+}
+
+func (s teeSink) Write(entry Entry) {
+	for _, sink := range s {
+		sink.Write(entry)
+	}
+}
+
+// defaultSink is used by GetLogger: log events in streaming mode (see events.Enable), colored text on os.Stdout
+// otherwise
+func defaultSink() Sink {
+	if events.Enabled() {
+		return eventSink{}
+	}
+
+	return NewTextSink(os.Stdout)
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bootllm/tester-utils/redact"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainTextSink(t *testing.T) {
+	var buf bytes.Buffer
+	l := GetLoggerWithSink(true, "[stage-1] ", NewPlainTextSink(&buf))
+
+	l.Infof("Running %s", "hello")
+	l.WithAdditionalSecondaryPrefix("your_program", func() {
+		l.Plainln("line 1\nline 2")
+	})
+	l.Debugln("100% done")
+
+	assert.Equal(t, "[stage-1] Running hello\n[stage-1] [your_program] line 1\n[stage-1] [your_program] line 2\n[stage-1] 100% done\n", buf.String())
+}
+
+func TestTextSinkColors(t *testing.T) {
+	previousNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = previousNoColor }()
+
+	var buf bytes.Buffer
+	GetLoggerWithWriter(false, "[build] ", &buf).Errorln("failed")
+
+	assert.Equal(t, "\x1b[33m[build] \x1b[0m\x1b[91mfailed\x1b[0m\n", buf.String())
+}
+
+func TestLevels(t *testing.T) {
+	sink := NewMemorySink()
+
+	l := GetLoggerWithSink(false, "", sink)
+	l.Debugf("hidden unless debug")
+	l.Successln("ok")
+	l.Hintf("hint %d", 1)
+
+	quiet := GetQuietLoggerWithSink("", sink)
+	quiet.Infoln("hidden when quiet")
+	quiet.Criticalf("critical")
+
+	levels := []Level{}
+	for _, entry := range sink.Entries() {
+		levels = append(levels, entry.Level)
+		assert.False(t, entry.Time.IsZero())
+	}
+	assert.Equal(t, []Level{LevelSuccess, LevelHint, LevelCritical}, levels)
+	assert.Equal(t, "ok\nhint 1\ncritical\n", sink.String())
+}
+
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	GetLoggerWithSink(false, "[stage-1] ", NewJSONSink(&buf)).Infoln("hello\nworld")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "[stage-1]", entry["prefix"])
+	assert.Equal(t, "hello\nworld", entry["message"])
+	assert.Contains(t, entry, "time")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestTeeSink(t *testing.T) {
+	first, second := NewMemorySink(), NewMemorySink()
+	GetLoggerWithSink(false, "", NewTeeSink(first, second)).Infoln("both")
+
+	assert.Equal(t, "both\n", first.String())
+	assert.Equal(t, "both\n", second.String())
+}
+
+func TestCloneKeepsSink(t *testing.T) {
+	sink := NewMemorySink()
+	l := GetLoggerWithSink(false, "[test] ", sink)
+	l.PushSecondaryPrefix("client")
+
+	cloned := l.Clone()
+	cloned.PushSecondaryPrefix("request")
+	cloned.Infoln("from clone")
+	l.Infoln("from original")
+
+	assert.Equal(t, "[test] [client] [request] from clone\n[test] [client] from original\n", sink.String())
+
+	other := NewMemorySink()
+	l.WithSink(other).Infoln("elsewhere")
+	assert.Equal(t, "[test] [client] elsewhere\n", other.String())
+	assert.Same(t, sink, l.Sink())
+}
+
+func TestRedaction(t *testing.T) {
+	redact.AddSecrets("hunter2")
+	defer redact.Reset()
+
+	sink := NewMemorySink()
+	l := GetLoggerWithSink(false, "[hunter2] ", sink)
+	l.Infof("password is %s", "hunter2")
+
+	assert.Equal(t, "[[REDACTED]] password is [REDACTED]\n", sink.String())
+}
@@ -0,0 +1,101 @@
+// Package redact masks secrets in everything the tester logs or reports: logs, events, the run history and the watch
+// summary.
+//
+// Secrets are registered once, usually at startup:
+//
+//	redact.AddSecrets(apiKey)
+//	redact.AddPattern(regexp.MustCompile(`sk-[A-Za-z0-9]{20,}`))
+//
+// Values of environment variables starting with BOOTLLM_SECRET are registered by the tester automatically (see
+// AddSecretsFromEnv), since they're also hidden from the user's program (see executable.GetSafeEnvironmentVariables).
+package redact
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mask replaces secrets
+const Mask = "[REDACTED]"
+
+// SecretEnvPrefix is the prefix of environment variables holding secrets
+const SecretEnvPrefix = "BOOTLLM_SECRET"
+
+var (
+	mutex    sync.RWMutex
+	secrets  []string
+	patterns []*regexp.Regexp
+)
+
+// AddSecrets registers values to mask. Empty values are ignored.
+func AddSecrets(values ...string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, value := range values {
+		if value != "" && !slices.Contains(secrets, value) {
+			secrets = append(secrets, value)
+		}
+	}
+
+	// Longer secrets first, so that a secret containing another one is masked entirely
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+}
+
+// AddPattern registers a pattern whose matches are masked
+func AddPattern(pattern *regexp.Regexp) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	patterns = append(patterns, pattern)
+}
+
+// AddSecretsFromEnv registers the values of variables in env starting with SecretEnvPrefix
+func AddSecretsFromEnv(env map[string]string) {
+	for name, value := range env {
+		if strings.HasPrefix(name, SecretEnvPrefix) {
+			AddSecrets(value)
+		}
+	}
+}
+
+// Reset removes all secrets and patterns. Used in tests.
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	secrets = nil
+	patterns = nil
+}
+
+// String returns s with every secret and every match of a pattern replaced by Mask
+func String(s string) string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+
+	for _, pattern := range patterns {
+		s = pattern.ReplaceAllLiteralString(s, Mask)
+	}
+
+	return s
+}
+
+// Strings returns values with String applied to each one
+func Strings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	redacted := make([]string, len(values))
+	for i, value := range values {
+		redacted[i] = String(value)
+	}
+
+	return redacted
+}
@@ -0,0 +1,40 @@
+package redact
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	t.Cleanup(Reset)
+
+	assert.Equal(t, "token abc", String("token abc"), "nothing registered")
+
+	AddSecrets("abc", "", "abcdef", "abc")
+	AddPattern(regexp.MustCompile(`sk-[a-z0-9]+`))
+
+	assert.Equal(t, "token [REDACTED] and [REDACTED]", String("token abcdef and abc"))
+	assert.Equal(t, "key=[REDACTED]", String("key=sk-123xyz"))
+	assert.Equal(t, []string{"[REDACTED]", "ok"}, Strings([]string{"abc", "ok"}))
+	assert.Nil(t, Strings(nil))
+}
+
+func TestAddSecretsFromEnv(t *testing.T) {
+	t.Cleanup(Reset)
+
+	AddSecretsFromEnv(map[string]string{
+		"BOOTLLM_SECRET_API_KEY": "hunter2",
+		"BOOTLLM_DEBUG":          "true",
+	})
+
+	assert.Equal(t, "password: [REDACTED], debug: true", String("password: hunter2, debug: true"))
+}
+
+func TestReset(t *testing.T) {
+	AddSecrets("abc")
+	Reset()
+
+	assert.Equal(t, "abc", String("abc"))
+}
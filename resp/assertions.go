@@ -0,0 +1,87 @@
+package resp
+
+import (
+	"fmt"
+)
+
+// ExpectSimpleString checks that value is a simple string equal to expected
+func ExpectSimpleString(value Value, expected string) error {
+	return expectStringLike(value, SimpleString, expected)
+}
+
+// ExpectBulkString checks that value is a bulk string equal to expected
+func ExpectBulkString(value Value, expected string) error {
+	return expectStringLike(value, BulkString, expected)
+}
+
+// ExpectError checks that value is an error (simple or bulk) with the exact message expected
+func ExpectError(value Value, expected string) error {
+	if value.Type == BulkError {
+		return expectStringLike(value, BulkError, expected)
+	}
+
+	return expectStringLike(value, Error, expected)
+}
+
+// ExpectInteger checks that value is an integer equal to expected
+func ExpectInteger(value Value, expected int64) error {
+	if value.Type != Integer {
+		return typeMismatchError(Integer, value)
+	}
+
+	if value.Integer() != expected {
+		return fmt.Errorf("expected integer %d, got %d", expected, value.Integer())
+	}
+
+	return nil
+}
+
+// ExpectNil checks that value is nil (a RESP2 null bulk string or null array, or RESP3 null)
+func ExpectNil(value Value) error {
+	if value.Type != Nil {
+		return typeMismatchError(Nil, value)
+	}
+
+	return nil
+}
+
+// ExpectArray checks that value is an array whose elements are equal to expected
+func ExpectArray(value Value, expected []Value) error {
+	if value.Type != Array {
+		return typeMismatchError(Array, value)
+	}
+
+	elements := value.Elements()
+	if len(elements) != len(expected) {
+		return fmt.Errorf("expected array of length %d, got %d: %s", len(expected), len(elements), value.FormattedString())
+	}
+
+	for i := range expected {
+		if !elements[i].Equal(expected[i]) {
+			return fmt.Errorf("expected element #%d of array to be %s %s, got %s %s", i+1, expected[i].Type, expected[i].FormattedString(), elements[i].Type, elements[i].FormattedString())
+		}
+	}
+
+	return nil
+}
+
+// ExpectBulkStringArray checks that value is an array of bulk strings equal to expected
+func ExpectBulkStringArray(value Value, expected []string) error {
+	return ExpectArray(value, NewBulkStringArrayValue(expected...).Elements())
+}
+
+func expectStringLike(value Value, expectedType ValueType, expected string) error {
+	if value.Type != expectedType {
+		return typeMismatchError(expectedType, value)
+	}
+
+	if value.String() != expected {
+		return fmt.Errorf("expected %s %q, got %q", expectedType, expected, value.String())
+	}
+
+	return nil
+}
+
+func typeMismatchError(expectedType ValueType, value Value) error {
+	return fmt.Errorf("expected %s, got %s %s", expectedType, value.Type, value.FormattedString())
+}
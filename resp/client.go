@@ -0,0 +1,40 @@
+package resp
+
+import (
+	"errors"
+
+	"github.com/bootllm/tester-utils/tcp_client"
+)
+
+// SendCommand encodes args as a command and sends it using client
+//
+//	if err := resp.SendCommand(client, "SET", "foo", "bar"); err != nil {
+//	    return err
+//	}
+//	value, err := resp.ReadValue(client)
+func SendCommand(client *tcp_client.Client, args ...string) error {
+	return client.Send(EncodeCommand(args...))
+}
+
+// ReadValue reads a single RESP value from client, waiting up to client.Timeout for it to arrive
+func ReadValue(client *tcp_client.Client) (Value, error) {
+	var value Value
+
+	err := client.ReadFrame(func(received []byte) (int, bool, error) {
+		decodedValue, bytesRead, err := Decode(received)
+
+		var incompleteInputError *IncompleteInputError
+		if errors.As(err, &incompleteInputError) {
+			return 0, false, nil
+		}
+
+		if err != nil {
+			return 0, false, err
+		}
+
+		value = decodedValue
+		return bytesRead, true, nil
+	})
+
+	return value, err
+}
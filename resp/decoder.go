@@ -0,0 +1,342 @@
+package resp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/bootllm/tester-utils/inspectable_byte_string"
+)
+
+// IncompleteInputError is returned by Decode when data is a valid prefix of a RESP value, but more bytes are needed
+type IncompleteInputError struct {
+	Message string
+}
+
+func (e *IncompleteInputError) Error() string {
+	return e.Message
+}
+
+// InvalidInputError is returned by Decode when data isn't valid RESP
+type InvalidInputError struct {
+	// Data is the input that was being decoded
+	Data []byte
+
+	// Offset is the index in Data where decoding failed
+	Offset int
+
+	// Message describes what was expected at Offset
+	Message string
+}
+
+func (e *InvalidInputError) Error() string {
+	formatted := inspectable_byte_string.NewInspectableByteString(e.Data).FormatWithHighlightedOffset(e.Offset, "error", "Received: ", "")
+	return fmt.Sprintf("%s\nError: %s", formatted, e.Message)
+}
+
+// Decode decodes a single RESP2 or RESP3 value from the start of data.
+//
+// Returns the value and the number of bytes read. Parsing is strict: for example, "\n" isn't accepted in place of
+// "\r\n", and lengths must match the data exactly.
+//
+// If data is incomplete, an *IncompleteInputError is returned. If data is invalid, an *InvalidInputError is returned,
+// pointing to the exact offset of the error.
+func Decode(data []byte) (value Value, bytesRead int, err error) {
+	d := &decoder{data: data}
+
+	value, err = d.decodeValue()
+	if err != nil {
+		return Value{}, 0, err
+	}
+
+	return value, d.offset, nil
+}
+
+// maxLength is the largest blob length or aggregate count accepted, matching Redis' default proto-max-bulk-len.
+// Larger values are rejected up front, so that a bogus length can't overflow offsets or exhaust memory.
+const maxLength = 512 * 1024 * 1024
+
+type decoder struct {
+	data   []byte
+	offset int
+}
+
+func (d *decoder) decodeValue() (Value, error) {
+	if d.offset >= len(d.data) {
+		return Value{}, d.incomplete("expected start of a RESP value")
+	}
+
+	typeByteOffset := d.offset
+	typeByte := d.data[d.offset]
+	d.offset++
+
+	switch typeByte {
+	case '+':
+		line, err := d.readLine()
+		return NewSimpleStringValue(line), err
+	case '-':
+		line, err := d.readLine()
+		return NewErrorValue(line), err
+	case ':':
+		return d.decodeInteger()
+	case '$':
+		return d.decodeBlob(BulkString)
+	case '*':
+		return d.decodeAggregate(Array)
+	case '_':
+		if _, err := d.readExpectedLine(""); err != nil {
+			return Value{}, err
+		}
+		return NewNilValue(), nil
+	case '#':
+		return d.decodeBoolean()
+	case ',':
+		return d.decodeDouble()
+	case '(':
+		return d.decodeBigNumber()
+	case '!':
+		return d.decodeBlob(BulkError)
+	case '=':
+		return d.decodeBlob(VerbatimString)
+	case '%':
+		return d.decodeAggregate(Map)
+	case '~':
+		return d.decodeAggregate(Set)
+	case '>':
+		return d.decodeAggregate(Push)
+	default:
+		return Value{}, d.invalidAt(typeByteOffset, fmt.Sprintf("%q is not a valid RESP type byte", typeByte))
+	}
+}
+
+func (d *decoder) decodeInteger() (Value, error) {
+	lineOffset := d.offset
+
+	line, err := d.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+
+	n, err := strconv.ParseInt(line, 10, 64)
+	if err != nil || (len(line) > 0 && line[0] == '+') {
+		return Value{}, d.invalidAt(lineOffset, fmt.Sprintf("invalid integer %q", line))
+	}
+
+	return NewIntegerValue(n), nil
+}
+
+func (d *decoder) decodeBoolean() (Value, error) {
+	lineOffset := d.offset
+
+	line, err := d.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch line {
+	case "t":
+		return NewBooleanValue(true), nil
+	case "f":
+		return NewBooleanValue(false), nil
+	default:
+		return Value{}, d.invalidAt(lineOffset, fmt.Sprintf("expected boolean to be 't' or 'f', got %q", line))
+	}
+}
+
+func (d *decoder) decodeDouble() (Value, error) {
+	lineOffset := d.offset
+
+	line, err := d.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch line {
+	case "inf":
+		return NewDoubleValue(math.Inf(1)), nil
+	case "-inf":
+		return NewDoubleValue(math.Inf(-1)), nil
+	case "nan":
+		return NewDoubleValue(math.NaN()), nil
+	}
+
+	f, err := strconv.ParseFloat(line, 64)
+	if err != nil {
+		return Value{}, d.invalidAt(lineOffset, fmt.Sprintf("invalid double %q", line))
+	}
+
+	return NewDoubleValue(f), nil
+}
+
+func (d *decoder) decodeBigNumber() (Value, error) {
+	lineOffset := d.offset
+
+	line, err := d.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+
+	digits := line
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits = digits[1:]
+	}
+
+	if digits == "" {
+		return Value{}, d.invalidAt(lineOffset, fmt.Sprintf("invalid big number %q", line))
+	}
+
+	for i, c := range []byte(digits) {
+		if c < '0' || c > '9' {
+			return Value{}, d.invalidAt(lineOffset+len(line)-len(digits)+i, fmt.Sprintf("invalid big number %q", line))
+		}
+	}
+
+	return NewBigNumberValue(line), nil
+}
+
+// decodeBlob decodes length-prefixed values: bulk strings, bulk errors and verbatim strings
+func (d *decoder) decodeBlob(valueType ValueType) (Value, error) {
+	length, err := d.readLength(valueType, valueType == BulkString)
+	if err != nil {
+		return Value{}, err
+	}
+
+	if length == -1 {
+		return NewNilValue(), nil
+	}
+
+	dataOffset := d.offset
+	if length > len(d.data)-dataOffset-2 {
+		return Value{}, d.incomplete(fmt.Sprintf("expected %d bytes of %s data followed by \\r\\n", length, valueType))
+	}
+
+	if d.data[dataOffset+length] != '\r' || d.data[dataOffset+length+1] != '\n' {
+		return Value{}, d.invalidAt(dataOffset+length, fmt.Sprintf("expected \\r\\n after %d bytes of %s data", length, valueType))
+	}
+
+	contents := d.data[dataOffset : dataOffset+length]
+	d.offset = dataOffset + length + 2
+
+	switch valueType {
+	case BulkError:
+		return NewBulkErrorValue(string(contents)), nil
+	case VerbatimString:
+		if len(contents) < 4 || contents[3] != ':' {
+			return Value{}, d.invalidAt(dataOffset, "expected verbatim string to start with a 3-letter format followed by ':' (e.g. \"txt:\")")
+		}
+		return NewVerbatimStringValue(string(contents[:3]), string(contents[4:])), nil
+	default:
+		return NewBulkStringValue(string(contents)), nil
+	}
+}
+
+// decodeAggregate decodes arrays, maps, sets and pushes
+func (d *decoder) decodeAggregate(valueType ValueType) (Value, error) {
+	count, err := d.readLength(valueType, valueType == Array)
+	if err != nil {
+		return Value{}, err
+	}
+
+	if count == -1 {
+		return NewNilValue(), nil
+	}
+
+	elementCount := count
+	if valueType == Map {
+		elementCount = count * 2
+	}
+
+	// Not preallocated: count comes from the wire, and the elements may never arrive
+	var elements []Value
+	for i := 0; i < elementCount; i++ {
+		element, err := d.decodeValue()
+		if err != nil {
+			return Value{}, err
+		}
+		elements = append(elements, element)
+	}
+
+	switch valueType {
+	case Map:
+		return NewMapValue(elements), nil
+	case Set:
+		return NewSetValue(elements), nil
+	case Push:
+		return NewPushValue(elements), nil
+	default:
+		return NewArrayValue(elements), nil
+	}
+}
+
+// readLength reads a length line. If allowNull is true, -1 is accepted (RESP2 null bulk strings and arrays).
+func (d *decoder) readLength(valueType ValueType, allowNull bool) (int, error) {
+	lineOffset := d.offset
+
+	line, err := d.readLine()
+	if err != nil {
+		return 0, err
+	}
+
+	if line == "-1" && allowNull {
+		return -1, nil
+	}
+
+	length, err := strconv.Atoi(line)
+	if err != nil || length < 0 || line[0] == '+' {
+		return 0, d.invalidAt(lineOffset, fmt.Sprintf("invalid %s length %q", valueType, line))
+	}
+
+	if length > maxLength {
+		return 0, d.invalidAt(lineOffset, fmt.Sprintf("%s length %d exceeds the maximum of %d", valueType, length, maxLength))
+	}
+
+	return length, nil
+}
+
+// readExpectedLine reads a line and checks that it's equal to expected
+func (d *decoder) readExpectedLine(expected string) (string, error) {
+	lineOffset := d.offset
+
+	line, err := d.readLine()
+	if err != nil {
+		return "", err
+	}
+
+	if line != expected {
+		return "", d.invalidAt(lineOffset, fmt.Sprintf("expected %q, got %q", expected+"\r\n", line+"\r\n"))
+	}
+
+	return line, nil
+}
+
+// readLine reads until "\r\n" and returns the line without it
+func (d *decoder) readLine() (string, error) {
+	for i := d.offset; i < len(d.data); i++ {
+		switch d.data[i] {
+		case '\n':
+			return "", d.invalidAt(i, "expected \\r\\n, found \\n without \\r")
+		case '\r':
+			if i+1 >= len(d.data) {
+				return "", d.incomplete("expected \\n after \\r")
+			}
+
+			if d.data[i+1] != '\n' {
+				return "", d.invalidAt(i+1, "expected \\n after \\r")
+			}
+
+			line := string(d.data[d.offset:i])
+			d.offset = i + 2
+			return line, nil
+		}
+	}
+
+	return "", d.incomplete("expected \\r\\n at end of line")
+}
+
+func (d *decoder) incomplete(message string) *IncompleteInputError {
+	return &IncompleteInputError{Message: message}
+}
+
+func (d *decoder) invalidAt(offset int, message string) *InvalidInputError {
+	return &InvalidInputError{Data: d.data, Offset: offset, Message: message}
+}
@@ -0,0 +1,82 @@
+package resp
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Encode encodes v as RESP. RESP3-only types are encoded using RESP3 syntax.
+//
+// Nil is encoded as a RESP2 null bulk string ("$-1\r\n"), since that's understood by both RESP2 and RESP3 clients.
+func Encode(v Value) []byte {
+	buf := bytes.NewBuffer(nil)
+	encodeTo(buf, v)
+	return buf.Bytes()
+}
+
+// EncodeCommand encodes a command as an array of bulk strings. Example: EncodeCommand("SET", "foo", "bar")
+func EncodeCommand(args ...string) []byte {
+	return Encode(NewBulkStringArrayValue(args...))
+}
+
+func encodeTo(buf *bytes.Buffer, v Value) {
+	switch v.Type {
+	case SimpleString:
+		fmt.Fprintf(buf, "+%s\r\n", v.bytes)
+	case Error:
+		fmt.Fprintf(buf, "-%s\r\n", v.bytes)
+	case Integer:
+		fmt.Fprintf(buf, ":%d\r\n", v.integer)
+	case BulkString:
+		fmt.Fprintf(buf, "$%d\r\n%s\r\n", len(v.bytes), v.bytes)
+	case Nil:
+		buf.WriteString("$-1\r\n")
+	case Boolean:
+		if v.boolean {
+			buf.WriteString("#t\r\n")
+		} else {
+			buf.WriteString("#f\r\n")
+		}
+	case Double:
+		fmt.Fprintf(buf, ",%s\r\n", formatDouble(v.double))
+	case BigNumber:
+		fmt.Fprintf(buf, "(%s\r\n", v.bytes)
+	case BulkError:
+		fmt.Fprintf(buf, "!%d\r\n%s\r\n", len(v.bytes), v.bytes)
+	case VerbatimString:
+		fmt.Fprintf(buf, "=%d\r\n%s:%s\r\n", len(v.bytes)+4, v.format, v.bytes)
+	case Array, Set, Push:
+		fmt.Fprintf(buf, "%c%d\r\n", aggregatePrefixes[v.Type], len(v.elements))
+		for _, element := range v.elements {
+			encodeTo(buf, element)
+		}
+	case Map:
+		fmt.Fprintf(buf, "%%%d\r\n", len(v.elements)/2)
+		for _, element := range v.elements {
+			encodeTo(buf, element)
+		}
+	default:
+		panic(fmt.Sprintf("resp: can't encode value of type %q", v.Type))
+	}
+}
+
+var aggregatePrefixes = map[ValueType]byte{
+	Array: '*',
+	Set:   '~',
+	Push:  '>',
+}
+
+func formatDouble(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
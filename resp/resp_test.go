@@ -0,0 +1,165 @@
+package resp
+
+import (
+	"math"
+	"net"
+	"testing"
+
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/tcp_client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	values := []Value{
+		NewSimpleStringValue("OK"),
+		NewErrorValue("ERR unknown command"),
+		NewIntegerValue(-42),
+		NewBulkStringValue("hello\r\nworld"),
+		NewBulkStringValue(""),
+		NewNilValue(),
+		NewBulkStringArrayValue("SET", "foo", "bar"),
+		NewArrayValue([]Value{NewIntegerValue(1), NewArrayValue([]Value{NewSimpleStringValue("nested")})}),
+		NewBooleanValue(true),
+		NewBooleanValue(false),
+		NewDoubleValue(3.14),
+		NewDoubleValue(math.Inf(-1)),
+		NewBigNumberValue("-3492890328409238509324850943850943825024385"),
+		NewBulkErrorValue("SYNTAX invalid syntax"),
+		NewVerbatimStringValue("txt", "Some string"),
+		NewMapValue([]Value{NewSimpleStringValue("first"), NewIntegerValue(1)}),
+		NewSetValue([]Value{NewSimpleStringValue("a"), NewSimpleStringValue("b")}),
+		NewPushValue([]Value{NewBulkStringValue("message"), NewBulkStringValue("hi")}),
+	}
+
+	for _, value := range values {
+		encoded := Encode(value)
+
+		decoded, bytesRead, err := Decode(encoded)
+		require.NoError(t, err, "%q", encoded)
+		assert.Equal(t, len(encoded), bytesRead, "%q", encoded)
+		assert.True(t, value.Equal(decoded), "expected %s, got %s", value.FormattedString(), decoded.FormattedString())
+	}
+}
+
+func TestEncodeCommand(t *testing.T) {
+	assert.Equal(t, "*2\r\n$4\r\nECHO\r\n$3\r\nhey\r\n", string(EncodeCommand("ECHO", "hey")))
+}
+
+func TestDecodeReturnsBytesRead(t *testing.T) {
+	value, bytesRead, err := Decode([]byte("+OK\r\n+NEXT\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, bytesRead)
+	assert.NoError(t, ExpectSimpleString(value, "OK"))
+}
+
+func TestDecodeNulls(t *testing.T) {
+	for _, input := range []string{"$-1\r\n", "*-1\r\n", "_\r\n"} {
+		value, _, err := Decode([]byte(input))
+		require.NoError(t, err, input)
+		assert.NoError(t, ExpectNil(value), input)
+	}
+}
+
+func TestDecodeIncomplete(t *testing.T) {
+	for _, input := range []string{"", "+OK", "+OK\r", "$5\r\nhel", "*2\r\n$3\r\nfoo\r\n", "%1\r\n+a\r\n"} {
+		_, _, err := Decode([]byte(input))
+
+		var incompleteInputError *IncompleteInputError
+		assert.ErrorAs(t, err, &incompleteInputError, "%q", input)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedOffset  int
+		expectedMessage string
+	}{
+		{"+OK\n", 3, `expected \r\n, found \n without \r`},
+		{"+OK\rX", 4, `expected \n after \r`},
+		{"?", 0, `'?' is not a valid RESP type byte`},
+		{":12a\r\n", 1, `invalid integer "12a"`},
+		{"$3\r\nhello\r\n", 7, `expected \r\n after 3 bytes of bulk string data`},
+		{"$-2\r\n", 1, `invalid bulk string length "-2"`},
+		{"*x\r\n", 1, `invalid array length "x"`},
+		{"*2\r\n+OK\r\nOK\r\n", 9, `'O' is not a valid RESP type byte`},
+		{"#x\r\n", 1, `expected boolean to be 't' or 'f', got "x"`},
+		{"=5\r\nhello\r\n", 4, `expected verbatim string to start with a 3-letter format followed by ':' (e.g. "txt:")`},
+		{"!-1\r\n", 1, `invalid bulk error length "-1"`},
+		{"$9223372036854775807\r\nabc", 1, `bulk string length 9223372036854775807 exceeds the maximum of 536870912`},
+		{"%4611686018427387904\r\n", 1, `map length 4611686018427387904 exceeds the maximum of 536870912`},
+		{"*999999999999\r\n", 1, `array length 999999999999 exceeds the maximum of 536870912`},
+	}
+
+	for _, tt := range tests {
+		_, _, err := Decode([]byte(tt.input))
+
+		var invalidInputError *InvalidInputError
+		if assert.ErrorAs(t, err, &invalidInputError, "%q", tt.input) {
+			assert.Equal(t, tt.expectedOffset, invalidInputError.Offset, "%q", tt.input)
+			assert.Equal(t, tt.expectedMessage, invalidInputError.Message, "%q", tt.input)
+		}
+	}
+}
+
+func TestInvalidInputErrorHighlightsOffset(t *testing.T) {
+	_, _, err := Decode([]byte("+OK\n"))
+	assert.Equal(t, "Received: \"+OK\\n\"\n              ^ error\nError: expected \\r\\n, found \\n without \\r", err.Error())
+}
+
+func TestAssertions(t *testing.T) {
+	assert.NoError(t, ExpectSimpleString(NewSimpleStringValue("PONG"), "PONG"))
+	assert.EqualError(t, ExpectSimpleString(NewBulkStringValue("PONG"), "PONG"), `expected simple string, got bulk string "PONG"`)
+	assert.EqualError(t, ExpectSimpleString(NewSimpleStringValue("PING"), "PONG"), `expected simple string "PONG", got "PING"`)
+
+	assert.NoError(t, ExpectBulkString(NewBulkStringValue("bar"), "bar"))
+	assert.EqualError(t, ExpectBulkString(NewNilValue(), "bar"), `expected bulk string, got nil nil`)
+
+	assert.NoError(t, ExpectError(NewErrorValue("ERR syntax"), "ERR syntax"))
+	assert.NoError(t, ExpectError(NewBulkErrorValue("ERR syntax"), "ERR syntax"))
+	assert.Error(t, ExpectError(NewSimpleStringValue("ERR syntax"), "ERR syntax"))
+
+	assert.NoError(t, ExpectInteger(NewIntegerValue(3), 3))
+	assert.EqualError(t, ExpectInteger(NewIntegerValue(4), 3), `expected integer 3, got 4`)
+
+	assert.NoError(t, ExpectBulkStringArray(NewBulkStringArrayValue("a", "b"), []string{"a", "b"}))
+	assert.EqualError(t, ExpectBulkStringArray(NewBulkStringArrayValue("a", "c"), []string{"a", "b"}), `expected element #2 of array to be bulk string "b", got bulk string "c"`)
+	assert.EqualError(t, ExpectBulkStringArray(NewBulkStringArrayValue("a"), []string{"a", "b"}), `expected array of length 2, got 1: ["a"]`)
+}
+
+func TestSendCommandAndReadValue(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		buf := make([]byte, 1024)
+		conn.Read(buf)
+
+		// Write the response in two parts to test that ReadValue waits for a complete value
+		conn.Write([]byte("*2\r\n$3\r\nfoo"))
+		conn.Write([]byte("\r\n:1\r\n+EXTRA\r\n"))
+	}()
+
+	client, err := tcp_client.Connect(listener.Addr().String(), logger.GetLogger(false, ""))
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, SendCommand(client, "GET", "foo"))
+
+	value, err := ReadValue(client)
+	require.NoError(t, err)
+	assert.NoError(t, ExpectArray(value, []Value{NewBulkStringValue("foo"), NewIntegerValue(1)}))
+
+	value, err = ReadValue(client)
+	require.NoError(t, err)
+	assert.NoError(t, ExpectSimpleString(value, "EXTRA"))
+}
@@ -0,0 +1,212 @@
+package resp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValueType is the type of a RESP value
+type ValueType string
+
+const (
+	// RESP2 types
+	SimpleString ValueType = "simple string"
+	Error        ValueType = "error"
+	Integer      ValueType = "integer"
+	BulkString   ValueType = "bulk string"
+	Array        ValueType = "array"
+
+	// Nil represents null bulk strings and null arrays (RESP2), and the null type (RESP3)
+	Nil ValueType = "nil"
+
+	// RESP3 types
+	Boolean        ValueType = "boolean"
+	Double         ValueType = "double"
+	BigNumber      ValueType = "big number"
+	BulkError      ValueType = "bulk error"
+	VerbatimString ValueType = "verbatim string"
+	Map            ValueType = "map"
+	Set            ValueType = "set"
+	Push           ValueType = "push"
+)
+
+// Value is a decoded RESP value
+type Value struct {
+	Type ValueType
+
+	bytes    []byte  // SimpleString, Error, BulkString, BigNumber, BulkError, VerbatimString
+	integer  int64   // Integer
+	boolean  bool    // Boolean
+	double   float64 // Double
+	elements []Value // Array, Set, Push. For Map, keys and values alternate.
+
+	// format is the 3-letter format of a VerbatimString. Example: "txt"
+	format string
+}
+
+func NewSimpleStringValue(s string) Value {
+	return Value{Type: SimpleString, bytes: []byte(s)}
+}
+
+func NewErrorValue(s string) Value {
+	return Value{Type: Error, bytes: []byte(s)}
+}
+
+func NewIntegerValue(n int64) Value {
+	return Value{Type: Integer, integer: n}
+}
+
+func NewBulkStringValue(s string) Value {
+	return Value{Type: BulkString, bytes: []byte(s)}
+}
+
+func NewArrayValue(elements []Value) Value {
+	return Value{Type: Array, elements: elements}
+}
+
+func NewNilValue() Value {
+	return Value{Type: Nil}
+}
+
+func NewBooleanValue(b bool) Value {
+	return Value{Type: Boolean, boolean: b}
+}
+
+func NewDoubleValue(f float64) Value {
+	return Value{Type: Double, double: f}
+}
+
+func NewBigNumberValue(digits string) Value {
+	return Value{Type: BigNumber, bytes: []byte(digits)}
+}
+
+func NewBulkErrorValue(s string) Value {
+	return Value{Type: BulkError, bytes: []byte(s)}
+}
+
+func NewVerbatimStringValue(format string, s string) Value {
+	return Value{Type: VerbatimString, format: format, bytes: []byte(s)}
+}
+
+// NewMapValue returns a map value. keysAndValues must alternate between keys and values.
+func NewMapValue(keysAndValues []Value) Value {
+	return Value{Type: Map, elements: keysAndValues}
+}
+
+func NewSetValue(elements []Value) Value {
+	return Value{Type: Set, elements: elements}
+}
+
+func NewPushValue(elements []Value) Value {
+	return Value{Type: Push, elements: elements}
+}
+
+// NewBulkStringArrayValue returns an array of bulk strings, which is how Redis commands are sent
+func NewBulkStringArrayValue(strings ...string) Value {
+	elements := make([]Value, len(strings))
+	for i, s := range strings {
+		elements[i] = NewBulkStringValue(s)
+	}
+	return NewArrayValue(elements)
+}
+
+// Bytes returns the contents of string-like values
+func (v Value) Bytes() []byte {
+	return v.bytes
+}
+
+// String returns the contents of string-like values
+func (v Value) String() string {
+	return string(v.bytes)
+}
+
+func (v Value) Integer() int64 {
+	return v.integer
+}
+
+func (v Value) Boolean() bool {
+	return v.boolean
+}
+
+func (v Value) Double() float64 {
+	return v.double
+}
+
+// Elements returns the elements of arrays, sets and pushes. For maps, keys and values alternate.
+func (v Value) Elements() []Value {
+	return v.elements
+}
+
+// Format returns the format of a verbatim string. Example: "txt"
+func (v Value) Format() string {
+	return v.format
+}
+
+// FormattedString returns a human-readable representation of the value, for use in logs and error messages.
+//
+// Example: `["SET", "foo", 1]`
+func (v Value) FormattedString() string {
+	switch v.Type {
+	case SimpleString, BulkString, VerbatimString:
+		return strconv.Quote(v.String())
+	case Error, BulkError:
+		return fmt.Sprintf("ERR %q", v.String())
+	case Integer:
+		return strconv.FormatInt(v.integer, 10)
+	case Nil:
+		return "nil"
+	case Boolean:
+		return strconv.FormatBool(v.boolean)
+	case Double:
+		return strconv.FormatFloat(v.double, 'g', -1, 64)
+	case BigNumber:
+		return v.String()
+	case Map:
+		pairs := []string{}
+		for i := 0; i+1 < len(v.elements); i += 2 {
+			pairs = append(pairs, v.elements[i].FormattedString()+": "+v.elements[i+1].FormattedString())
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case Array, Set, Push:
+		formattedElements := make([]string, len(v.elements))
+		for i, element := range v.elements {
+			formattedElements[i] = element.FormattedString()
+		}
+		return "[" + strings.Join(formattedElements, ", ") + "]"
+	default:
+		return fmt.Sprintf("<%s>", v.Type)
+	}
+}
+
+// Equal returns true if both values have the same type and contents
+func (v Value) Equal(other Value) bool {
+	if v.Type != other.Type {
+		return false
+	}
+
+	switch v.Type {
+	case Integer:
+		return v.integer == other.integer
+	case Boolean:
+		return v.boolean == other.boolean
+	case Double:
+		return v.double == other.double
+	case Nil:
+		return true
+	case VerbatimString:
+		return v.format == other.format && string(v.bytes) == string(other.bytes)
+	case Array, Set, Push, Map:
+		if len(v.elements) != len(other.elements) {
+			return false
+		}
+		for i := range v.elements {
+			if !v.elements[i].Equal(other.elements[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return string(v.bytes) == string(other.bytes)
+	}
+}
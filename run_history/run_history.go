@@ -0,0 +1,248 @@
+// Package run_history persists the results of local tester runs in the submission directory, so that later runs can
+// re-run only failing stages or resume from the first failure.
+package run_history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dir is the directory (relative to the submission directory) where the history is stored
+const Dir = ".bootllm"
+
+// FileName is the name of the history file inside Dir
+const FileName = "last-run.json"
+
+// Status is the outcome of a stage
+type Status string
+
+const (
+	StatusPassed Status = "passed"
+	StatusFailed Status = "failed"
+	StatusNotRun Status = "not_run" // Selected, but skipped because the build or an earlier stage failed
+)
+
+// StageResult is the last recorded result of a stage
+type StageResult struct {
+	Slug       string `json:"slug"`
+	Status     Status `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+
+	// ConsecutiveFailures counts the runs in a row in which this stage failed. Reset when the stage passes.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+
+	// SubmissionHash is the hash of the submission files when this result was recorded
+	SubmissionHash string `json:"submission_hash"`
+}
+
+// History holds the latest result of every stage that has been run in a submission directory
+type History struct {
+	FinishedAt time.Time     `json:"finished_at"`
+	Stages     []StageResult `json:"stages"`
+
+	// GeneratedFiles lists the submission files created or modified by earlier runs (build outputs, files written by
+	// tests, ...). They're excluded from SubmissionHash, so that a run doesn't invalidate its own results.
+	GeneratedFiles []string `json:"generated_files,omitempty"`
+}
+
+// Path returns the path of the history file for submissionDir
+func Path(submissionDir string) string {
+	return filepath.Join(submissionDir, Dir, FileName)
+}
+
+// Load reads the history of submissionDir. Returns an empty history if there's none yet.
+func Load(submissionDir string) (History, error) {
+	contents, err := os.ReadFile(Path(submissionDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return History{}, nil
+	}
+	if err != nil {
+		return History{}, err
+	}
+
+	var history History
+	if err := json.Unmarshal(contents, &history); err != nil {
+		return History{}, err
+	}
+
+	return history, nil
+}
+
+// Save writes the history to submissionDir, replacing the previous one
+func (h History) Save(submissionDir string) error {
+	if err := os.MkdirAll(filepath.Join(submissionDir, Dir), 0755); err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that an interrupted run doesn't leave a truncated file behind
+	tempFile, err := os.CreateTemp(filepath.Join(submissionDir, Dir), FileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(append(contents, '\n')); err != nil {
+		tempFile.Close()
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), Path(submissionDir))
+}
+
+// Stage returns the last recorded result of the stage with slug
+func (h History) Stage(slug string) (StageResult, bool) {
+	for _, result := range h.Stages {
+		if result.Slug == slug {
+			return result, true
+		}
+	}
+
+	return StageResult{}, false
+}
+
+// PassedWith returns true if the stage with slug passed the last time it ran, and the submission hasn't changed since
+func (h History) PassedWith(slug string, submissionHash string) bool {
+	result, ok := h.Stage(slug)
+
+	return ok && result.Status == StatusPassed && result.SubmissionHash == submissionHash
+}
+
+// Record replaces the result of result.Slug, and updates its ConsecutiveFailures
+func (h *History) Record(result StageResult) {
+	previous, _ := h.Stage(result.Slug)
+
+	switch result.Status {
+	case StatusPassed:
+		result.ConsecutiveFailures = 0
+	case StatusFailed:
+		result.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+	default:
+		result.ConsecutiveFailures = previous.ConsecutiveFailures
+	}
+
+	for i := range h.Stages {
+		if h.Stages[i].Slug == result.Slug {
+			h.Stages[i] = result
+			return
+		}
+	}
+
+	h.Stages = append(h.Stages, result)
+}
+
+// Snapshot maps the path (relative to the submission directory, with forward slashes) of each submission file to
+// the hash of its contents
+type Snapshot map[string]string
+
+// TakeSnapshot hashes all files in submissionDir. Hidden files and directories (like .git and the history itself)
+// are skipped.
+func TakeSnapshot(submissionDir string) (Snapshot, error) {
+	snapshot := Snapshot{}
+
+	err := filepath.WalkDir(submissionDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != submissionDir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(submissionDir, path)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		fileHash := sha256.New()
+		if _, err := io.Copy(fileHash, file); err != nil {
+			return err
+		}
+
+		snapshot[filepath.ToSlash(relativePath)] = hex.EncodeToString(fileHash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// Hash returns a hash of the paths and contents of the files in the snapshot, except for the excluded paths
+func (s Snapshot) Hash(excluded []string) string {
+	paths := make([]string, 0, len(s))
+	for path := range s {
+		if !slices.Contains(excluded, path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	hash := sha256.New()
+	for _, path := range paths {
+		hash.Write([]byte(path + "\x00" + s[path] + "\n"))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// GeneratedFiles returns the files that a run created or modified (build outputs, files written by tests, ...),
+// given the snapshots taken before and after it. Files generated by earlier runs (previous) stay in the list as long
+// as they exist, since rebuilding an identical binary doesn't modify it.
+func GeneratedFiles(before Snapshot, after Snapshot, previous []string) []string {
+	generated := []string{}
+
+	for path, contentHash := range after {
+		if before[path] != contentHash || slices.Contains(previous, path) {
+			generated = append(generated, path)
+		}
+	}
+	sort.Strings(generated)
+
+	return generated
+}
+
+// HashSubmission returns a hash of the paths and contents of all files in submissionDir, except for the excluded
+// paths. Hidden files and directories (like .git and the history itself) are skipped.
+func HashSubmission(submissionDir string, excluded ...string) (string, error) {
+	snapshot, err := TakeSnapshot(submissionDir)
+	if err != nil {
+		return "", err
+	}
+
+	return snapshot.Hash(excluded), nil
+}
@@ -0,0 +1,120 @@
+package run_history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMissingHistory(t *testing.T) {
+	history, err := Load(t.TempDir())
+
+	assert.NoError(t, err)
+	assert.Empty(t, history.Stages)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	history := History{FinishedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	history.Record(StageResult{Slug: "hello", Status: StatusPassed, DurationMs: 120, SubmissionHash: "abc"})
+	history.Record(StageResult{Slug: "mario", Status: StatusFailed, Error: "expected 1 line", SubmissionHash: "abc"})
+	require.NoError(t, history.Save(dir))
+
+	loaded, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, history, loaded)
+
+	entries, err := os.ReadDir(filepath.Join(dir, Dir))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files should be removed")
+}
+
+func TestLoadInvalidHistory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, Dir), 0755))
+	require.NoError(t, os.WriteFile(Path(dir), []byte("{"), 0644))
+
+	_, err := Load(dir)
+	assert.Error(t, err)
+}
+
+func TestRecordCountsConsecutiveFailures(t *testing.T) {
+	history := History{}
+
+	history.Record(StageResult{Slug: "hello", Status: StatusFailed})
+	history.Record(StageResult{Slug: "hello", Status: StatusFailed})
+	history.Record(StageResult{Slug: "hello", Status: StatusNotRun})
+
+	result, ok := history.Stage("hello")
+	require.True(t, ok)
+	assert.Equal(t, StatusNotRun, result.Status)
+	assert.Equal(t, 2, result.ConsecutiveFailures)
+
+	history.Record(StageResult{Slug: "hello", Status: StatusFailed})
+	result, _ = history.Stage("hello")
+	assert.Equal(t, 3, result.ConsecutiveFailures)
+
+	history.Record(StageResult{Slug: "hello", Status: StatusPassed})
+	result, _ = history.Stage("hello")
+	assert.Equal(t, 0, result.ConsecutiveFailures)
+
+	assert.Len(t, history.Stages, 1)
+}
+
+func TestPassedWith(t *testing.T) {
+	history := History{}
+	history.Record(StageResult{Slug: "hello", Status: StatusPassed, SubmissionHash: "abc"})
+	history.Record(StageResult{Slug: "mario", Status: StatusFailed, SubmissionHash: "abc"})
+
+	assert.True(t, history.PassedWith("hello", "abc"))
+	assert.False(t, history.PassedWith("hello", "def"))
+	assert.False(t, history.PassedWith("mario", "abc"))
+	assert.False(t, history.PassedWith("cash", "abc"))
+}
+
+func TestHashSubmission(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.c"), []byte("int main() {}"), 0644))
+
+	hash, err := HashSubmission(dir)
+	require.NoError(t, err)
+
+	// Hidden files (including the history itself) don't change the hash
+	require.NoError(t, History{}.Save(dir))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".main.c.swp"), []byte("x"), 0644))
+
+	unchangedHash, err := HashSubmission(dir)
+	require.NoError(t, err)
+	assert.Equal(t, hash, unchangedHash)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.c"), []byte("int main() { return 1; }"), 0644))
+
+	changedHash, err := HashSubmission(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changedHash)
+
+	// Renaming a file changes the hash, even if contents are the same
+	require.NoError(t, os.Rename(filepath.Join(dir, "main.c"), filepath.Join(dir, "hello.c")))
+
+	renamedHash, err := HashSubmission(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, changedHash, renamedHash)
+}
+
+func TestGeneratedFiles(t *testing.T) {
+	before := Snapshot{"main.c": "1", "main": "2"}
+	after := Snapshot{"main.c": "1", "main": "3", "output.txt": "4", "old.log": "5"}
+
+	generated := GeneratedFiles(before, after, []string{"old.log", "deleted.log"})
+	assert.Equal(t, []string{"main", "old.log", "output.txt"}, generated)
+
+	// Generated files don't change the hash, so a run doesn't invalidate its own results
+	assert.Equal(t, Snapshot{"main.c": "1"}.Hash(nil), before.Hash(generated))
+	assert.Equal(t, before.Hash(generated), after.Hash(generated))
+	assert.NotEqual(t, before.Hash(nil), before.Hash(generated))
+}
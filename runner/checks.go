@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/i18n"
+)
+
+// checkStdoutContains 检查输出是否包含期望内容（expected 为空时总是通过）
+func checkStdoutContains(stdout []byte, expected string, hidden bool) error {
+	actual := normalizeOutput(string(stdout))
+
+	if expected != "" && !strings.Contains(actual, expected) {
+		return newMismatch(expected, actual, hidden, i18n.T("expected output to contain %q", expected))
+	}
+
+	return nil
+}
+
+// checkStdoutRegex 检查输出是否匹配正则表达式
+func checkStdoutRegex(stdout []byte, pattern string, hidden bool) error {
+	actual := normalizeOutput(string(stdout))
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return i18n.Errorf("invalid regex pattern: %v", err)
+	}
+
+	if !re.MatchString(actual) {
+		return newMismatch(pattern, actual, hidden, i18n.T("expected output to match pattern %q", pattern))
+	}
+
+	return nil
+}
+
+// checkStdoutExact 检查输出是否完全匹配（忽略首尾空白）
+// 因为首尾空白被忽略，失败信息中的空白说明只涉及输出中间的差异，末尾的差异见 checkStdoutExactStrict
+func checkStdoutExact(stdout []byte, expected string, hidden bool) error {
+	actual := strings.TrimSpace(normalizeOutput(string(stdout)))
+	expected = strings.TrimSpace(expected)
+
+	if actual != expected {
+		mismatch := newMismatch(expected, actual, hidden, i18n.T("output mismatch"))
+		mismatch.ShowDiff = true
+		return mismatch
+	}
+
+	return nil
+}
+
+// checkStdoutExactStrict 检查输出是否逐字节完全匹配：不忽略首尾空白，也不把 \r\n 转换为 \n
+// 只有空白字符不同时，失败信息会说明差异（如 "expected trailing newline"）并显示空白字符
+func checkStdoutExactStrict(stdout []byte, expected string, hidden bool) error {
+	actual := string(stdout)
+
+	if actual != expected {
+		mismatch := newMismatch(expected, actual, hidden, i18n.T("output mismatch"))
+		mismatch.ShowDiff = true
+		return mismatch
+	}
+
+	return nil
+}
+
+// newMismatch 创建 Mismatch，hidden 为 true 时错误信息不包含期望值
+func newMismatch(expected string, actual string, hidden bool, message string) *Mismatch {
+	if hidden {
+		message = i18n.T("output did not match hidden expected value")
+	}
+
+	return &Mismatch{
+		Expected: expected,
+		Actual:   actual,
+		Message:  message,
+		Hidden:   hidden,
+	}
+}
+
+// describeExpected 返回事件中描述期望值的文字，隐藏的期望值不会出现在事件中
+func describeExpected(expected string, hidden bool) string {
+	if hidden {
+		return "hidden expected value"
+	}
+
+	return strconv.Quote(expected)
+}
+
+// checkExitCode 检查退出码
+func checkExitCode(result executable.ExecutableResult, code int) error {
+	if result.ExitCode != code {
+		return &ExitCodeMismatch{
+			Expected: code,
+			Actual:   result.ExitCode,
+			Stdout:   normalizeOutput(string(result.Stdout)),
+			Stderr:   normalizeOutput(string(result.Stderr)),
+		}
+	}
+
+	return nil
+}
+
+// normalizeOutput 标准化输出（移除 PTY 的 \r\n 转换为 \n）
+func normalizeOutput(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
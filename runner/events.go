@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bootllm/tester-utils/events"
+	"github.com/bootllm/tester-utils/executable"
+)
+
+// emitProgramOutput 在流式模式下（见 events.Enable）发送程序的标准输出和标准错误输出
+func emitProgramOutput(command string, result executable.ExecutableResult) {
+	if !events.Enabled() {
+		return
+	}
+
+	for _, output := range []struct {
+		stream string
+		data   []byte
+	}{{"stdout", result.Stdout}, {"stderr", result.Stderr}} {
+		if len(output.data) > 0 {
+			events.Emit(events.Event{
+				Type:    events.TypeProgramOutput,
+				Command: command,
+				Stream:  output.stream,
+				Output:  normalizeOutput(string(output.data)),
+			})
+		}
+	}
+}
+
+// emitCheckPassed 在检查通过（err 为 nil）时发送 check_passed 事件，check 描述检查内容，例如 "exit code 0"
+func emitCheckPassed(err error, check string, args ...any) {
+	if err != nil || !events.Enabled() {
+		return
+	}
+
+	events.Emit(events.Event{Type: events.TypeCheckPassed, Check: fmt.Sprintf(check, args...)})
+}
+
+// commandLine 返回运行的命令及参数，例如 "./mario 4"
+func (r *Runner) commandLine() string {
+	return strings.Join(append([]string{r.command}, r.args...), " ")
+}
@@ -0,0 +1,423 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/i18n"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// Command 描述管道中的一个命令
+type Command struct {
+	Name string
+	Args []string
+}
+
+// Cmd 创建一个管道命令
+func Cmd(name string, args ...string) Command {
+	return Command{Name: name, Args: args}
+}
+
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// PipelineRunner 将多个命令的 stdout 依次连接到下一个命令的 stdin（不经过 shell）
+//
+// 用法示例:
+//
+//	// 等价于 cat input.txt | ./wc -l
+//	runner.Pipeline(dir, runner.Cmd("cat", "input.txt"), runner.Cmd("./wc", "-l")).
+//	    Execute().
+//	    StdoutExact("3").
+//	    ExitCodes(0, 0)
+type PipelineRunner struct {
+	workDir  string
+	commands []Command
+	env      []string
+	timeout  time.Duration
+	logger   *logger.Logger
+	results  []executable.ExecutableResult
+	err      error
+	hideNext bool // 下一个输出检查的期望值是隐藏的，见 Hidden
+}
+
+// Pipeline 创建一个新的 PipelineRunner 实例
+func Pipeline(workDir string, commands ...Command) *PipelineRunner {
+	p := &PipelineRunner{
+		workDir:  workDir,
+		commands: commands,
+		timeout:  10 * time.Second,
+	}
+
+	if len(commands) == 0 {
+		p.err = i18n.Errorf("pipeline requires at least one command")
+	}
+
+	return p
+}
+
+// WithLogger 设置 logger
+func (p *PipelineRunner) WithLogger(l *logger.Logger) *PipelineRunner {
+	p.logger = l
+	return p
+}
+
+// WithTimeout 设置整个管道的超时时间
+func (p *PipelineRunner) WithTimeout(t time.Duration) *PipelineRunner {
+	p.timeout = t
+	return p
+}
+
+// WithEnv 设置环境变量（对管道中所有命令生效）
+func (p *PipelineRunner) WithEnv(env ...string) *PipelineRunner {
+	p.env = append(p.env, env...)
+	return p
+}
+
+// Stdin 将输入发送给第一个命令并运行管道（阻塞式）
+func (p *PipelineRunner) Stdin(input string) *PipelineRunner {
+	if p.err != nil {
+		return p
+	}
+
+	if p.logger != nil {
+		p.logger.Debugf("sending input %q...", input)
+	}
+
+	p.run([]byte(input + "\n"))
+	return p
+}
+
+// Execute 不带输入运行管道
+func (p *PipelineRunner) Execute() *PipelineRunner {
+	if p.err != nil {
+		return p
+	}
+
+	p.run(nil)
+	return p
+}
+
+// run 启动所有命令，连接管道并等待全部结束
+func (p *PipelineRunner) run(stdin []byte) {
+	if p.logger != nil {
+		p.logger.Debugf("running pipeline %s...", p.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	cmds := make([]*exec.Cmd, len(p.commands))
+	stderrBuffers := make([]*bytes.Buffer, len(p.commands))
+	stdoutBuffer := bytes.NewBuffer(nil)
+
+	for i, command := range p.commands {
+		path, err := resolvePipelineCommand(p.workDir, command.Name)
+		if err != nil {
+			p.err = err
+			return
+		}
+
+		cmd := exec.CommandContext(ctx, path, command.Args...)
+		cmd.Dir = p.workDir
+		cmd.Env = append(executable.GetSafeEnvironmentVariables(), p.env...)
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		cmd.Cancel = func() error {
+			// Kill the whole process group, not just the direct child
+			return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		}
+
+		stderrBuffers[i] = bytes.NewBuffer(nil)
+		cmd.Stderr = stderrBuffers[i]
+		cmds[i] = cmd
+	}
+
+	if stdin != nil {
+		cmds[0].Stdin = bytes.NewReader(stdin)
+	}
+	cmds[len(cmds)-1].Stdout = stdoutBuffer
+
+	// Connect stdout of each command to stdin of the next one
+	pipeFiles := []*os.File{}
+	for i := 0; i < len(cmds)-1; i++ {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			closeFiles(pipeFiles)
+			p.err = err
+			return
+		}
+
+		cmds[i].Stdout = writer
+		cmds[i+1].Stdin = reader
+		pipeFiles = append(pipeFiles, reader, writer)
+	}
+
+	started := []*exec.Cmd{}
+	for _, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			closeFiles(pipeFiles)
+			for _, startedCmd := range started {
+				syscall.Kill(-startedCmd.Process.Pid, syscall.SIGKILL)
+				startedCmd.Wait()
+			}
+			p.err = err
+			return
+		}
+		started = append(started, cmd)
+
+		// Untracked once run returns, after all commands have been waited for
+		defer executable.TrackProcessGroup(cmd.Process.Pid)()
+	}
+
+	// The children own their copies of the pipe ends now. Closing ours is required
+	// for readers to observe EOF once the writing process exits.
+	closeFiles(pipeFiles)
+
+	p.results = make([]executable.ExecutableResult, len(cmds))
+	for i, cmd := range cmds {
+		err := cmd.Wait()
+
+		var exitError *exec.ExitError
+		if err != nil && !errors.As(err, &exitError) {
+			p.err = err
+		}
+
+		p.results[i] = executable.ExecutableResult{
+			Stderr:   stderrBuffers[i].Bytes(),
+			ExitCode: exitCodeFromProcessState(cmd.ProcessState),
+		}
+	}
+	p.results[len(cmds)-1].Stdout = stdoutBuffer.Bytes()
+
+	for i, result := range p.results {
+		emitProgramOutput(p.commands[i].String(), result)
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		p.err = executable.ErrTimeout
+	}
+}
+
+// resolvePipelineCommand 解析命令的绝对路径并检查可执行权限
+func resolvePipelineCommand(workDir string, command string) (string, error) {
+	path := resolveCommandPath(workDir, command)
+
+	absolutePath, err := exec.LookPath(path)
+	if err != nil && !errors.Is(err, exec.ErrDot) {
+		if _, statErr := os.Stat(path); statErr != nil {
+			return "", i18n.Errorf("%s not found", filepath.Base(path))
+		}
+
+		return "", i18n.Errorf("%s is not an executable file", path)
+	}
+
+	return filepath.Abs(absolutePath)
+}
+
+// exitCodeFromProcessState 返回退出码，被信号终止时返回 128 + 信号值
+func exitCodeFromProcessState(state *os.ProcessState) int {
+	if state == nil {
+		return -1
+	}
+
+	exitCode := state.ExitCode()
+	if exitCode == -1 {
+		if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			exitCode = 128 + int(status.Signal())
+		}
+	}
+
+	return exitCode
+}
+
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+// Stdout 检查最后一个命令的标准输出是否包含期望内容
+func (p *PipelineRunner) Stdout(expected string) *PipelineRunner {
+	if p.err != nil {
+		return p
+	}
+	if p.results == nil {
+		p.err = i18n.Errorf("pipeline not yet executed")
+		return p
+	}
+
+	hidden := p.takeHidden()
+	p.err = checkStdoutContains(p.Result().Stdout, expected, hidden)
+	emitCheckPassed(p.err, "output contains %s", describeExpected(expected, hidden))
+	return p
+}
+
+// StdoutRegex 使用正则表达式检查最后一个命令的标准输出
+func (p *PipelineRunner) StdoutRegex(pattern string) *PipelineRunner {
+	if p.err != nil {
+		return p
+	}
+	if p.results == nil {
+		p.err = i18n.Errorf("pipeline not yet executed")
+		return p
+	}
+
+	hidden := p.takeHidden()
+	p.err = checkStdoutRegex(p.Result().Stdout, pattern, hidden)
+	emitCheckPassed(p.err, "output matches %s", describeExpected(pattern, hidden))
+	return p
+}
+
+// StdoutExact 检查最后一个命令的标准输出是否完全匹配
+func (p *PipelineRunner) StdoutExact(expected string) *PipelineRunner {
+	if p.err != nil {
+		return p
+	}
+	if p.results == nil {
+		p.err = i18n.Errorf("pipeline not yet executed")
+		return p
+	}
+
+	hidden := p.takeHidden()
+	p.err = checkStdoutExact(p.Result().Stdout, expected, hidden)
+	emitCheckPassed(p.err, "output is %s", describeExpected(expected, hidden))
+	return p
+}
+
+// StdoutExactStrict 检查最后一个命令的标准输出是否逐字节完全匹配，见 Runner.StdoutExactStrict
+func (p *PipelineRunner) StdoutExactStrict(expected string) *PipelineRunner {
+	if p.err != nil {
+		return p
+	}
+	if p.results == nil {
+		p.err = i18n.Errorf("pipeline not yet executed")
+		return p
+	}
+
+	hidden := p.takeHidden()
+	p.err = checkStdoutExactStrict(p.Result().Stdout, expected, hidden)
+	emitCheckPassed(p.err, "output is %s", describeExpected(expected, hidden))
+	return p
+}
+
+// Hidden 将下一个输出检查的期望值标记为隐藏，见 Runner.Hidden
+func (p *PipelineRunner) Hidden() *PipelineRunner {
+	p.hideNext = true
+	return p
+}
+
+// takeHidden 返回下一个输出检查是否隐藏期望值，并清除该标记
+func (p *PipelineRunner) takeHidden() bool {
+	hidden := p.hideNext
+	p.hideNext = false
+	return hidden
+}
+
+// Exit 检查最后一个命令的退出码（与 shell 中 $? 的语义一致）
+func (p *PipelineRunner) Exit(code int) *PipelineRunner {
+	return p.ExitAt(len(p.commands)-1, code)
+}
+
+// ExitAt 检查第 index 个命令（从 0 开始）的退出码
+func (p *PipelineRunner) ExitAt(index int, code int) *PipelineRunner {
+	if p.err != nil {
+		return p
+	}
+	if p.results == nil {
+		p.err = i18n.Errorf("pipeline not yet executed")
+		return p
+	}
+	if index < 0 || index >= len(p.results) {
+		p.err = i18n.Errorf("pipeline has no command at index %d", index)
+		return p
+	}
+
+	if err := checkExitCode(p.results[index], code); err != nil {
+		p.err = &PipelineExitCodeMismatch{
+			Index:            index,
+			Command:          p.commands[index].String(),
+			ExitCodeMismatch: err.(*ExitCodeMismatch),
+		}
+	}
+
+	emitCheckPassed(p.err, "exit code %d (%s)", code, p.commands[index].String())
+	return p
+}
+
+// ExitCodes 依次检查每个命令的退出码（与 bash 中 ${PIPESTATUS[@]} 的语义一致）
+func (p *PipelineRunner) ExitCodes(codes ...int) *PipelineRunner {
+	if p.err != nil {
+		return p
+	}
+	if len(codes) != len(p.commands) {
+		p.err = i18n.Errorf("expected %d exit codes for pipeline, got %d", len(p.commands), len(codes))
+		return p
+	}
+
+	for index, code := range codes {
+		p.ExitAt(index, code)
+	}
+
+	return p
+}
+
+// Error 返回链式调用中累积的错误
+func (p *PipelineRunner) Error() error {
+	return p.err
+}
+
+// Result 返回最后一个命令的执行结果
+func (p *PipelineRunner) Result() *executable.ExecutableResult {
+	if p.results == nil {
+		return nil
+	}
+	return &p.results[len(p.results)-1]
+}
+
+// Results 返回每个命令的执行结果（只有最后一个命令的 Stdout 会被记录）
+func (p *PipelineRunner) Results() []executable.ExecutableResult {
+	return p.results
+}
+
+// GetStdout 返回最后一个命令的标准输出内容
+func (p *PipelineRunner) GetStdout() string {
+	if p.results == nil {
+		return ""
+	}
+	return normalizeOutput(string(p.Result().Stdout))
+}
+
+// String 返回管道的可读表示，例如 "cat input.txt | ./wc -l"
+func (p *PipelineRunner) String() string {
+	parts := make([]string, len(p.commands))
+	for i, command := range p.commands {
+		parts[i] = command.String()
+	}
+	return strings.Join(parts, " | ")
+}
+
+// PipelineExitCodeMismatch 表示管道中某个命令的退出码不匹配
+type PipelineExitCodeMismatch struct {
+	*ExitCodeMismatch
+
+	Index   int
+	Command string
+}
+
+func (e *PipelineExitCodeMismatch) Error() string {
+	return i18n.T("pipeline command #%d (%s): %s", e.Index+1, e.Command, e.ExitCodeMismatch.Error())
+}
+
+func (e *PipelineExitCodeMismatch) Unwrap() error {
+	return e.ExitCodeMismatch
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeline_Execute(t *testing.T) {
+	// 等价于 echo hello | tr a-z A-Z
+	p := Pipeline(".", Cmd("echo", "hello"), Cmd("tr", "a-z", "A-Z")).
+		Execute().
+		StdoutExact("HELLO").
+		StdoutExactStrict("HELLO\n").
+		ExitCodes(0, 0)
+
+	assert.NoError(t, p.Error())
+}
+
+func TestPipeline_Stdin(t *testing.T) {
+	p := Pipeline(".", Cmd("cat"), Cmd("wc", "-l")).
+		Stdin("a\nb\nc").
+		StdoutRegex(`^\s*3\s*$`).
+		Exit(0)
+
+	assert.NoError(t, p.Error())
+}
+
+func TestPipeline_LocalExecutable(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestScript(t, tmpDir, "count.sh", `#!/bin/bash
+wc -l
+`)
+
+	p := Pipeline(tmpDir, Cmd("printf", `1\n2\n`), Cmd("count.sh")).
+		Execute().
+		StdoutExact("2")
+
+	assert.NoError(t, p.Error())
+}
+
+func TestPipeline_PerProcessExitCodes(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestScript(t, tmpDir, "fail.sh", `#!/bin/bash
+echo "broken" >&2
+cat
+exit 3
+`)
+
+	p := Pipeline(tmpDir, Cmd("echo", "hello"), Cmd("./fail.sh"), Cmd("cat")).Execute()
+	assert.NoError(t, p.Error())
+
+	results := p.Results()
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, 0, results[0].ExitCode)
+	assert.Equal(t, 3, results[1].ExitCode)
+	assert.Equal(t, "broken\n", string(results[1].Stderr))
+	assert.Equal(t, 0, results[2].ExitCode)
+	assert.Equal(t, "hello\n", p.GetStdout())
+
+	// 最后一个命令的退出码与 shell 的 $? 一致
+	assert.NoError(t, p.Exit(0).Error())
+
+	p.ExitCodes(0, 0, 0)
+	assert.Error(t, p.Error())
+	assert.IsType(t, &PipelineExitCodeMismatch{}, p.Error())
+	assert.Contains(t, p.Error().Error(), "./fail.sh")
+	assert.Contains(t, p.Error().Error(), "expected exit code 0, got 3")
+}
+
+func TestPipeline_CommandNotFound(t *testing.T) {
+	p := Pipeline(".", Cmd("echo", "hello"), Cmd("nonexistent_command_12345")).Execute()
+	assert.Error(t, p.Error())
+	assert.Contains(t, p.Error().Error(), "not found")
+}
+
+func TestPipeline_Timeout(t *testing.T) {
+	p := Pipeline(".", Cmd("sleep", "10"), Cmd("cat")).
+		WithTimeout(200 * time.Millisecond).
+		Execute()
+
+	assert.Error(t, p.Error())
+	assert.Contains(t, p.Error().Error(), "timed out")
+}
+
+func TestPipeline_NotExecuted(t *testing.T) {
+	p := Pipeline(".", Cmd("echo", "test")).Stdout("test")
+	assert.Error(t, p.Error())
+	assert.Contains(t, p.Error().Error(), "not yet executed")
+
+	p = Pipeline(".")
+	assert.Error(t, p.Error())
+}
+
+func TestPipeline_String(t *testing.T) {
+	p := Pipeline(".", Cmd("cat", "input.txt"), Cmd("./wc", "-l"))
+	assert.Equal(t, "cat input.txt | ./wc -l", p.String())
+}
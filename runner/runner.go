@@ -2,17 +2,19 @@ package runner
 
 import (
 	"bytes"
-	"fmt"
+	"errors"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 
+	"github.com/bootllm/tester-utils/build"
 	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/hints"
+	"github.com/bootllm/tester-utils/i18n"
 	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/text_diff_visualizer"
 )
 
 // Runner 提供类似 check50 的链式 API 来运行和测试程序
@@ -40,6 +42,8 @@ type Runner struct {
 	executable *executable.Executable
 	started    bool
 	stdout     *bytes.Buffer // 用于交互模式收集输出
+	errIsStale bool          // err 之后又调用过其他检查，Hint 不再作用于 err
+	hideNext   bool          // 下一个输出检查的期望值是隐藏的，见 Hidden
 }
 
 // Run 创建一个新的 Runner 实例
@@ -80,7 +84,19 @@ func (r *Runner) WithPty() *Runner {
 
 // createExecutable 创建并配置 executable
 func (r *Runner) createExecutable() *executable.Executable {
-	cmdPath := r.command
+	e := executable.NewExecutable(resolveCommandPath(r.workDir, r.command))
+	e.WorkingDir = r.workDir
+	e.TimeoutInMilliseconds = int(r.timeout.Milliseconds())
+	e.ShouldUsePty = r.usePty
+	e.Env = r.env
+
+	return e
+}
+
+// resolveCommandPath 解析命令路径
+// 本地可执行文件会拼接 workDir，系统命令原样返回
+func resolveCommandPath(workDir string, command string) string {
+	cmdPath := command
 
 	// 判断是否为本地可执行文件
 	// 1. 包含路径分隔符（如 ./hello, ../bin/test, path/to/file）
@@ -90,27 +106,19 @@ func (r *Runner) createExecutable() *executable.Executable {
 		strings.HasPrefix(cmdPath, "../") ||
 		strings.Contains(cmdPath, "/") ||
 		filepath.IsAbs(cmdPath) ||
-		fileExistsInDir(r.workDir, cmdPath)
+		fileExistsInDir(workDir, cmdPath)
 
-	var fullPath string
-	if isLocalExecutable {
-		// 本地可执行文件：拼接 workDir
-		if !filepath.IsAbs(cmdPath) && !strings.HasPrefix(cmdPath, "./") {
-			cmdPath = "./" + cmdPath
-		}
-		fullPath = filepath.Join(r.workDir, cmdPath)
-	} else {
+	if !isLocalExecutable {
 		// 系统命令（如 python3, bash, clang）：直接传给 executable
 		// executable.resolveAbsolutePath 会通过 exec.LookPath 查找
-		fullPath = cmdPath
+		return cmdPath
 	}
 
-	e := executable.NewExecutable(fullPath)
-	e.WorkingDir = r.workDir
-	e.TimeoutInMilliseconds = int(r.timeout.Milliseconds())
-	e.ShouldUsePty = r.usePty
-
-	return e
+	// 本地可执行文件：拼接 workDir
+	if !filepath.IsAbs(cmdPath) && !strings.HasPrefix(cmdPath, "./") {
+		cmdPath = "./" + cmdPath
+	}
+	return filepath.Join(workDir, cmdPath)
 }
 
 // fileExistsInDir 检查文件是否存在于指定目录中
@@ -122,7 +130,7 @@ func fileExistsInDir(dir, name string) bool {
 
 // Start 启动程序但不等待结束（交互模式）
 func (r *Runner) Start() *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 
@@ -143,12 +151,12 @@ func (r *Runner) Start() *Runner {
 
 // SendLine 发送一行输入（交互模式，需要先调用 Start）
 func (r *Runner) SendLine(input string) *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 
 	if !r.started {
-		r.err = fmt.Errorf("program not started, call Start() first")
+		r.err = i18n.Errorf("program not started, call Start() first")
 		return r
 	}
 
@@ -158,7 +166,7 @@ func (r *Runner) SendLine(input string) *Runner {
 
 	// 使用 executable 的 SendLine 方法发送输入
 	if err := r.executable.SendLine(input); err != nil {
-		r.err = fmt.Errorf("failed to send input: %v", err)
+		r.err = i18n.Errorf("failed to send input: %v", err)
 	}
 
 	return r
@@ -167,7 +175,7 @@ func (r *Runner) SendLine(input string) *Runner {
 // Reject 检查程序是否拒绝输入（继续等待而不是退出）
 // 类似 check50 的 reject()，检查程序在收到输入后是否继续运行等待更多输入
 func (r *Runner) Reject(rejectTimeout ...time.Duration) *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 
@@ -189,7 +197,7 @@ func (r *Runner) Reject(rejectTimeout ...time.Duration) *Runner {
 		for elapsed < timeout {
 			if r.executable.HasExited() {
 				r.err = &RejectError{
-					Message: "expected program to reject input and wait for more, but it exited",
+					Message: i18n.T("expected program to reject input and wait for more, but it exited"),
 				}
 				return r
 			}
@@ -197,6 +205,7 @@ func (r *Runner) Reject(rejectTimeout ...time.Duration) *Runner {
 			elapsed += checkInterval
 		}
 		// 程序仍在运行，这是期望的行为（输入被拒绝，程序等待更多输入）
+		emitCheckPassed(nil, "input rejected")
 	}
 
 	return r
@@ -204,7 +213,7 @@ func (r *Runner) Reject(rejectTimeout ...time.Duration) *Runner {
 
 // Stdin 发送输入并运行程序（阻塞式）
 func (r *Runner) Stdin(input string) *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 
@@ -217,7 +226,8 @@ func (r *Runner) Stdin(input string) *Runner {
 	// 运行程序
 	result, err := r.executable.RunWithStdin([]byte(input+"\n"), r.args...)
 	r.result = &result
-	if err != nil && err.Error() != "execution timed out" {
+	emitProgramOutput(r.commandLine(), result)
+	if err != nil && !errors.Is(err, executable.ErrTimeout) {
 		r.err = err
 	}
 
@@ -226,7 +236,7 @@ func (r *Runner) Stdin(input string) *Runner {
 
 // Execute 不带输入运行程序
 func (r *Runner) Execute() *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 
@@ -234,6 +244,7 @@ func (r *Runner) Execute() *Runner {
 
 	result, err := r.executable.Run(r.args...)
 	r.result = &result
+	emitProgramOutput(r.commandLine(), result)
 	r.err = err
 
 	return r
@@ -241,14 +252,15 @@ func (r *Runner) Execute() *Runner {
 
 // WaitForExit 等待程序结束（交互模式）
 func (r *Runner) WaitForExit() *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 
 	if r.executable != nil && r.started {
 		result, err := r.executable.Wait()
 		r.result = &result
-		if err != nil && err.Error() != "execution timed out" {
+		emitProgramOutput(r.commandLine(), result)
+		if err != nil && !errors.Is(err, executable.ErrTimeout) {
 			r.err = err
 		}
 		r.started = false
@@ -286,108 +298,131 @@ func (r *Runner) readAvailableOutput(reader io.Reader, timeout time.Duration) st
 
 // Stdout 检查标准输出是否包含期望内容
 func (r *Runner) Stdout(expected string) *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 	if r.result == nil {
-		r.err = fmt.Errorf("program not yet executed")
+		r.err = i18n.Errorf("program not yet executed")
 		return r
 	}
 
-	actual := normalizeOutput(string(r.result.Stdout))
-
-	if expected != "" {
-		if !strings.Contains(actual, expected) {
-			r.err = &Mismatch{
-				Expected: expected,
-				Actual:   actual,
-				Message:  fmt.Sprintf("expected output to contain %q", expected),
-			}
-		}
-	}
-
+	hidden := r.takeHidden()
+	r.err = checkStdoutContains(r.result.Stdout, expected, hidden)
+	emitCheckPassed(r.err, "output contains %s", describeExpected(expected, hidden))
 	return r
 }
 
 // StdoutRegex 使用正则表达式检查标准输出
 func (r *Runner) StdoutRegex(pattern string) *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 	if r.result == nil {
-		r.err = fmt.Errorf("program not yet executed")
-		return r
-	}
-
-	actual := normalizeOutput(string(r.result.Stdout))
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		r.err = fmt.Errorf("invalid regex pattern: %v", err)
+		r.err = i18n.Errorf("program not yet executed")
 		return r
 	}
 
-	if !re.MatchString(actual) {
-		r.err = &Mismatch{
-			Expected: pattern,
-			Actual:   actual,
-			Message:  fmt.Sprintf("expected output to match pattern %q", pattern),
-		}
-	}
-
+	hidden := r.takeHidden()
+	r.err = checkStdoutRegex(r.result.Stdout, pattern, hidden)
+	emitCheckPassed(r.err, "output matches %s", describeExpected(pattern, hidden))
 	return r
 }
 
 // StdoutExact 检查标准输出是否完全匹配
 func (r *Runner) StdoutExact(expected string) *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 	if r.result == nil {
-		r.err = fmt.Errorf("program not yet executed")
+		r.err = i18n.Errorf("program not yet executed")
 		return r
 	}
 
-	actual := strings.TrimSpace(normalizeOutput(string(r.result.Stdout)))
-	expected = strings.TrimSpace(expected)
+	hidden := r.takeHidden()
+	r.err = checkStdoutExact(r.result.Stdout, expected, hidden)
+	emitCheckPassed(r.err, "output is %s", describeExpected(expected, hidden))
+	return r
+}
 
-	if actual != expected {
-		r.err = &Mismatch{
-			Expected: expected,
-			Actual:   actual,
-			Message:  "output mismatch",
-		}
+// StdoutExactStrict 检查标准输出是否逐字节完全匹配，包括首尾空白、末尾换行和 \r\n
+// 适合要求输出格式完全一致的 stage，失败时会说明行尾空格、缺少末尾换行等空白差异
+//
+// 用法示例:
+//
+//	runner.Run(dir, "./hello").Execute().StdoutExactStrict("hello, world\n").Exit(0)
+func (r *Runner) StdoutExactStrict(expected string) *Runner {
+	if r.skip() {
+		return r
+	}
+	if r.result == nil {
+		r.err = i18n.Errorf("program not yet executed")
+		return r
 	}
 
+	hidden := r.takeHidden()
+	r.err = checkStdoutExactStrict(r.result.Stdout, expected, hidden)
+	emitCheckPassed(r.err, "output is %s", describeExpected(expected, hidden))
 	return r
 }
 
-// normalizeOutput 标准化输出（移除 PTY 的 \r\n 转换为 \n）
-func normalizeOutput(s string) string {
-	return strings.ReplaceAll(s, "\r\n", "\n")
-}
-
 // Exit 检查退出码
 func (r *Runner) Exit(code int) *Runner {
-	if r.err != nil {
+	if r.skip() {
 		return r
 	}
 	if r.result == nil {
-		r.err = fmt.Errorf("program not yet executed")
+		r.err = i18n.Errorf("program not yet executed")
 		return r
 	}
 
-	if r.result.ExitCode != code {
-		r.err = &ExitCodeMismatch{
-			Expected: code,
-			Actual:   r.result.ExitCode,
-			Stdout:   normalizeOutput(string(r.result.Stdout)),
-			Stderr:   normalizeOutput(string(r.result.Stderr)),
-		}
+	r.err = checkExitCode(*r.result, code)
+	emitCheckPassed(r.err, "exit code %d", code)
+	return r
+}
+
+// skip 在之前的步骤已失败时返回 true，链式方法应直接返回
+func (r *Runner) skip() bool {
+	if r.err != nil {
+		r.errIsStale = true
+		return true
+	}
+
+	return false
+}
+
+// Hint 为上一个检查附加提示，仅当上一个检查失败时生效（之前的步骤已失败时忽略）
+// 传入多个提示时，随着该 stage 连续失败的次数逐级显示
+//
+// 用法示例:
+//
+//	runner.Run(dir, "./mario").Stdin("4").Stdout(expected).Hint("每行末尾不要有多余的空格").Exit(0)
+func (r *Runner) Hint(levels ...string) *Runner {
+	if r.err == nil || r.errIsStale {
+		return r
 	}
 
+	r.err = hints.Attach(r.err, levels...)
+	return r
+}
+
+// Hidden 将下一个输出检查（Stdout、StdoutRegex、StdoutExact 或 StdoutExactStrict）的期望值标记为隐藏：
+// 检查失败时错误信息为 "output did not match hidden expected value"，不会向学生展示期望值
+//
+// 用法示例:
+//
+//	runner.Run(dir, "./cash").Stdin("0.41").Hidden().StdoutExact("4").Exit(0)
+func (r *Runner) Hidden() *Runner {
+	r.hideNext = true
 	return r
 }
 
+// takeHidden 返回下一个输出检查是否隐藏期望值，并清除该标记
+func (r *Runner) takeHidden() bool {
+	hidden := r.hideNext
+	r.hideNext = false
+	return hidden
+}
+
 // Error 返回链式调用中累积的错误
 func (r *Runner) Error() error {
 	return r.err
@@ -421,13 +456,35 @@ type Mismatch struct {
 	Actual   string
 	Message  string
 	Help     string
+	Hidden   bool // 期望值是隐藏的（见 Runner.Hidden），Error 不会包含 Expected
+	ShowDiff bool // Error 在 Message 之后附加 Expected 和 Actual 的逐行 diff（只有空白字符不同时显示空白字符并说明差异）
+}
+
+// Hint 返回 Help 作为提示，由 test_runner 在失败后显示
+func (m *Mismatch) Hint() hints.Hint {
+	if m.Help == "" {
+		return hints.Hint{}
+	}
+
+	return hints.New(m.Help)
 }
 
 func (m *Mismatch) Error() string {
-	if m.Message != "" {
-		return m.Message
+	if m.Hidden {
+		return i18n.T("output did not match hidden expected value")
+	}
+	message := m.Message
+	if message == "" {
+		message = i18n.T("expected %q, got %q", m.Expected, m.Actual)
+	}
+
+	if m.ShowDiff && text_diff_visualizer.OnlyWhitespaceDiffers(m.Actual, m.Expected) {
+		message += "\n" + strings.Join(text_diff_visualizer.VisualizeWhitespaceDiff(m.Actual, m.Expected), "\n")
+	} else if m.ShowDiff {
+		message += "\n" + strings.Join(text_diff_visualizer.VisualizeTextDiff(m.Actual, m.Expected), "\n")
 	}
-	return fmt.Sprintf("expected %q, got %q", m.Expected, m.Actual)
+
+	return message
 }
 
 // ExitCodeMismatch 表示退出码不匹配
@@ -439,30 +496,35 @@ type ExitCodeMismatch struct {
 }
 
 func (e *ExitCodeMismatch) Error() string {
-	msg := fmt.Sprintf("expected exit code %d, got %d", e.Expected, e.Actual)
+	msg := i18n.T("expected exit code %d, got %d", e.Expected, e.Actual)
 	if e.Stderr != "" {
-		msg += fmt.Sprintf("\nStderr: %s", e.Stderr)
+		msg += "\n" + i18n.T("Stderr: %s", e.Stderr)
 	}
 	return msg
 }
 
 // CompileC 编译 C 文件
+// 编译结果会缓存在 build.DefaultCache() 中，源文件、参数和编译器版本相同时直接复用
 func CompileC(workDir, source, output string, flags ...string) error {
-	args := []string{"-o", output, source}
-	args = append(args, flags...)
+	toolchain := build.CC{
+		Compiler: "clang",
+		Sources:  []string{source},
+		Output:   output,
+		Flags:    flags,
+	}
 
-	cmd := exec.Command("clang", args...)
-	cmd.Dir = workDir
+	_, err := build.Run(toolchain, build.Options{WorkDir: workDir, Cache: build.DefaultCache()})
 
-	out, err := cmd.CombinedOutput()
-	if err != nil {
+	var buildError *build.Error
+	if errors.As(err, &buildError) {
 		return &CompileError{
 			Source: source,
-			Output: string(out),
-			Err:    err,
+			Output: buildError.Output,
+			Err:    buildError.Err,
 		}
 	}
-	return nil
+
+	return err
 }
 
 // CompileError 表示编译错误
@@ -473,5 +535,5 @@ type CompileError struct {
 }
 
 func (e *CompileError) Error() string {
-	return fmt.Sprintf("failed to compile %s: %s\n%s", e.Source, e.Err, e.Output)
+	return i18n.T("failed to compile %s: %s", e.Source, e.Err) + "\n" + e.Output
 }
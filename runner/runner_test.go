@@ -1,11 +1,19 @@
 package runner
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/bootllm/tester-utils/build"
+	"github.com/bootllm/tester-utils/events"
+	"github.com/bootllm/tester-utils/hints"
+	"github.com/bootllm/tester-utils/i18n"
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -115,6 +123,16 @@ func TestWithTimeout(t *testing.T) {
 	assert.NotNil(t, result)
 }
 
+func TestWithEnv(t *testing.T) {
+	// 测试环境变量传递给程序
+	r := Run(".", "sh", "-c", "echo $SERVER_ADDR").
+		WithEnv("SERVER_ADDR=127.0.0.1:4321").
+		Execute()
+
+	assert.NoError(t, r.Error())
+	assert.Equal(t, "127.0.0.1:4321\n", r.GetStdout())
+}
+
 func TestWithPty(t *testing.T) {
 	// 测试 PTY 模式
 	r := Run(".", "echo", "test").
@@ -277,6 +295,49 @@ func TestMismatch_Error(t *testing.T) {
 	assert.Contains(t, m2.Error(), "actual")
 }
 
+func TestMismatch_ErrorDiff(t *testing.T) {
+	previousNoColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = previousNoColor }()
+
+	r := Run(".", "printf", "#\\n##\\n").Execute().StdoutExact("#\n###")
+	require.Error(t, r.Error())
+	assert.Equal(t, "output mismatch\n--- expected\n+++ actual\n@@ -1,2 +1,2 @@\n1 1 |  #\n2   | -###\n  2 | +##", r.Error().Error())
+
+	// 只有空白字符不同时显示空白字符并说明差异
+	r = Run(".", "printf", "#\\n##\\n").Execute().StdoutExact("#\n ##")
+	require.Error(t, r.Error())
+	assert.Equal(t, "output mismatch\nmissing spaces at start of line 2\n--- expected\n+++ actual\n@@ -1,2 +1,2 @@\n1 1 |  #↵\n2   | -·##\n  2 | +##\n    |  ^", r.Error().Error())
+
+	// 首尾空白被忽略，所以末尾换行和最后一行的行尾空格不会导致失败
+	r = Run(".", "printf", "#\\n##  ").Execute().StdoutExact("#\n##\n")
+	assert.NoError(t, r.Error())
+
+	// 隐藏的期望值不显示 diff
+	r = Run(".", "echo", "hello").Execute().Hidden().StdoutExact("secret")
+	assert.NotContains(t, r.Error().Error(), "secret")
+}
+
+func TestRun_StdoutExactStrict(t *testing.T) {
+	previousNoColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = previousNoColor }()
+
+	r := Run(".", "printf", "hello\\n").Execute().StdoutExactStrict("hello\n")
+	assert.NoError(t, r.Error())
+
+	// 末尾换行不会被忽略，失败信息说明差异
+	r = Run(".", "printf", "hello").Execute().StdoutExactStrict("hello\n")
+	require.Error(t, r.Error())
+	assert.Contains(t, r.Error().Error(), "expected trailing newline")
+
+	// \r\n 不会被转换为 \n，回车显示为 ␍
+	r = Run(".", "printf", "a\\r\\nb\\n").Execute().StdoutExactStrict("a\nb\n")
+	require.Error(t, r.Error())
+	assert.Contains(t, r.Error().Error(), "line 1 ends with \\r\\n (Windows line ending), expected \\n")
+	assert.Contains(t, r.Error().Error(), "+a␍↵")
+}
+
 func TestExitCodeMismatch_Error(t *testing.T) {
 	e := &ExitCodeMismatch{
 		Expected: 0,
@@ -289,6 +350,27 @@ func TestExitCodeMismatch_Error(t *testing.T) {
 	assert.Contains(t, errMsg, "error message")
 }
 
+// TestErrorMessagesTranslated 测试错误信息在输出时按当前语言翻译
+func TestErrorMessagesTranslated(t *testing.T) {
+	i18n.SetLocale(i18n.Chinese)
+	defer i18n.SetLocale(i18n.English)
+
+	e := &ExitCodeMismatch{Expected: 0, Actual: 1, Stderr: "segfault"}
+	assert.Equal(t, "期望退出码为 0，实际为 1\n标准错误输出：segfault", e.Error())
+
+	r := Run(".", "./does-not-exist").Execute()
+	assert.EqualError(t, r.Error(), "找不到 does-not-exist")
+}
+
+// TestStdin_TimeoutTranslated 测试超时的判断不依赖错误信息的语言
+func TestStdin_TimeoutTranslated(t *testing.T) {
+	i18n.SetLocale(i18n.Chinese)
+	defer i18n.SetLocale(i18n.English)
+
+	r := Run(".", "sleep", "10").WithTimeout(300 * time.Millisecond).Stdin("")
+	assert.NoError(t, r.Error())
+}
+
 func TestRejectError_Error(t *testing.T) {
 	e := &RejectError{Message: "test error"}
 	assert.Equal(t, "test error", e.Error())
@@ -319,3 +401,100 @@ func TestGetStdout_NilResult(t *testing.T) {
 	r := Run(".", "echo", "test")
 	assert.Equal(t, "", r.GetStdout())
 }
+
+// ============== 提示测试 ==============
+
+func TestHint(t *testing.T) {
+	// 上一个检查失败时附加提示
+	r := Run(".", "echo", "hello").Execute().Stdout("world").Hint("检查拼写", "应该输出 world").Exit(0)
+	assert.IsType(t, &Mismatch{}, errors.Unwrap(r.Error()))
+	assert.Equal(t, []string{"检查拼写", "应该输出 world"}, hints.For(r.Error()).Levels)
+
+	// 检查通过时不附加
+	r = Run(".", "echo", "hello").Execute().Stdout("hello").Hint("不应出现").Exit(1)
+	assert.True(t, hints.For(r.Error()).IsEmpty())
+
+	// 更早的步骤失败时，后面检查的提示不适用
+	r = Run(".", "echo", "hello").Execute().Stdout("world").Exit(0).Hint("只适用于退出码")
+	assert.True(t, hints.For(r.Error()).IsEmpty())
+}
+
+func TestMismatch_Hint(t *testing.T) {
+	assert.True(t, (&Mismatch{}).Hint().IsEmpty())
+	assert.Equal(t, []string{"注意换行"}, hints.For(&Mismatch{Help: "注意换行"}).Levels)
+}
+
+// ============== 事件流测试 ==============
+
+func TestEvents(t *testing.T) {
+	var buf bytes.Buffer
+	events.Enable(&buf)
+	defer events.Disable()
+
+	err := Run(".", "sh", "-c", "echo hello; echo oops >&2").Execute().Stdout("hello").Exit(0).Stdout("world").Error()
+	assert.Error(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `"type":"program_output"`)
+	assert.Contains(t, output, `"command":"sh -c echo hello; echo oops >&2","stream":"stdout","output":"hello\n"`)
+	assert.Contains(t, output, `"stream":"stderr","output":"oops\n"`)
+	assert.Contains(t, output, `"check":"output contains \"hello\""`)
+	assert.Contains(t, output, `"check":"exit code 0"`)
+	assert.NotContains(t, output, "world", "failed checks aren't reported as passed")
+}
+
+// ============== 隐藏期望值测试 ==============
+
+func TestHidden(t *testing.T) {
+	r := Run(".", "echo", "hello").Execute().Hidden().StdoutExact("secret answer")
+	require.Error(t, r.Error())
+	assert.Equal(t, "output did not match hidden expected value", r.Error().Error())
+	assert.NotContains(t, r.Error().Error(), "secret answer")
+
+	mismatch := r.Error().(*Mismatch)
+	assert.True(t, mismatch.Hidden)
+	assert.Equal(t, "secret answer", mismatch.Expected, "test code can still read the expected value")
+
+	// Hidden 只作用于下一个检查
+	r = Run(".", "echo", "hello").Execute().Hidden().Stdout("hello").Stdout("world")
+	require.Error(t, r.Error())
+	assert.Contains(t, r.Error().Error(), "world")
+
+	p := Pipeline(".", Cmd("echo", "hello")).Execute().Hidden().StdoutRegex("^bye")
+	require.Error(t, p.Error())
+	assert.Equal(t, "output did not match hidden expected value", p.Error().Error())
+}
+
+func TestHiddenEvents(t *testing.T) {
+	var buf bytes.Buffer
+	events.Enable(&buf)
+	defer events.Disable()
+
+	r := Run(".", "echo", "hello").Execute().Hidden().Stdout("ell")
+	assert.NoError(t, r.Error())
+
+	assert.Contains(t, buf.String(), `"check":"output contains hidden expected value"`)
+	assert.NotContains(t, buf.String(), "ell\"")
+}
+
+// TestCompileC_LogsCacheHit 测试第二次编译相同的源文件时命中缓存，并在调试日志中说明
+func TestCompileC_LogsCacheHit(t *testing.T) {
+	// 用假的 clang 代替真正的编译器：把源文件复制为输出文件
+	binDir := t.TempDir()
+	createTestScript(t, binDir, "clang", "#!/bin/sh\nif [ \"$1\" = --version ]; then echo fake clang; exit 0; fi\ncp \"$3\" \"$2\"\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	sink := logger.NewMemorySink()
+	build.SetDebugLogger(logger.GetLoggerWithSink(true, "[build] ", sink))
+	defer build.SetDebugLogger(nil)
+	defer build.RemoveTemporaryCache()
+
+	dir := t.TempDir()
+	createTestScript(t, dir, "hello.c", "int main(void) { return 0; }\n")
+
+	require.NoError(t, CompileC(dir, "hello.c", "hello"))
+	assert.NotContains(t, sink.String(), "Build cache hit")
+
+	require.NoError(t, CompileC(dir, "hello.c", "hello"))
+	assert.Contains(t, sink.String(), "Build cache hit")
+}
@@ -0,0 +1,94 @@
+package tester_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bootllm/tester-utils/tester_context"
+	"github.com/bootllm/tester-utils/tester_definition"
+)
+
+// StageInfo describes a stage, as printed by `--list`
+type StageInfo struct {
+	// Index is the 1-based position of the stage, among regular or anti-cheat stages
+	Index          int    `json:"index"`
+	Slug           string `json:"slug"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	TimeoutSeconds int64  `json:"timeout_seconds"`
+	IsAntiCheat    bool   `json:"is_anti_cheat"`
+}
+
+// ListStages returns information about all stages in definition, followed by its anti-cheat stages
+func ListStages(definition tester_definition.TesterDefinition) []StageInfo {
+	stages := []StageInfo{}
+
+	for index, testCase := range definition.TestCases {
+		stages = append(stages, newStageInfo(index, testCase, false))
+	}
+
+	for index, testCase := range definition.AntiCheatTestCases {
+		stages = append(stages, newStageInfo(index, testCase, true))
+	}
+
+	return stages
+}
+
+func newStageInfo(index int, testCase tester_definition.TestCase, isAntiCheat bool) StageInfo {
+	return StageInfo{
+		Index:          index + 1,
+		Slug:           testCase.Slug,
+		Title:          tester_context.StageTitle(testCase),
+		Description:    testCase.Description,
+		TimeoutSeconds: int64(testCase.CustomOrDefaultTimeout().Seconds()),
+		IsAntiCheat:    isAntiCheat,
+	}
+}
+
+// printStageList prints all stages as a table, or as JSON if asJSON is true
+func printStageList(w io.Writer, definition tester_definition.TesterDefinition, asJSON bool) error {
+	stages := ListStages(definition)
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stages)
+	}
+
+	rows := [][]string{{"#", "SLUG", "TITLE", "TIMEOUT"}}
+	for _, stage := range stages {
+		index := fmt.Sprintf("%d", stage.Index)
+		if stage.IsAntiCheat {
+			index = fmt.Sprintf("AC%d", stage.Index)
+		}
+
+		rows = append(rows, []string{index, stage.Slug, stage.Title, fmt.Sprintf("%ds", stage.TimeoutSeconds)})
+	}
+
+	// Descriptions are printed below each row, aligned with the title column, so columns are padded by hand
+	widths := make([]int, 3)
+	for _, row := range rows {
+		for i := range widths {
+			widths[i] = max(widths[i], utf8.RuneCountInString(row[i]))
+		}
+	}
+
+	descriptionIndent := strings.Repeat(" ", widths[0]+widths[1]+4)
+
+	for i, row := range rows {
+		fmt.Fprintf(w, "%-*s  %-*s  %-*s  %s\n", widths[0], row[0], widths[1], row[1], widths[2], row[2], row[3])
+
+		if i == 0 || stages[i-1].Description == "" {
+			continue
+		}
+
+		for _, line := range strings.Split(strings.TrimSpace(stages[i-1].Description), "\n") {
+			fmt.Fprintf(w, "%s%s\n", descriptionIndent, line)
+		}
+	}
+
+	return nil
+}
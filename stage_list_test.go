@@ -0,0 +1,61 @@
+package tester_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bootllm/tester-utils/tester_definition"
+	"github.com/stretchr/testify/assert"
+)
+
+var listDefinition = tester_definition.TesterDefinition{
+	TestCases: []tester_definition.TestCase{
+		{Slug: "hello", Title: "Say Hello", Description: "Prints \"hello, world\".\nNo arguments needed.", TestFunc: passFunc},
+		{Slug: "mario-less", Timeout: 30 * time.Second, TestFunc: passFunc},
+	},
+	AntiCheatTestCases: []tester_definition.TestCase{
+		{Slug: "anti-cheat-1", Description: "Checks for copied code", TestFunc: passFunc},
+	},
+}
+
+func TestListStages(t *testing.T) {
+	assert.Equal(t, []StageInfo{
+		{Index: 1, Slug: "hello", Title: "Say Hello", Description: "Prints \"hello, world\".\nNo arguments needed.", TimeoutSeconds: 10},
+		{Index: 2, Slug: "mario-less", Title: "Mario Less", TimeoutSeconds: 30},
+		{Index: 1, Slug: "anti-cheat-1", Title: "Anti Cheat 1", Description: "Checks for copied code", TimeoutSeconds: 10, IsAntiCheat: true},
+	}, ListStages(listDefinition))
+}
+
+func TestPrintStageList(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, printStageList(&out, listDefinition, false))
+
+	expected := "" +
+		"#    SLUG          TITLE         TIMEOUT\n" +
+		"1    hello         Say Hello     10s\n" +
+		"                   Prints \"hello, world\".\n" +
+		"                   No arguments needed.\n" +
+		"2    mario-less    Mario Less    30s\n" +
+		"AC1  anti-cheat-1  Anti Cheat 1  10s\n" +
+		"                   Checks for copied code\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func TestPrintStageListJSON(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, printStageList(&out, listDefinition, true))
+
+	var stages []map[string]any
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &stages))
+	assert.Len(t, stages, 3)
+	assert.Equal(t, map[string]any{
+		"index":           float64(2),
+		"slug":            "mario-less",
+		"title":           "Mario Less",
+		"description":     "",
+		"timeout_seconds": float64(30),
+		"is_anti_cheat":   false,
+	}, stages[1])
+}
@@ -0,0 +1,256 @@
+package stub_server
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// Response is a scripted response sent by HTTPServer
+type Response struct {
+	// StatusCode defaults to 200
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+// RecordedRequest is a request received by HTTPServer
+type RecordedRequest struct {
+	Method string
+
+	// Path is the request target as sent by the client, including the query string
+	Path    string
+	Headers http.Header
+	Body    []byte
+}
+
+// HTTPServer is a scripted HTTP server listening on an ephemeral loopback port, used to test programs that act as
+// HTTP clients.
+//
+// Responses are sent in the order they're scripted. Once the script is exhausted, the last response is repeated (or
+// 404 is sent if no responses were scripted). All requests are recorded, so that they can be checked after the
+// program has run:
+//
+//	server, err := stub_server.NewHTTPServer(harness.Logger, stub_server.Response{Body: []byte("hello")})
+//	if err != nil {
+//	    return err
+//	}
+//	defer server.Close()
+//
+//	r := runner.Run(harness.SubmissionDir, "fetch", server.URL("/greeting")).Execute().Stdout("hello")
+//	if err := r.Error(); err != nil {
+//	    return err
+//	}
+//	return server.Request(0).ExpectMethod("GET").ExpectPath("/greeting").Error()
+type HTTPServer struct {
+	listener net.Listener
+	server   *http.Server
+	logger   *logger.Logger
+
+	mu        sync.Mutex
+	responses []Response
+	requests  []RecordedRequest
+}
+
+// NewHTTPServer starts an HTTPServer that replies with responses, in order
+func NewHTTPServer(logger *logger.Logger, responses ...Response) (*HTTPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start stub server: %v", err)
+	}
+
+	s := &HTTPServer{
+		listener:  listener,
+		logger:    serverLogger(logger),
+		responses: responses,
+	}
+	s.server = &http.Server{Handler: http.HandlerFunc(s.handle)}
+
+	s.logger.Debugf("Listening on %s", s.Address())
+
+	go s.server.Serve(listener)
+
+	return s, nil
+}
+
+// Address returns the address the server is listening on (e.g. "127.0.0.1:43567")
+func (s *HTTPServer) Address() string {
+	return s.listener.Addr().String()
+}
+
+// Host returns the host the server is listening on
+func (s *HTTPServer) Host() string {
+	return hostOf(s.Address())
+}
+
+// Port returns the port the server is listening on
+func (s *HTTPServer) Port() string {
+	return portOf(s.Address())
+}
+
+// URL returns the URL for path on this server (e.g. "http://127.0.0.1:43567/index.html")
+func (s *HTTPServer) URL(path string) string {
+	return "http://" + s.Address() + path
+}
+
+// EnvVar returns "name=address", for passing the address to the program using runner.Runner.WithEnv
+func (s *HTTPServer) EnvVar(name string) string {
+	return name + "=" + s.Address()
+}
+
+// Close stops the server
+func (s *HTTPServer) Close() error {
+	return s.server.Close()
+}
+
+// Requests returns all requests recorded so far
+func (s *HTTPServer) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]RecordedRequest{}, s.requests...)
+}
+
+// WaitForRequests waits until at least n requests have been received
+func (s *HTTPServer) WaitForRequests(n int, timeout time.Duration) error {
+	return waitFor(timeout, func() bool { return len(s.Requests()) >= n }, func() error {
+		return fmt.Errorf("expected %d request(s) to stub server within %v, got %d", n, timeout, len(s.Requests()))
+	})
+}
+
+// ExpectRequestCount checks that exactly n requests were received
+func (s *HTTPServer) ExpectRequestCount(n int) error {
+	if count := len(s.Requests()); count != n {
+		return fmt.Errorf("expected %d request(s) to stub server, got %d", n, count)
+	}
+
+	return nil
+}
+
+// Request returns assertions for the request at index (0-based). Checks are chainable, call Error() to get the
+// first failure.
+func (s *HTTPServer) Request(index int) *RequestAssertion {
+	requests := s.Requests()
+	if index < 0 {
+		return &RequestAssertion{err: fmt.Errorf("invalid request index %d", index)}
+	}
+
+	if index >= len(requests) {
+		return &RequestAssertion{err: fmt.Errorf("expected client to send request #%d, but it sent %d request(s)", index+1, len(requests))}
+	}
+
+	return &RequestAssertion{request: requests[index], index: index}
+}
+
+func (s *HTTPServer) handle(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	// net/http moves the Host header to req.Host, put it back so that it can be checked like other headers
+	headers := req.Header.Clone()
+	headers.Set("Host", req.Host)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method:  req.Method,
+		Path:    req.RequestURI,
+		Headers: headers,
+		Body:    body,
+	})
+	requestIndex := len(s.requests) - 1
+	response := s.nextResponse(requestIndex)
+	s.mu.Unlock()
+
+	s.logger.Infof("Received %s %s", req.Method, req.RequestURI)
+
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+	w.WriteHeader(response.StatusCode)
+	w.Write(response.Body)
+
+	s.logger.Infof("Sent %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+}
+
+// nextResponse returns the scripted response for the request at requestIndex. Must be called with mu held.
+func (s *HTTPServer) nextResponse(requestIndex int) Response {
+	if len(s.responses) == 0 {
+		return Response{StatusCode: http.StatusNotFound}
+	}
+
+	response := s.responses[min(requestIndex, len(s.responses)-1)]
+	if response.StatusCode == 0 {
+		response.StatusCode = http.StatusOK
+	}
+
+	return response
+}
+
+// RequestAssertion checks a RecordedRequest. The first failed check is kept, later checks are skipped.
+type RequestAssertion struct {
+	request RecordedRequest
+	index   int
+	err     error
+}
+
+// ExpectMethod checks the request method
+func (a *RequestAssertion) ExpectMethod(method string) *RequestAssertion {
+	return a.check(a.request.Method == method, "method %q, got %q", method, a.request.Method)
+}
+
+// ExpectPath checks the request target, including the query string
+func (a *RequestAssertion) ExpectPath(path string) *RequestAssertion {
+	return a.check(a.request.Path == path, "path %q, got %q", path, a.request.Path)
+}
+
+// ExpectHeader checks that the request has the header name, with value
+func (a *RequestAssertion) ExpectHeader(name string, value string) *RequestAssertion {
+	if a.err != nil {
+		return a
+	}
+
+	values, ok := a.request.Headers[http.CanonicalHeaderKey(name)]
+	if !ok {
+		return a.check(false, "header %q, but it was missing", name)
+	}
+
+	return a.check(values[0] == value, "header %q: %q, got %q", name, value, values[0])
+}
+
+// ExpectBody checks the request body
+func (a *RequestAssertion) ExpectBody(body []byte) *RequestAssertion {
+	return a.check(string(a.request.Body) == string(body), "body %s, got %s", formatBytes(body), formatBytes(a.request.Body))
+}
+
+// ExpectBodyRegex checks that the request body matches pattern
+func (a *RequestAssertion) ExpectBodyRegex(pattern string) *RequestAssertion {
+	if a.err != nil {
+		return a
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		a.err = fmt.Errorf("invalid regex pattern: %v", err)
+		return a
+	}
+
+	return a.check(re.Match(a.request.Body), "a body matching %q, got %s", pattern, formatBytes(a.request.Body))
+}
+
+// Error returns the first failed check, or nil
+func (a *RequestAssertion) Error() error {
+	return a.err
+}
+
+func (a *RequestAssertion) check(ok bool, format string, args ...any) *RequestAssertion {
+	if a.err == nil && !ok {
+		a.err = fmt.Errorf("expected request #%d to have %s", a.index+1, fmt.Sprintf(format, args...))
+	}
+
+	return a
+}
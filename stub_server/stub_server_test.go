@@ -0,0 +1,116 @@
+package stub_server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/runner"
+	"github.com/bootllm/tester-utils/tcp_client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() *logger.Logger {
+	return logger.GetLogger(true, "[test] ")
+}
+
+func TestTCPServer_Script(t *testing.T) {
+	server, err := NewTCPServer(testLogger(), Send("220 ready\r\n"), ReadLine(), Send("250 ok\r\n"), ReadLine(), Send("221 bye\r\n"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := tcp_client.Connect(server.Address(), testLogger())
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.ExpectLine("220 ready"))
+	require.NoError(t, client.SendLine("HELO test"))
+	require.NoError(t, client.ExpectLine("250 ok"))
+	require.NoError(t, client.Send([]byte("QUIT\r\n")))
+	require.NoError(t, client.ExpectLine("221 bye"))
+	require.NoError(t, client.ExpectClosed())
+
+	assert.NoError(t, server.ExpectConnectionCount(1))
+	assert.NoError(t, server.ExpectLines([]string{"HELO test", "QUIT"}))
+	assert.NoError(t, server.ExpectReceived([]byte("HELO test\nQUIT\r\n")))
+	assert.EqualError(t, server.ExpectLines([]string{"HELO test", "NOOP"}), `expected client to send line #2 "NOOP", got "QUIT"`)
+}
+
+func TestTCPServer_NoConnection(t *testing.T) {
+	server, err := NewTCPServer(testLogger(), ReadLine())
+	require.NoError(t, err)
+	defer server.Close()
+
+	assert.Error(t, server.ExpectLines([]string{"hello"}))
+	assert.EqualError(t, server.WaitForConnections(1, 50*time.Millisecond), "expected 1 connection(s) to stub server within 50ms, got 0")
+}
+
+func TestTCPServer_WithRunner(t *testing.T) {
+	server, err := NewTCPServer(testLogger(), Send("hello from server\n"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	// The address is passed using an environment variable, the port using an argument
+	r := runner.Run(".", "sh", "-c", `test "$SERVER_ADDR" = "127.0.0.1:$1" && echo ok`, "sh", server.Port()).
+		WithEnv(server.EnvVar("SERVER_ADDR")).
+		Execute().
+		Stdout("ok")
+
+	assert.NoError(t, r.Error())
+}
+
+func TestNilLogger(t *testing.T) {
+	tcpServer, err := NewTCPServer(nil, Send("hello\n"))
+	require.NoError(t, err)
+	defer tcpServer.Close()
+
+	client, err := tcp_client.Connect(tcpServer.Address(), nil)
+	require.NoError(t, err)
+	defer client.Close()
+	assert.NoError(t, client.ExpectLine("hello"))
+
+	httpServer, err := NewHTTPServer(nil)
+	require.NoError(t, err)
+	defer httpServer.Close()
+}
+
+func TestHTTPServer(t *testing.T) {
+	server, err := NewHTTPServer(testLogger(),
+		Response{Body: []byte("first")},
+		Response{StatusCode: 201, Headers: map[string]string{"X-Stub": "yes"}, Body: []byte("second")},
+	)
+	require.NoError(t, err)
+	defer server.Close()
+
+	response, err := http.Get(server.URL("/a?x=1"))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "first", string(body))
+
+	for i := 0; i < 2; i++ {
+		response, err = http.Post(server.URL("/b"), "application/json", strings.NewReader(`{"n":1}`))
+		require.NoError(t, err)
+		body, _ = io.ReadAll(response.Body)
+		response.Body.Close()
+
+		// The last response is repeated once the script is exhausted
+		assert.Equal(t, 201, response.StatusCode)
+		assert.Equal(t, "yes", response.Header.Get("X-Stub"))
+		assert.Equal(t, "second", string(body))
+	}
+
+	assert.NoError(t, server.ExpectRequestCount(3))
+	assert.NoError(t, server.Request(0).ExpectMethod("GET").ExpectPath("/a?x=1").ExpectHeader("host", server.Address()).Error())
+	assert.NoError(t, server.Request(1).ExpectMethod("POST").ExpectHeader("Content-Type", "application/json").ExpectBody([]byte(`{"n":1}`)).ExpectBodyRegex(`"n":\d`).Error())
+
+	assert.EqualError(t, server.Request(0).ExpectMethod("POST").ExpectPath("/nope").Error(), `expected request #1 to have method "POST", got "GET"`)
+	assert.EqualError(t, server.Request(0).ExpectHeader("Authorization", "x").Error(), `expected request #1 to have header "Authorization", but it was missing`)
+	assert.EqualError(t, server.Request(3).ExpectMethod("GET").Error(), "expected client to send request #4, but it sent 3 request(s)")
+	assert.EqualError(t, server.Request(-1).ExpectMethod("GET").Error(), "invalid request index -1")
+}
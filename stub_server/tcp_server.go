@@ -0,0 +1,330 @@
+package stub_server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bootllm/tester-utils/inspectable_byte_string"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// Step is a single step of a TCPServer script, run for every connection
+type Step struct {
+	// send is written to the client, if readLine is false
+	send []byte
+
+	// readLine makes the server wait for a line from the client
+	readLine bool
+
+	// close makes the server close the connection
+	close bool
+}
+
+// Send returns a step that writes data to the client
+func Send(data string) Step {
+	return Step{send: []byte(data)}
+}
+
+// ReadLine returns a step that waits for the client to send a line (terminated by "\n")
+func ReadLine() Step {
+	return Step{readLine: true}
+}
+
+// CloseConnection returns a step that closes the connection. Connections are also closed when the script ends.
+func CloseConnection() Step {
+	return Step{close: true}
+}
+
+// Connection holds everything a client sent over one connection
+type Connection struct {
+	// Received holds all bytes received from the client
+	Received []byte
+
+	// Lines holds the lines read by ReadLine steps, without line endings
+	Lines []string
+}
+
+// TCPServer is a scripted TCP server listening on an ephemeral loopback port, used to test programs that act as
+// clients.
+//
+// Every connection runs the same script. Everything clients send is recorded, so that it can be checked after the
+// program has run:
+//
+//	server, err := stub_server.NewTCPServer(harness.Logger, stub_server.Send("220 ready\r\n"), stub_server.ReadLine(), stub_server.Send("221 bye\r\n"))
+//	if err != nil {
+//	    return err
+//	}
+//	defer server.Close()
+//
+//	r := runner.Run(harness.SubmissionDir, "client", server.Host(), server.Port()).Execute().Exit(0)
+//	if err := r.Error(); err != nil {
+//	    return err
+//	}
+//	return server.ExpectLines([]string{"QUIT"})
+type TCPServer struct {
+	listener net.Listener
+	logger   *logger.Logger
+	script   []Step
+
+	mu          sync.Mutex
+	connections []*Connection
+	openConns   []net.Conn
+	wg          sync.WaitGroup
+}
+
+// NewTCPServer starts a TCPServer that runs script for every connection
+func NewTCPServer(logger *logger.Logger, script ...Step) (*TCPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start stub server: %v", err)
+	}
+
+	server := &TCPServer{
+		listener: listener,
+		logger:   serverLogger(logger),
+		script:   script,
+	}
+
+	server.logger.Debugf("Listening on %s", server.Address())
+
+	server.wg.Add(1)
+	go server.acceptLoop()
+
+	return server, nil
+}
+
+// Address returns the address the server is listening on (e.g. "127.0.0.1:43567")
+func (s *TCPServer) Address() string {
+	return s.listener.Addr().String()
+}
+
+// Host returns the host the server is listening on
+func (s *TCPServer) Host() string {
+	return hostOf(s.Address())
+}
+
+// Port returns the port the server is listening on
+func (s *TCPServer) Port() string {
+	return portOf(s.Address())
+}
+
+// EnvVar returns "name=address", for passing the address to the program using runner.Runner.WithEnv
+func (s *TCPServer) EnvVar(name string) string {
+	return name + "=" + s.Address()
+}
+
+// Close stops the server and closes all open connections
+func (s *TCPServer) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for _, conn := range s.openConns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// Connections returns all connections recorded so far
+func (s *TCPServer) Connections() []Connection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	connections := make([]Connection, len(s.connections))
+	for i, connection := range s.connections {
+		connections[i] = Connection{
+			Received: append([]byte{}, connection.Received...),
+			Lines:    append([]string{}, connection.Lines...),
+		}
+	}
+
+	return connections
+}
+
+// WaitForConnections waits until at least n connections have been accepted
+func (s *TCPServer) WaitForConnections(n int, timeout time.Duration) error {
+	return waitFor(timeout, func() bool { return len(s.Connections()) >= n }, func() error {
+		return fmt.Errorf("expected %d connection(s) to stub server within %v, got %d", n, timeout, len(s.Connections()))
+	})
+}
+
+// ExpectConnectionCount checks that exactly n connections were made
+func (s *TCPServer) ExpectConnectionCount(n int) error {
+	if count := len(s.Connections()); count != n {
+		return fmt.Errorf("expected %d connection(s) to stub server, got %d", n, count)
+	}
+
+	return nil
+}
+
+// ExpectLines checks that the lines read from the first connection are equal to expected
+func (s *TCPServer) ExpectLines(expected []string) error {
+	connection, err := s.firstConnection()
+	if err != nil {
+		return err
+	}
+
+	for i, line := range expected {
+		if i >= len(connection.Lines) {
+			return fmt.Errorf("expected client to send line #%d %q, but it sent only %d line(s)", i+1, line, len(connection.Lines))
+		}
+
+		if connection.Lines[i] != line {
+			return fmt.Errorf("expected client to send line #%d %q, got %q", i+1, line, connection.Lines[i])
+		}
+	}
+
+	return nil
+}
+
+// ExpectReceived checks that the bytes received on the first connection are exactly expected
+func (s *TCPServer) ExpectReceived(expected []byte) error {
+	connection, err := s.firstConnection()
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(connection.Received, expected) {
+		return fmt.Errorf("expected client to send %s, got %s", formatBytes(expected), formatBytes(connection.Received))
+	}
+
+	return nil
+}
+
+func (s *TCPServer) firstConnection() (Connection, error) {
+	connections := s.Connections()
+	if len(connections) == 0 {
+		return Connection{}, fmt.Errorf("expected client to connect to stub server at %s, but it didn't", s.Address())
+	}
+
+	return connections[0], nil
+}
+
+func (s *TCPServer) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		connection := &Connection{}
+
+		s.mu.Lock()
+		s.connections = append(s.connections, connection)
+		s.openConns = append(s.openConns, conn)
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn, connection)
+		}()
+	}
+}
+
+func (s *TCPServer) handle(conn net.Conn, connection *Connection) {
+	defer conn.Close()
+
+	s.logger.Debugf("Accepted connection from %s", conn.RemoteAddr())
+	reader := bufio.NewReader(&recordingReader{conn: conn, server: s, connection: connection})
+
+	for _, step := range s.script {
+		switch {
+		case step.close:
+			s.logger.Debugf("Closing connection")
+			return
+		case step.readLine:
+			conn.SetReadDeadline(time.Now().Add(defaultReadTimeout))
+
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				s.logger.Debugf("Failed to read line: %v", err)
+				return
+			}
+
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			s.logger.Infof("Received line %q", line)
+
+			s.mu.Lock()
+			connection.Lines = append(connection.Lines, line)
+			s.mu.Unlock()
+		default:
+			s.logger.Infof("Sent %s", formatBytes(step.send))
+
+			if _, err := conn.Write(step.send); err != nil {
+				s.logger.Debugf("Failed to send: %v", err)
+				return
+			}
+		}
+	}
+}
+
+// defaultReadTimeout is how long ReadLine steps wait for the client, so that stuck clients don't leak goroutines
+const defaultReadTimeout = 30 * time.Second
+
+// recordingReader records everything read from conn in the connection
+type recordingReader struct {
+	conn       net.Conn
+	server     *TCPServer
+	connection *Connection
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.conn.Read(p)
+
+	if n > 0 {
+		r.server.mu.Lock()
+		r.connection.Received = append(r.connection.Received, p[:n]...)
+		r.server.mu.Unlock()
+	}
+
+	return n, err
+}
+
+// serverLogger returns a copy of l with a "stub-server" prefix, or a logger that doesn't print anything if l is nil
+func serverLogger(l *logger.Logger) *logger.Logger {
+	if l == nil {
+		return logger.GetQuietLogger("")
+	}
+
+	cloned := l.Clone()
+	cloned.PushSecondaryPrefix("stub-server")
+	return cloned
+}
+
+func waitFor(timeout time.Duration, isDone func() bool, timeoutError func() error) error {
+	deadline := time.Now().Add(timeout)
+
+	for !isDone() {
+		if time.Now().After(deadline) {
+			return timeoutError()
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return nil
+}
+
+func hostOf(address string) string {
+	host, _, _ := net.SplitHostPort(address)
+	return host
+}
+
+func portOf(address string) string {
+	_, port, _ := net.SplitHostPort(address)
+	return port
+}
+
+func formatBytes(data []byte) string {
+	return inspectable_byte_string.NewInspectableByteString(data).FormattedString()
+}
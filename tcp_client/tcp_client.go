@@ -0,0 +1,373 @@
+package tcp_client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bootllm/tester-utils/inspectable_byte_string"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// DefaultTimeout is the default time to wait for a response in Expect* methods
+const DefaultTimeout = 2 * time.Second
+
+// Client is a TCP client that talks to the program under test.
+//
+// All traffic is logged through the given Logger (nothing is logged if it's nil). Expect* methods read from the connection until the expectation is
+// met, a mismatch is found or Timeout expires.
+//
+//	client, err := tcp_client.Connect("localhost:6379", harness.Logger)
+//	if err != nil {
+//	    return err
+//	}
+//	defer client.Close()
+//
+//	if err := client.Send([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+//	    return err
+//	}
+//	return client.ExpectExactBytes([]byte("+PONG\r\n"))
+type Client struct {
+	// Timeout is the maximum time Expect* methods wait for data. Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	conn   net.Conn
+	logger *logger.Logger
+
+	// unreadBytes holds bytes that have been received but not consumed by an Expect* method yet
+	unreadBytes []byte
+}
+
+// Connect connects to a TCP server at address (e.g. "localhost:6379")
+func Connect(address string, logger *logger.Logger) (*Client, error) {
+	return connect("tcp", address, logger)
+}
+
+// ConnectUnix connects to a program listening on a Unix domain socket at path
+func ConnectUnix(path string, logger *logger.Logger) (*Client, error) {
+	return connect("unix", path, logger)
+}
+
+func connect(network string, address string, l *logger.Logger) (*Client, error) {
+	l = quietIfNil(l)
+	l.Debugf("Connecting to %s...", address)
+
+	conn, err := net.DialTimeout(network, address, DefaultTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", address, err)
+	}
+
+	return NewClient(conn, l), nil
+}
+
+// NewClient wraps an existing connection
+func NewClient(conn net.Conn, logger *logger.Logger) *Client {
+	return &Client{
+		Timeout: DefaultTimeout,
+		conn:    conn,
+		logger:  quietIfNil(logger),
+	}
+}
+
+// quietIfNil returns l, or a logger that doesn't print anything if l is nil
+func quietIfNil(l *logger.Logger) *logger.Logger {
+	if l == nil {
+		return logger.GetQuietLogger("")
+	}
+
+	return l
+}
+
+// Conn returns the underlying connection
+func (c *Client) Conn() net.Conn {
+	return c.conn
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Send writes data to the connection
+func (c *Client) Send(data []byte) error {
+	c.logger.Infof("Sent %s", formatBytes(data))
+
+	if _, err := c.conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send %s: %v", formatBytes(data), err)
+	}
+
+	return nil
+}
+
+// SendLine writes line followed by "\n" to the connection
+func (c *Client) SendLine(line string) error {
+	return c.Send([]byte(line + "\n"))
+}
+
+// ExpectExactBytes reads len(expected) bytes and checks that they match expected exactly.
+//
+// On mismatch, the error highlights the offset of the first byte that differs.
+func (c *Client) ExpectExactBytes(expected []byte) error {
+	err := c.readUntil(func(received []byte) bool {
+		return len(received) >= len(expected) || firstMismatchOffset(received, expected) != -1
+	})
+
+	received := c.unreadBytes[:min(len(c.unreadBytes), len(expected))]
+
+	if offset := firstMismatchOffset(received, expected); offset != -1 {
+		return &MismatchError{
+			Expected: expected,
+			Received: c.unreadBytes,
+			Offset:   offset,
+		}
+	}
+
+	if err != nil {
+		return c.wrapReadError(err, fmt.Sprintf("waiting for %s", formatBytes(expected)))
+	}
+
+	c.consume(len(expected))
+	c.logger.Successf("Received %s", formatBytes(expected))
+	return nil
+}
+
+// ExpectLine reads a line (terminated by "\n" or "\r\n") and checks that it is equal to expected.
+func (c *Client) ExpectLine(expected string) error {
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+
+	if line != expected {
+		return NewMismatchError([]byte(expected), []byte(line))
+	}
+
+	c.logger.Successf("Received line %q", line)
+	return nil
+}
+
+// ExpectRegex reads a line (terminated by "\n" or "\r\n") and checks that it matches pattern.
+func (c *Client) ExpectRegex(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid regex pattern: %v", err)
+	}
+
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+
+	if !re.MatchString(line) {
+		return fmt.Errorf("expected line to match %q, got %q", pattern, line)
+	}
+
+	c.logger.Successf("Received line %q", line)
+	return nil
+}
+
+// ExpectClosed checks that the server closes the connection without sending any more data.
+func (c *Client) ExpectClosed() error {
+	err := c.readUntil(func(received []byte) bool {
+		return len(received) > 0
+	})
+
+	if len(c.unreadBytes) > 0 {
+		return fmt.Errorf("expected connection to be closed, received %s", formatBytes(c.unreadBytes))
+	}
+
+	if errors.Is(err, net.ErrClosed) || isEOF(err) {
+		c.logger.Successf("Connection closed")
+		return nil
+	}
+
+	if err != nil {
+		return c.wrapReadError(err, "waiting for connection to be closed")
+	}
+
+	return nil
+}
+
+// ReadFrame reads until decode reports a complete frame, and consumes the number of bytes it returns.
+//
+// decode is called with all unread bytes every time more data arrives. It must return complete=false if more data is
+// needed, or an error if the data is invalid. This is useful for implementing protocol-specific readers:
+//
+//	err := client.ReadFrame(func(received []byte) (int, bool, error) {
+//	    value, n, err := resp.Decode(received)
+//	    ...
+//	})
+func (c *Client) ReadFrame(decode func(received []byte) (bytesRead int, complete bool, err error)) error {
+	var bytesRead int
+	var decodeErr error
+
+	readErr := c.readUntil(func(received []byte) bool {
+		if len(received) == 0 {
+			return false
+		}
+
+		var complete bool
+		bytesRead, complete, decodeErr = decode(received)
+		return complete || decodeErr != nil
+	})
+
+	if decodeErr != nil {
+		return decodeErr
+	}
+
+	if readErr != nil {
+		return c.wrapReadError(readErr, "waiting for a complete response")
+	}
+
+	c.consume(bytesRead)
+	return nil
+}
+
+// readLine reads up to and including the next "\n", and returns the line without the line ending.
+func (c *Client) readLine() (string, error) {
+	err := c.readUntil(func(received []byte) bool {
+		return bytes.IndexByte(received, '\n') != -1
+	})
+
+	index := bytes.IndexByte(c.unreadBytes, '\n')
+	if index == -1 {
+		return "", c.wrapReadError(err, "waiting for a line")
+	}
+
+	line := string(c.unreadBytes[:index])
+	c.consume(index + 1)
+
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// readUntil reads from the connection until isDone returns true for the unread bytes, or until an error
+// (including a timeout) occurs.
+func (c *Client) readUntil(isDone func(received []byte) bool) error {
+	deadline := time.Now().Add(c.Timeout)
+	buf := make([]byte, 4096)
+
+	for !isDone(c.unreadBytes) {
+		if err := c.conn.SetReadDeadline(deadline); err != nil {
+			return err
+		}
+
+		n, err := c.conn.Read(buf)
+		if n > 0 {
+			c.logger.Debugf("Received bytes: %s", formatBytes(buf[:n]))
+			c.unreadBytes = append(c.unreadBytes, buf[:n]...)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) consume(n int) {
+	c.unreadBytes = c.unreadBytes[n:]
+}
+
+// wrapReadError converts low-level read errors into user-friendly ones, including any bytes received so far
+func (c *Client) wrapReadError(err error, action string) error {
+	var message string
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		message = fmt.Sprintf("timed out after %v %s", c.Timeout, action)
+	case isEOF(err):
+		message = fmt.Sprintf("connection closed by server while %s", action)
+	default:
+		message = fmt.Sprintf("error while %s: %v", action, err)
+	}
+
+	if len(c.unreadBytes) > 0 {
+		message += fmt.Sprintf("\nReceived so far: %s", formatBytes(c.unreadBytes))
+	} else {
+		message += "\nNo bytes received."
+	}
+
+	return errors.New(message)
+}
+
+// MismatchError is returned when received bytes don't match the expected bytes
+type MismatchError struct {
+	Expected []byte
+	Received []byte
+
+	// Offset is the index of the first byte that doesn't match
+	Offset int
+}
+
+func (e *MismatchError) Error() string {
+	received := inspectable_byte_string.NewInspectableByteString(e.Received)
+	expected := inspectable_byte_string.NewInspectableByteString(e.Expected)
+
+	// Both strings are truncated around the same offset, so the highlighted byte lines up with the expected one
+	return strings.Join([]string{
+		"Expected: " + expected.TruncateAroundOffset(e.Offset).FormattedString(),
+		received.FormatWithHighlightedOffset(e.Offset, describeMismatch(e.Expected, e.Received, e.Offset), "Received: ", ""),
+	}, "\n")
+}
+
+// NewMismatchError returns a MismatchError pointing at the first byte where received differs from expected.
+//
+// Returns nil if received and expected are equal.
+func NewMismatchError(expected []byte, received []byte) *MismatchError {
+	if bytes.Equal(expected, received) {
+		return nil
+	}
+
+	offset := firstMismatchOffset(received, expected)
+	if offset == -1 {
+		offset = len(received)
+	}
+
+	return &MismatchError{
+		Expected: expected,
+		Received: received,
+		Offset:   offset,
+	}
+}
+
+// describeMismatch returns a short description of the mismatch at offset
+func describeMismatch(expected []byte, received []byte, offset int) string {
+	if offset >= len(expected) {
+		return "unexpected extra bytes"
+	}
+
+	if offset >= len(received) {
+		return fmt.Sprintf("expected %q here", expected[offset:offset+1])
+	}
+
+	return fmt.Sprintf("expected %q, got %q", expected[offset:offset+1], received[offset:offset+1])
+}
+
+// firstMismatchOffset returns the index of the first byte in received that doesn't match expected, or -1 if
+// received is a prefix of expected.
+func firstMismatchOffset(received []byte, expected []byte) int {
+	for i := range received {
+		if i >= len(expected) || received[i] != expected[i] {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func formatBytes(data []byte) string {
+	return inspectable_byte_string.NewInspectableByteString(data).FormattedString()
+}
+
+func isEOF(err error) bool {
+	return errors.Is(err, io.EOF)
+}
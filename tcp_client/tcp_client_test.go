@@ -0,0 +1,171 @@
+package tcp_client
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer starts a TCP server that runs handler for the first connection
+func startServer(t *testing.T, handler func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+func connectTo(t *testing.T, address string) *Client {
+	client, err := Connect(address, logger.GetLogger(true, "[test] "))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func echoHandler(conn net.Conn) {
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		conn.Write(buf[:n])
+	}
+}
+
+func TestExpectExactBytes(t *testing.T) {
+	client := connectTo(t, startServer(t, echoHandler))
+
+	require.NoError(t, client.Send([]byte("+PONG\r\n")))
+	assert.NoError(t, client.ExpectExactBytes([]byte("+PONG\r\n")))
+}
+
+func TestExpectExactBytes_Mismatch(t *testing.T) {
+	client := connectTo(t, startServer(t, echoHandler))
+
+	require.NoError(t, client.Send([]byte("+PANG\r\n")))
+	err := client.ExpectExactBytes([]byte("+PONG\r\n"))
+
+	var mismatchError *MismatchError
+	require.ErrorAs(t, err, &mismatchError)
+	assert.Equal(t, 2, mismatchError.Offset)
+	assert.Equal(t, "Expected: \"+PONG\\r\\n\"\nReceived: \"+PANG\\r\\n\"\n             ^ expected \"O\", got \"A\"", err.Error())
+}
+
+func TestExpectExactBytes_Timeout(t *testing.T) {
+	client := connectTo(t, startServer(t, func(conn net.Conn) {
+		conn.Write([]byte("+PO"))
+		time.Sleep(time.Second)
+	}))
+	client.Timeout = 200 * time.Millisecond
+
+	err := client.ExpectExactBytes([]byte("+PONG\r\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Contains(t, err.Error(), `Received so far: "+PO"`)
+}
+
+func TestExpectLine(t *testing.T) {
+	client := connectTo(t, startServer(t, func(conn net.Conn) {
+		conn.Write([]byte("hello\r\nworld\nfoo 123\n"))
+		time.Sleep(time.Second)
+	}))
+
+	assert.NoError(t, client.ExpectLine("hello"))
+	assert.NoError(t, client.ExpectLine("world"))
+	assert.NoError(t, client.ExpectRegex(`^foo \d+$`))
+}
+
+func TestExpectLine_Mismatch(t *testing.T) {
+	client := connectTo(t, startServer(t, func(conn net.Conn) {
+		conn.Write([]byte("hell\n"))
+		time.Sleep(time.Second)
+	}))
+
+	err := client.ExpectLine("hello")
+	var mismatchError *MismatchError
+	require.ErrorAs(t, err, &mismatchError)
+	assert.Equal(t, 4, mismatchError.Offset)
+	assert.Contains(t, err.Error(), `expected "o" here`)
+}
+
+func TestExpectRegex_Mismatch(t *testing.T) {
+	client := connectTo(t, startServer(t, func(conn net.Conn) {
+		conn.Write([]byte("foo bar\n"))
+		time.Sleep(time.Second)
+	}))
+
+	err := client.ExpectRegex(`^foo \d+$`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `got "foo bar"`)
+}
+
+func TestExpectClosed(t *testing.T) {
+	client := connectTo(t, startServer(t, func(conn net.Conn) {}))
+	assert.NoError(t, client.ExpectClosed())
+
+	client = connectTo(t, startServer(t, func(conn net.Conn) {
+		conn.Write([]byte("bye\n"))
+	}))
+	err := client.ExpectClosed()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `"bye\n"`)
+}
+
+func TestConnect_Refused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err = Connect(address, logger.GetLogger(false, ""))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect")
+}
+
+func TestConnect_NilLogger(t *testing.T) {
+	client, err := Connect(startServer(t, echoHandler), nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.Send([]byte("ping\n")))
+	assert.NoError(t, client.ExpectLine("ping"))
+}
+
+func TestConnectUnix(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "server.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		echoHandler(conn)
+	}()
+
+	client, err := ConnectUnix(socketPath, logger.GetLogger(true, "[test] "))
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.SendLine("hello"))
+	assert.NoError(t, client.ExpectLine("hello"))
+}
@@ -0,0 +1,153 @@
+package test_case_harness
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// readinessPollInterval is how often readiness probes are retried
+const readinessPollInterval = 50 * time.Millisecond
+
+// maxProgramOutputInReadinessError limits how much of the program's output is included in readiness errors
+const maxProgramOutputInReadinessError = 2000
+
+// WaitForPort waits until the program accepts TCP connections on the given port (on localhost).
+//
+//	if err := harness.Executable.Start(); err != nil {
+//	    return err
+//	}
+//	if err := harness.WaitForPort(6379, 5*time.Second); err != nil {
+//	    return err
+//	}
+func (s *TestCaseHarness) WaitForPort(port int, timeout time.Duration) error {
+	address := net.JoinHostPort("localhost", fmt.Sprintf("%d", port))
+
+	return s.waitUntilReady(fmt.Sprintf("program to accept TCP connections on port %d", port), timeout, func() bool {
+		conn, err := net.DialTimeout("tcp", address, readinessPollInterval)
+		if err != nil {
+			return false
+		}
+
+		conn.Close()
+		return true
+	})
+}
+
+// WaitForUnixSocket waits until a Unix socket file exists at path and accepts connections.
+//
+// Relative paths are resolved against SubmissionDir.
+func (s *TestCaseHarness) WaitForUnixSocket(path string, timeout time.Duration) error {
+	if !filepath.IsAbs(path) {
+		path = s.FilePath(path)
+	}
+
+	return s.waitUntilReady(fmt.Sprintf("program to listen on Unix socket %s", path), timeout, func() bool {
+		fileInfo, err := os.Stat(path)
+		if err != nil || fileInfo.Mode()&os.ModeSocket == 0 {
+			return false
+		}
+
+		conn, err := net.DialTimeout("unix", path, readinessPollInterval)
+		if err != nil {
+			return false
+		}
+
+		conn.Close()
+		return true
+	})
+}
+
+// WaitForStdoutLine waits until the program prints a line to stdout matching the regular expression pattern.
+func (s *TestCaseHarness) WaitForStdoutLine(pattern string, timeout time.Duration) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid regex pattern: %v", err)
+	}
+
+	return s.waitUntilReady(fmt.Sprintf("program to print a line matching %q", pattern), timeout, func() bool {
+		stdout := strings.ReplaceAll(string(s.Executable.StdoutSoFar()), "\r\n", "\n")
+
+		for _, line := range strings.Split(stdout, "\n") {
+			if re.MatchString(line) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// WaitForHTTP waits until an HTTP GET request to url gets a response with a status code below 500.
+func (s *TestCaseHarness) WaitForHTTP(url string, timeout time.Duration) error {
+	client := &http.Client{Timeout: 500 * time.Millisecond}
+
+	return s.waitUntilReady(fmt.Sprintf("program to respond to HTTP requests at %s", url), timeout, func() bool {
+		response, err := client.Get(url)
+		if err != nil {
+			return false
+		}
+
+		response.Body.Close()
+		return response.StatusCode < 500
+	})
+}
+
+// waitUntilReady polls isReady until it returns true, the program exits or the timeout expires.
+func (s *TestCaseHarness) waitUntilReady(description string, timeout time.Duration, isReady func() bool) error {
+	if s.Logger != nil {
+		s.Logger.Debugf("Waiting for %s...", description)
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if isReady() {
+			return nil
+		}
+
+		if s.Executable.HasExited() {
+			return fmt.Errorf("program exited before it was ready (waiting for %s)%s", description, s.formatProgramOutputSoFar())
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %v waiting for %s%s", timeout, description, s.formatProgramOutputSoFar())
+		}
+
+		time.Sleep(readinessPollInterval)
+	}
+}
+
+// formatProgramOutputSoFar formats the program's output so far for inclusion in an error message
+func (s *TestCaseHarness) formatProgramOutputSoFar() string {
+	stdout := s.Executable.StdoutSoFar()
+	stderr := s.Executable.StderrSoFar()
+
+	if len(stdout) == 0 && len(stderr) == 0 {
+		return "\nThe program didn't produce any output."
+	}
+
+	result := ""
+	if len(stdout) > 0 {
+		result += "\nProgram stdout so far:\n" + truncateFromStart(string(stdout), maxProgramOutputInReadinessError)
+	}
+	if len(stderr) > 0 {
+		result += "\nProgram stderr so far:\n" + truncateFromStart(string(stderr), maxProgramOutputInReadinessError)
+	}
+
+	return strings.TrimRight(result, "\n")
+}
+
+// truncateFromStart keeps the last maxLength bytes of s, since the most recent output is the most relevant
+func truncateFromStart(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+
+	return "(truncated)..." + s[len(s)-maxLength:]
+}
@@ -0,0 +1,104 @@
+package test_case_harness
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bootllm/tester-utils/executable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startHarnessWithScript(t *testing.T, script string) *TestCaseHarness {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "program.sh")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+
+	harness := &TestCaseHarness{
+		SubmissionDir: dir,
+		Executable:    executable.NewExecutable(path),
+	}
+	require.NoError(t, harness.Executable.Start())
+	t.Cleanup(func() { harness.Executable.Kill() })
+
+	return harness
+}
+
+func TestWaitForStdoutLine(t *testing.T) {
+	harness := startHarnessWithScript(t, `#!/bin/bash
+echo "booting"
+sleep 0.2
+echo "Listening on port 1234"
+sleep 10
+`)
+
+	err := harness.WaitForStdoutLine(`^Listening on port \d+$`, 2*time.Second)
+	assert.NoError(t, err)
+}
+
+func TestWaitForStdoutLine_Timeout(t *testing.T) {
+	harness := startHarnessWithScript(t, `#!/bin/bash
+echo "booting"
+sleep 10
+`)
+
+	err := harness.WaitForStdoutLine(`^ready$`, 300*time.Millisecond)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Contains(t, err.Error(), "booting")
+}
+
+func TestWaitForPort_ProgramExits(t *testing.T) {
+	harness := startHarnessWithScript(t, `#!/bin/bash
+echo "failed to bind" >&2
+exit 1
+`)
+
+	err := harness.WaitForPort(1, 2*time.Second)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exited before it was ready")
+	assert.Contains(t, err.Error(), "failed to bind")
+}
+
+func TestWaitForPort(t *testing.T) {
+	harness := startHarnessWithScript(t, "#!/bin/bash\nsleep 10\n")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, harness.WaitForPort(port, time.Second))
+}
+
+func TestWaitForUnixSocket(t *testing.T) {
+	harness := startHarnessWithScript(t, "#!/bin/bash\nsleep 10\n")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		listener, err := net.Listen("unix", harness.FilePath("server.sock"))
+		if err == nil {
+			t.Cleanup(func() { listener.Close() })
+		}
+	}()
+
+	assert.NoError(t, harness.WaitForUnixSocket("server.sock", 2*time.Second))
+}
+
+func TestWaitForHTTP(t *testing.T) {
+	harness := startHarnessWithScript(t, "#!/bin/bash\nsleep 10\n")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &http.Server{Handler: http.NotFoundHandler()}
+	go server.Serve(listener)
+	defer server.Close()
+
+	url := fmt.Sprintf("http://%s/", listener.Addr().String())
+	assert.NoError(t, harness.WaitForHTTP(url, time.Second))
+}
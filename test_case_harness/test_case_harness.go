@@ -22,6 +22,9 @@ import (
 //	   return err
 //	}
 //	harness.RegisterTeardownFunc(func() { harness.Executable.Kill() })
+//	if err := harness.WaitForPort(6379, 5*time.Second); err != nil {
+//	   return err
+//	}
 //
 // For scripts that run and exit (like a Git command):
 //
@@ -37,7 +40,12 @@ type TestCaseHarness struct {
 	// Use this for direct file access without needing your_program.sh.
 	SubmissionDir string
 
-	// Executable is the program to be tested (may point to SubmissionDir if no ExecutableFileName).
+	// Language is the language of the submission (e.g. "c", "python", "go"), declared in bootllm.yml or auto-detected.
+	// Empty if unknown. An auto-detected language doesn't change how the submission is built or run.
+	Language string
+
+	// Executable is the program to be tested (may point to SubmissionDir if no ExecutableFileName). If bootllm.yml
+	// declares a run command, Executable runs it in SubmissionDir.
 	Executable *executable.Executable
 
 	// teardownFuncs are run once the error has been reported to the user
@@ -0,0 +1,104 @@
+package test_runner
+
+import (
+	"sync"
+
+	"github.com/bootllm/tester-utils/i18n"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// LogEntry is a message logged while a step ran
+type LogEntry struct {
+	logger.Entry
+
+	// IsProgramOutput is true for output of the user's program, false for logs from the tester
+	IsProgramOutput bool
+}
+
+// stepLogs captures the logs of a step, and forwards them to display. When buffered, nothing is displayed until
+// flush, so that program output can be collapsed if the step passes.
+type stepLogs struct {
+	mutex      sync.Mutex
+	display    logger.Sink
+	isBuffered bool
+	entries    []LogEntry
+}
+
+func newStepLogs(display logger.Sink, isBuffered bool) *stepLogs {
+	return &stepLogs{display: display, isBuffered: isBuffered}
+}
+
+// testerSink returns the sink for the tester's logs
+func (s *stepLogs) testerSink() logger.Sink {
+	return stepLogsSink{logs: s, isProgramOutput: false}
+}
+
+// programSink returns the sink for the output of the user's program
+func (s *stepLogs) programSink() logger.Sink {
+	return stepLogsSink{logs: s, isProgramOutput: true}
+}
+
+func (s *stepLogs) write(entry LogEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries = append(s.entries, entry)
+
+	if !s.isBuffered {
+		s.display.Write(entry.Entry)
+	}
+}
+
+// flush displays buffered logs. If collapseProgramOutput is true, program output is replaced by a single line saying
+// how many lines were hidden.
+func (s *stepLogs) flush(collapseProgramOutput bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isBuffered {
+		return
+	}
+
+	hiddenLines := 0
+	for _, entry := range s.entries {
+		if entry.IsProgramOutput {
+			hiddenLines++
+		}
+	}
+
+	isSummaryShown := false
+	for _, entry := range s.entries {
+		if !collapseProgramOutput || !entry.IsProgramOutput {
+			s.display.Write(entry.Entry)
+			continue
+		}
+
+		// The summary takes the place of the first line of program output
+		if !isSummaryShown {
+			summary := entry.Entry
+			summary.Level = logger.LevelInfo
+			summary.Message = i18n.T("(%d lines of output hidden since the stage passed, set debug: true in bootllm.yml to show them)", hiddenLines)
+			s.display.Write(summary)
+			isSummaryShown = true
+		}
+	}
+
+	s.isBuffered = false
+}
+
+// logEntries returns a copy of the captured logs
+func (s *stepLogs) logEntries() []LogEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]LogEntry{}, s.entries...)
+}
+
+type stepLogsSink struct {
+	logs            *stepLogs
+	isProgramOutput bool
+}
+
+func (s stepLogsSink) Write(entry logger.Entry) {
+	s.logs.write(LogEntry{Entry: entry, IsProgramOutput: s.isProgramOutput})
+}
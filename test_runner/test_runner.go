@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bootllm/tester-utils/events"
 	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/hints"
+	"github.com/bootllm/tester-utils/i18n"
 	"github.com/bootllm/tester-utils/logger"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -25,7 +28,14 @@ type TestRunnerStep struct {
 type TestRunner struct {
 	isQuiet       bool   // Used for anti-cheat tests, where we only want Critical logs to be emitted
 	submissionDir string // The directory containing the student's submission
+	language      string // The language of the student's submission, if known
 	steps         []TestRunnerStep
+
+	hintRules        []hints.Rule   // Hints for all steps, checked after TestCase.Hints
+	previousFailures map[string]int // Consecutive failures of each test case (by slug) in previous runs
+
+	programLogPrefix            string // Prefix for the output of the user's program. Empty to keep the executable's logger.
+	shouldCollapseProgramOutput bool   // Hide the program output of passed steps, unless in debug mode
 }
 
 func NewTestRunner(steps []TestRunnerStep, submissionDir string) TestRunner {
@@ -39,21 +49,94 @@ func NewQuietTestRunner(steps []TestRunnerStep, submissionDir string) TestRunner
 	return TestRunner{isQuiet: true, steps: steps, submissionDir: submissionDir}
 }
 
+// WithLanguage returns a copy of the runner that exposes language to test cases through TestCaseHarness.Language
+func (r TestRunner) WithLanguage(language string) TestRunner {
+	r.language = language
+	return r
+}
+
+// WithHints returns a copy of the runner that shows hints from rules (after the test case's own hints) when a step
+// fails. previousFailures has the number of consecutive failures of each test case (by slug) in previous runs, so
+// that hints are revealed progressively.
+func (r TestRunner) WithHints(rules []hints.Rule, previousFailures map[string]int) TestRunner {
+	r.hintRules = rules
+	r.previousFailures = previousFailures
+	return r
+}
+
+// WithProgramOutput returns a copy of the runner that logs the output of the user's program (TestCaseHarness.Executable)
+// with prefix, captured along with the step's logs (see StepResult.Logs). If collapseWhenPassed is true, the program
+// output of steps that pass is replaced by a single line, unless in debug mode.
+func (r TestRunner) WithProgramOutput(prefix string, collapseWhenPassed bool) TestRunner {
+	r.programLogPrefix = prefix
+	r.shouldCollapseProgramOutput = collapseWhenPassed
+	return r
+}
+
+// StepResult is the outcome of a single step
+type StepResult struct {
+	Step TestRunnerStep
+
+	// Err is the error returned by the test case (or a timeout error). nil if the step passed.
+	Err error
+
+	// Duration is how long the test case took to run
+	Duration time.Duration
+
+	// Logs are the messages logged while the step ran, including the output of the user's program
+	Logs []LogEntry
+}
+
+// Passed returns true if the step passed
+func (r StepResult) Passed() bool {
+	return r.Err == nil
+}
+
 // Run runs all tests in a stageRunner
 func (r TestRunner) Run(isDebug bool, executable *executable.Executable) bool {
+	results := r.RunWithResults(isDebug, executable)
+
+	return len(results) == len(r.steps) && (len(results) == 0 || results[len(results)-1].Passed())
+}
+
+// RunWithResults runs all tests like Run, and returns the result of every step that ran. Steps after the first
+// failing step aren't run.
+func (r TestRunner) RunWithResults(isDebug bool, executable *executable.Executable) []StepResult {
+	results := []StepResult{}
+
 	for index, step := range r.steps {
-		if index != 0 {
+		if index != 0 && !events.Enabled() {
 			fmt.Println("")
 		}
 
+		if !r.isQuiet {
+			events.Emit(events.Event{
+				Type:      events.TypeStageStarted,
+				Stage:     step.TestCase.Slug,
+				Title:     step.Title,
+				LogPrefix: step.TesterLogPrefix,
+			})
+		}
+
+		stepLogger := r.getLoggerForStep(isDebug, step)
+		stepLogs := newStepLogs(stepLogger.Sink(), r.shouldCollapseProgramOutput && !isDebug)
+
+		stepExecutable := executable.Clone()
+		if r.programLogPrefix != "" {
+			stepExecutable.SetLoggerFunc(logger.GetLoggerWithSink(true, r.programLogPrefix, stepLogs.programSink()).Plainln)
+		}
+
 		testCaseHarness := test_case_harness.TestCaseHarness{
-			Logger:        r.getLoggerForStep(isDebug, step),
+			Logger:        stepLogger.WithSink(stepLogs.testerSink()),
 			SubmissionDir: r.submissionDir,
-			Executable:    executable.Clone(),
+			Language:      r.language,
+			Executable:    stepExecutable,
 		}
 
 		logger := testCaseHarness.Logger
-		logger.Infof("Running tests for %s", step.Title)
+		logger.Infoln(i18n.T("Running tests for %s", step.Title))
+
+		startTime := time.Now()
 
 		stepResultChannel := make(chan error, 1)
 		go func() {
@@ -68,23 +151,38 @@ func (r TestRunner) Run(isDebug bool, executable *executable.Executable) bool {
 		case stageErr := <-stepResultChannel:
 			err = stageErr
 		case <-time.After(timeout):
-			err = fmt.Errorf("timed out, test exceeded %d seconds", int64(timeout.Seconds()))
+			err = i18n.Errorf("timed out, test exceeded %d seconds", int64(timeout.Seconds()))
 		}
 
+		duration := time.Since(startTime)
+
+		var revealedHints []string
 		if err != nil {
-			r.reportTestError(err, isDebug, logger)
+			revealedHints = r.reportTestError(step, err, isDebug, logger)
 		} else {
-			logger.Successf("Test passed.")
+			logger.Successln(i18n.T("Test passed."))
 		}
 
 		testCaseHarness.RunTeardownFuncs()
+		stepLogs.flush(err == nil)
+
+		results = append(results, StepResult{Step: step, Err: err, Duration: duration, Logs: stepLogs.logEntries()})
+
+		if err != nil && !r.isQuiet {
+			events.Emit(events.Event{
+				Type:       events.TypeStageFailed,
+				Error:      err.Error(),
+				Hints:      revealedHints,
+				DurationMs: duration.Milliseconds(),
+			})
+		}
 
 		if err != nil {
-			return false
+			break
 		}
 	}
 
-	return true
+	return results
 }
 
 func (r TestRunner) getLoggerForStep(isDebug bool, step TestRunnerStep) *logger.Logger {
@@ -95,9 +193,38 @@ func (r TestRunner) getLoggerForStep(isDebug bool, step TestRunnerStep) *logger.
 	}
 }
 
-func (r TestRunner) reportTestError(err error, isDebug bool, logger *logger.Logger) {
+// reportTestError logs err and the hint for it. Returns the hint levels shown.
+func (r TestRunner) reportTestError(step TestRunnerStep, err error, isDebug bool, logger *logger.Logger) []string {
 	logger.Errorf("%s", err)
-	logger.Errorf("Test failed")
+	logger.Errorln(i18n.T("Test failed"))
+	return r.reportHint(step, err, logger)
+}
+
+// reportHint shows the hint for err, if any. One more level is revealed every time the step fails in a row. Returns
+// the levels shown.
+func (r TestRunner) reportHint(step TestRunnerStep, err error, logger *logger.Logger) []string {
+	rules := append(append([]hints.Rule{}, step.TestCase.Hints...), r.hintRules...)
+
+	hint := hints.For(err, rules...)
+	if hint.IsEmpty() {
+		return nil
+	}
+
+	revealed := hint.Reveal(r.previousFailures[step.TestCase.Slug] + 1)
+
+	for i, level := range revealed {
+		if len(hint.Levels) == 1 {
+			logger.Hintln(i18n.T("Hint: %s", level))
+		} else {
+			logger.Hintln(i18n.T("Hint %d/%d: %s", i+1, len(hint.Levels), level))
+		}
+	}
+
+	if len(revealed) < len(hint.Levels) {
+		logger.Hintln(i18n.T("Another hint will be shown if this stage fails again."))
+	}
+
+	return revealed
 }
 
 // Fuck you, go
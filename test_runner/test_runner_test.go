@@ -0,0 +1,89 @@
+package test_runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/test_case_harness"
+	"github.com/bootllm/tester-utils/tester_definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runProgramStep(harness *test_case_harness.TestCaseHarness) error {
+	harness.Logger.Infoln("before")
+	if _, err := harness.Executable.Run("-c", "echo one; echo two"); err != nil {
+		return err
+	}
+	harness.Logger.Infoln("after")
+
+	return nil
+}
+
+func TestStepLogs(t *testing.T) {
+	steps := []TestRunnerStep{
+		{TestCase: tester_definition.TestCase{Slug: "program", TestFunc: runProgramStep}, TesterLogPrefix: "stage-1", Title: "Program"},
+		{TestCase: tester_definition.TestCase{Slug: "fail", TestFunc: func(harness *test_case_harness.TestCaseHarness) error {
+			return errors.New("oops")
+		}}, TesterLogPrefix: "stage-2", Title: "Fail"},
+	}
+
+	results := NewTestRunner(steps, t.TempDir()).
+		WithProgramOutput("[your_program] ", false).
+		RunWithResults(false, executable.NewExecutable("sh"))
+	require.Len(t, results, 2)
+
+	messages := []string{}
+	for _, entry := range results[0].Logs {
+		messages = append(messages, entry.Prefix+entry.Message)
+		assert.Equal(t, entry.Prefix == "[your_program] ", entry.IsProgramOutput)
+	}
+	assert.Equal(t, []string{
+		"[stage-1] Running tests for Program",
+		"[stage-1] before",
+		"[your_program] one",
+		"[your_program] two",
+		"[stage-1] after",
+		"[stage-1] Test passed.",
+	}, messages)
+
+	lastEntry := results[1].Logs[len(results[1].Logs)-1]
+	assert.Equal(t, logger.LevelError, lastEntry.Level)
+	assert.Equal(t, "Test failed", lastEntry.Message)
+}
+
+func TestStepLogsFlush(t *testing.T) {
+	entry := func(message string, isProgramOutput bool) LogEntry {
+		return LogEntry{Entry: logger.Entry{Level: logger.LevelPlain, Message: message}, IsProgramOutput: isProgramOutput}
+	}
+
+	// Buffered logs are displayed on flush, with program output collapsed into one line
+	display := logger.NewMemorySink()
+	logs := newStepLogs(display, true)
+	for _, e := range []LogEntry{entry("start", false), entry("one", true), entry("two", true), entry("end", false)} {
+		logs.write(e)
+	}
+
+	assert.Empty(t, display.Entries())
+	logs.flush(true)
+	assert.Equal(t, "start\n(2 lines of output hidden since the stage passed, set debug: true in bootllm.yml to show them)\nend\n", display.String())
+	assert.Len(t, logs.logEntries(), 4, "captured logs aren't collapsed")
+
+	// Everything is displayed when not collapsing, and logs are displayed immediately after flush
+	display = logger.NewMemorySink()
+	logs = newStepLogs(display, true)
+	logs.write(entry("one", true))
+	logs.flush(false)
+	logs.write(entry("late", true))
+	assert.Equal(t, "one\nlate\n", display.String())
+
+	// Unbuffered logs are displayed immediately
+	display = logger.NewMemorySink()
+	logs = newStepLogs(display, false)
+	logs.write(entry("one", true))
+	assert.Equal(t, "one\n", display.String())
+	logs.flush(true)
+	assert.Equal(t, "one\n", display.String())
+}
@@ -1,15 +1,20 @@
 package tester_utils
 
 import (
-	"flag"
 	"fmt"
 	"os"
-	"strings"
+	"regexp"
+	"time"
 
+	"github.com/bootllm/tester-utils/build"
+	"github.com/bootllm/tester-utils/events"
 	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/i18n"
 	"github.com/bootllm/tester-utils/internal"
 	"github.com/bootllm/tester-utils/logger"
 	"github.com/bootllm/tester-utils/random"
+	"github.com/bootllm/tester-utils/redact"
+	"github.com/bootllm/tester-utils/run_history"
 	"github.com/bootllm/tester-utils/test_runner"
 	"github.com/bootllm/tester-utils/tester_context"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -23,82 +28,36 @@ type Tester struct {
 
 // newTester creates a Tester based on the TesterDefinition provided
 func newTester(env map[string]string, definition tester_definition.TesterDefinition) (Tester, error) {
+	redact.AddSecretsFromEnv(env)
+
+	for locale, messages := range definition.Messages {
+		i18n.Register(locale, messages)
+	}
+
 	context, err := tester_context.GetTesterContext(env, definition)
 	if err != nil {
 		if userError, ok := err.(*internal.UserError); ok {
 			return Tester{}, fmt.Errorf("%s", userError.Message)
 		}
 
-		return Tester{}, fmt.Errorf("BootLLM internal error. Error fetching tester context: %v", err)
+		return Tester{}, i18n.Errorf("BootLLM internal error. Error fetching tester context: %v", err)
 	}
 
+	i18n.SetLocale(context.Locale)
+	build.SetDebugLogger(logger.GetLogger(context.IsDebug, "[build] "))
+
 	tester := Tester{
 		context:    context,
 		definition: definition,
 	}
 
 	if err := tester.validateContext(); err != nil {
-		return Tester{}, fmt.Errorf("BootLLM internal error. Error validating tester context: %v", err)
+		return Tester{}, i18n.Errorf("BootLLM internal error. Error validating tester context: %v", err)
 	}
 
 	return tester, nil
 }
 
-// CLIArgs holds parsed command-line arguments
-type CLIArgs struct {
-	Stage   string // Stage slug to run (empty = run all)
-	Dir     string // Working directory (empty = current dir)
-	Help    bool   // Show help
-	Version bool   // Show version
-}
-
-// ParseArgs parses command-line arguments
-// Supports:
-//   - ./tester [stage]           # positional argument
-//   - ./tester --stage <slug>    # flag
-//   - ./tester -d <dir>          # specify directory
-func ParseArgs(args []string) CLIArgs {
-	result := CLIArgs{}
-
-	// Create a new FlagSet to avoid global state
-	fs := flag.NewFlagSet("tester", flag.ContinueOnError)
-	fs.StringVar(&result.Stage, "stage", "", "Stage slug to run")
-	fs.StringVar(&result.Stage, "s", "", "Stage slug to run (shorthand)")
-	fs.StringVar(&result.Dir, "dir", "", "Working directory")
-	fs.StringVar(&result.Dir, "d", "", "Working directory (shorthand)")
-	fs.BoolVar(&result.Help, "help", false, "Show help")
-	fs.BoolVar(&result.Help, "h", false, "Show help (shorthand)")
-	fs.BoolVar(&result.Version, "version", false, "Show version")
-	fs.BoolVar(&result.Version, "v", false, "Show version (shorthand)")
-
-	// Parse flags (ignore errors for unknown flags)
-	fs.Parse(args)
-
-	// If no --stage flag but there's a positional argument, use it as stage
-	if result.Stage == "" && fs.NArg() > 0 {
-		result.Stage = fs.Arg(0)
-	}
-
-	return result
-}
-
-// MergeArgsIntoEnv merges CLI args into env map (CLI args take precedence)
-func MergeArgsIntoEnv(args CLIArgs, env map[string]string) map[string]string {
-	result := make(map[string]string)
-	for k, v := range env {
-		result[k] = v
-	}
-
-	if args.Stage != "" {
-		result["BOOTLLM_STAGE"] = args.Stage
-	}
-	if args.Dir != "" {
-		result["BOOTLLM_REPOSITORY_DIR"] = args.Dir
-	}
-
-	return result
-}
-
 // Run executes the tester with command-line arguments and environment
 // This is the recommended entry point for tester main functions
 //
@@ -107,62 +66,44 @@ func MergeArgsIntoEnv(args CLIArgs, env map[string]string) map[string]string {
 //	os.Exit(tester_utils.Run(os.Args[1:], definition))
 func Run(args []string, definition tester_definition.TesterDefinition) int {
 	// Configure streaming logs if enabled by Worker
-	// When BOOTLLM_STREAM_LOGS=1, redirect stdout to stderr and disable colors
-	// This allows Worker to capture all logs through stderr for real-time streaming
+	// When BOOTLLM_STREAM_LOGS=1, logs are written to stderr as newline-delimited JSON events (see the events
+	// package). Anything else printed to stdout is also redirected to stderr, without colors.
 	if os.Getenv("BOOTLLM_STREAM_LOGS") == "1" {
+		events.Enable(os.Stderr)
 		os.Stdout = os.Stderr // Redirect stdout to stderr
 		color.NoColor = true  // Disable ANSI color codes
 	}
 
-	cliArgs := ParseArgs(args)
-
-	if cliArgs.Help {
-		printUsage(definition)
-		return 0
-	}
-
-	if cliArgs.Version {
-		fmt.Println("bcs100x-tester v0.1.0")
-		return 0
+	cliArgs, err := ParseArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\nRun 'tester --help' for usage.\n", err)
+		return ExitUsageError
 	}
 
 	// Merge CLI args into environment (CLI takes precedence)
-	env := getEnvMap()
-	env = MergeArgsIntoEnv(cliArgs, env)
-
-	return RunCLI(env, definition)
-}
+	env := MergeArgsIntoEnv(cliArgs, getEnvMap())
 
-// getEnvMap converts os.Environ() to a map
-func getEnvMap() map[string]string {
-	env := make(map[string]string)
-	for _, e := range os.Environ() {
-		pair := strings.SplitN(e, "=", 2)
-		if len(pair) == 2 {
-			env[pair[0]] = pair[1]
+	switch cliArgs.Command {
+	case CommandHelp:
+		printUsage(os.Stdout, definition)
+		return ExitSuccess
+	case CommandVersion:
+		fmt.Println("bcs100x-tester v0.1.0")
+		return ExitSuccess
+	case CommandList:
+		if err := printStageList(os.Stdout, definition, cliArgs.JSON); err != nil {
+			fmt.Println(err.Error())
+			return ExitFailure
+		}
+		return ExitSuccess
+	case CommandDoctor:
+		return runDoctor(os.Stdout, env, definition)
+	default:
+		if cliArgs.Watch {
+			return runWatch(env, definition)
 		}
-	}
-	return env
-}
 
-// printUsage prints help message
-func printUsage(definition tester_definition.TesterDefinition) {
-	fmt.Println("Usage: tester [options] [stage]")
-	fmt.Println()
-	fmt.Println("Options:")
-	fmt.Println("  -s, --stage <slug>  Run a specific stage")
-	fmt.Println("  -d, --dir <path>    Set working directory (default: current dir)")
-	fmt.Println("  -h, --help          Show this help message")
-	fmt.Println("  -v, --version       Show version")
-	fmt.Println()
-	fmt.Println("Examples:")
-	fmt.Println("  tester              # Run all stages")
-	fmt.Println("  tester hello        # Run 'hello' stage")
-	fmt.Println("  tester -s hello     # Same as above")
-	fmt.Println()
-	fmt.Println("Available stages:")
-	for _, tc := range definition.TestCases {
-		fmt.Printf("  %s\n", tc.Slug)
+		return RunCLI(env, definition)
 	}
 }
 
@@ -170,26 +111,209 @@ func printUsage(definition tester_definition.TesterDefinition) {
 // Deprecated: Use Run() instead for command-line argument support
 func RunCLI(env map[string]string, definition tester_definition.TesterDefinition) int {
 	random.Init()
+	defer build.RemoveTemporaryCache()
 
 	tester, err := newTester(env, definition)
 	if err != nil {
-		fmt.Println(err.Error())
-		return 1
+		if events.Enabled() {
+			events.Emit(events.Event{Type: events.TypeRunFinished, Status: events.StatusFailed, Error: err.Error()})
+		} else {
+			fmt.Println(redact.String(err.Error()))
+		}
+
+		return ExitFailure
 	}
 
 	tester.printDebugContext()
+	tester.printConfigWarnings()
 
 	// TODO: Validate context here instead of in NewTester?
 
-	if !tester.runStages() {
-		return 1
+	return tester.run().exitCode()
+}
+
+// runReport is the outcome of running the tester once
+type runReport struct {
+	// buildFailed is true if the build command failed, in which case no stages ran
+	buildFailed bool
+
+	// stages has the results of the stages that ran, in order. Stages after the first failure don't run.
+	stages []test_runner.StepResult
+
+	// notRun lists the stages that didn't run because the build or an earlier stage failed
+	notRun []tester_context.TesterContextTestCase
+
+	// antiCheatFailed is true if an anti-cheat stage failed
+	antiCheatFailed bool
+}
+
+func (r runReport) passed() bool {
+	if r.buildFailed || r.antiCheatFailed || len(r.notRun) > 0 {
+		return false
+	}
+
+	for _, stage := range r.stages {
+		if !stage.Passed() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r runReport) exitCode() int {
+	if r.passed() {
+		return ExitSuccess
+	}
+
+	return ExitFailure
+}
+
+// run runs the build, the stages and the anti-cheat stages, and records the results in the run history
+func (tester Tester) run() runReport {
+	// The submission is hashed before the run, like in tester_context, since the build and the stages write files
+	submissionBefore := tester.snapshotSubmission()
+	report := tester.runWithoutRecording()
+	tester.recordRunHistory(report, submissionBefore)
+	emitRunFinished(report)
+
+	return report
+}
+
+// emitRunFinished emits the run_finished event for report, in streaming mode
+func emitRunFinished(report runReport) {
+	if !events.Enabled() {
+		return
+	}
+
+	event := events.Event{Type: events.TypeRunFinished, Status: events.StatusPassed, Summary: &events.Summary{}}
+	if !report.passed() {
+		event.Status = events.StatusFailed
+	}
+
+	switch {
+	case report.buildFailed:
+		event.Error = "build failed"
+	case report.antiCheatFailed:
+		event.Error = "anti-cheat check failed"
+	}
+
+	for _, stage := range report.stages {
+		result := events.StageResult{Slug: stage.Step.TestCase.Slug, Status: events.StatusPassed, DurationMs: stage.Duration.Milliseconds()}
+		if stage.Passed() {
+			event.Summary.Passed++
+		} else {
+			result.Status = events.StatusFailed
+			event.Summary.Failed++
+		}
+
+		event.Stages = append(event.Stages, result)
+	}
+
+	for _, testCase := range report.notRun {
+		event.Stages = append(event.Stages, events.StageResult{Slug: testCase.Slug, Status: events.StatusNotRun})
+		event.Summary.NotRun++
+	}
+
+	events.Emit(event)
+}
+
+func (tester Tester) runWithoutRecording() runReport {
+	report := runReport{}
+
+	if !tester.runBuild() {
+		report.buildFailed = true
+		report.notRun = tester.context.TestCases
+		return report
+	}
+
+	report.stages = tester.runStages()
+	report.notRun = tester.context.TestCases[len(report.stages):]
+
+	if !report.passed() {
+		return report
 	}
 
 	if !tester.context.ShouldSkipAntiCheatTestCases && !tester.runAntiCheatStages() {
-		return 1
+		report.antiCheatFailed = true
+	}
+
+	return report
+}
+
+// ansiEscapeCodes matches the color codes in error messages (e.g. in diffs), which are stripped from the run history
+var ansiEscapeCodes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// snapshotSubmission hashes the submission files for the run history. Returns nil if the history isn't recorded, or
+// the files can't be read.
+func (tester Tester) snapshotSubmission() run_history.Snapshot {
+	if !tester.context.ShouldRecordRunHistory {
+		return nil
+	}
+
+	snapshot, err := run_history.TakeSnapshot(tester.context.SubmissionDir)
+	if err != nil {
+		logger.GetLogger(tester.context.IsDebug, "[tester] ").Debugf("Can't record run history: %v", err)
+		return nil
+	}
+
+	return snapshot
+}
+
+// recordRunHistory saves the results of the stages in report to the run history, for --failed and --resume. Results
+// are recorded with the hash of submissionBefore, the files before the run, minus the files the run generated. Errors
+// are only logged in debug mode, since the history is just a cache.
+func (tester Tester) recordRunHistory(report runReport, submissionBefore run_history.Snapshot) {
+	if submissionBefore == nil {
+		return
+	}
+
+	historyLogger := logger.GetLogger(tester.context.IsDebug, "[tester] ")
+
+	submissionAfter, err := run_history.TakeSnapshot(tester.context.SubmissionDir)
+	if err != nil {
+		historyLogger.Debugf("Can't record run history: %v", err)
+		return
+	}
+
+	history, err := run_history.Load(tester.context.SubmissionDir)
+	if err != nil {
+		historyLogger.Debugf("Ignoring invalid run history: %v", err)
+		history = run_history.History{}
+	}
+
+	history.GeneratedFiles = run_history.GeneratedFiles(submissionBefore, submissionAfter, history.GeneratedFiles)
+	submissionHash := submissionBefore.Hash(history.GeneratedFiles)
+
+	for _, stage := range report.stages {
+		result := run_history.StageResult{
+			Slug:           stage.Step.TestCase.Slug,
+			Status:         run_history.StatusPassed,
+			DurationMs:     stage.Duration.Milliseconds(),
+			SubmissionHash: submissionHash,
+		}
+
+		if !stage.Passed() {
+			result.Status = run_history.StatusFailed
+			result.Error = redact.String(ansiEscapeCodes.ReplaceAllString(stage.Err.Error(), ""))
+		}
+
+		history.Record(result)
+	}
+
+	for _, testCase := range report.notRun {
+		history.Record(run_history.StageResult{
+			Slug:           testCase.Slug,
+			Status:         run_history.StatusNotRun,
+			SubmissionHash: submissionHash,
+		})
 	}
 
-	return 0
+	history.FinishedAt = time.Now()
+
+	if err := history.Save(tester.context.SubmissionDir); err != nil {
+		historyLogger.Debugf("Can't record run history: %v", err)
+	}
 }
 
 // PrintDebugContext is to be run as early as possible after creating a Tester
@@ -202,15 +326,52 @@ func (tester Tester) printDebugContext() {
 	fmt.Println("")
 }
 
+// printConfigWarnings prints problems in bootllm.yml that don't stop the run, like deprecated values
+func (tester Tester) printConfigWarnings() {
+	configLogger := logger.GetLogger(tester.context.IsDebug, "[config] ")
+	for _, warning := range tester.context.ConfigWarnings {
+		configLogger.Infof("bootllm.yml %s", warning)
+	}
+}
+
+// runBuild runs the build command from bootllm.yml (if any) in the submission directory. Returns true if the build
+// succeeds.
+func (tester Tester) runBuild() bool {
+	if tester.context.BuildCommand == "" {
+		return true
+	}
+
+	buildLogger := logger.GetLogger(tester.context.IsDebug, "[build] ")
+	buildLogger.Infof("$ %s", tester.context.BuildCommand)
+
+	result, err := build.Run(build.Shell{Script: tester.context.BuildCommand}, build.Options{WorkDir: tester.context.SubmissionDir})
+	if err != nil {
+		buildLogger.Errorf("%s", err)
+		return false
+	}
+
+	for _, warning := range result.Warnings() {
+		buildLogger.Infoln(warning.String())
+	}
+
+	buildLogger.Successln(i18n.T("Build succeeded."))
+	if !events.Enabled() {
+		fmt.Println("")
+	}
+
+	return true
+}
+
 // runAntiCheatStages runs any anti-cheat stages specified in the TesterDefinition. Only critical logs are emitted. If
 // the stages pass, the user won't see any visible output.
 func (tester Tester) runAntiCheatStages() bool {
 	return tester.getAntiCheatRunner().Run(false, tester.getQuietExecutable())
 }
 
-// runStages runs all the stages upto the current stage the user is attempting. Returns true if all stages pass.
-func (tester Tester) runStages() bool {
-	return tester.getRunner().Run(tester.context.IsDebug, tester.getExecutable())
+// runStages runs all the stages upto the current stage the user is attempting, and returns the results of the stages
+// that ran.
+func (tester Tester) runStages() []test_runner.StepResult {
+	return tester.getRunner().RunWithResults(tester.context.IsDebug, tester.getExecutable())
 }
 
 func (tester Tester) getRunner() test_runner.TestRunner {
@@ -226,7 +387,28 @@ func (tester Tester) getRunner() test_runner.TestRunner {
 		})
 	}
 
-	return test_runner.NewTestRunner(steps, tester.context.SubmissionDir)
+	return test_runner.NewTestRunner(steps, tester.context.SubmissionDir).
+		WithLanguage(tester.context.Language).
+		WithHints(tester.definition.Hints, tester.previousFailures()).
+		WithProgramOutput(programLogPrefix, tester.definition.CollapsePassedProgramOutput)
+}
+
+// previousFailures returns the consecutive failures of each stage in previous local runs, used to reveal hints
+// progressively
+func (tester Tester) previousFailures() map[string]int {
+	failures := map[string]int{}
+
+	if !tester.context.ShouldRecordRunHistory {
+		return failures
+	}
+
+	// A missing or invalid history just means no hints have been revealed yet
+	history, _ := run_history.Load(tester.context.SubmissionDir)
+	for _, stage := range history.Stages {
+		failures[stage.Slug] = stage.ConsecutiveFailures
+	}
+
+	return failures
 }
 
 func (tester Tester) getAntiCheatRunner() test_runner.TestRunner {
@@ -240,15 +422,32 @@ func (tester Tester) getAntiCheatRunner() test_runner.TestRunner {
 		})
 	}
 
-	return test_runner.NewQuietTestRunner(steps, tester.context.SubmissionDir) // We only want Critical logs to be emitted for anti-cheat tests
+	return test_runner.NewQuietTestRunner(steps, tester.context.SubmissionDir).WithLanguage(tester.context.Language) // We only want Critical logs to be emitted for anti-cheat tests
 }
 
 func (tester Tester) getQuietExecutable() *executable.Executable {
-	return executable.NewExecutable(tester.context.ExecutablePath)
+	return tester.configureRunCommand(executable.NewExecutable(tester.context.ExecutablePath))
 }
 
+// programLogPrefix is the prefix of lines printed by the user's program
+const programLogPrefix = "[your_program] "
+
 func (tester Tester) getExecutable() *executable.Executable {
-	return executable.NewVerboseExecutable(tester.context.ExecutablePath, logger.GetLogger(true, "[your_program] ").Plainln)
+	return tester.configureRunCommand(executable.NewVerboseExecutable(tester.context.ExecutablePath, logger.GetLogger(true, programLogPrefix).Plainln))
+}
+
+// configureRunCommand makes e run the run command from bootllm.yml (if any) instead of ExecutablePath. Arguments
+// passed to Start or Run are appended to the command.
+func (tester Tester) configureRunCommand(e *executable.Executable) *executable.Executable {
+	if tester.context.RunCommand == "" {
+		return e
+	}
+
+	e.Path = "sh"
+	e.Args = []string{"-c", tester.context.RunCommand + ` "$@"`, "sh"}
+	e.WorkingDir = tester.context.SubmissionDir
+
+	return e
 }
 
 func (tester Tester) validateContext() error {
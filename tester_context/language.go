@@ -0,0 +1,93 @@
+package tester_context
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Language describes how to build and run a submission written in a given language
+type Language struct {
+	// Name is the value used for `language` in bootllm.yml. Example: "python"
+	Name string
+
+	// BuildCommand is run (using sh) in the submission directory before the tests. Empty if no build is needed.
+	BuildCommand string
+
+	// RunCommand is run (using sh) in the submission directory to start the program. Test arguments are appended.
+	RunCommand string
+
+	// detect reports whether the submission in dir looks like it's written in this language
+	detect func(dir string) bool
+}
+
+// Languages are the languages that can be declared in bootllm.yml, or auto-detected. The order matters for detection:
+// a Go submission may also contain C files (cgo), so Go is checked first.
+var Languages = []Language{
+	{
+		Name:         "go",
+		BuildCommand: "go build -o main .",
+		RunCommand:   "./main",
+		detect:       func(dir string) bool { return fileExists(dir, "go.mod") },
+	},
+	{
+		Name:         "rust",
+		BuildCommand: "cargo build --release --quiet",
+		RunCommand:   "cargo run --release --quiet --",
+		detect:       func(dir string) bool { return fileExists(dir, "Cargo.toml") },
+	},
+	{
+		Name:         "c",
+		BuildCommand: "cc -o main *.c -lm",
+		RunCommand:   "./main",
+		detect:       func(dir string) bool { return hasFileWithExtension(dir, ".c") },
+	},
+	{
+		Name:       "python",
+		RunCommand: "python3 main.py",
+		detect:     func(dir string) bool { return hasFileWithExtension(dir, ".py") },
+	},
+}
+
+// LanguageByName returns the language with the given name
+func LanguageByName(name string) (Language, bool) {
+	for _, language := range Languages {
+		if language.Name == strings.ToLower(name) {
+			return language, true
+		}
+	}
+
+	return Language{}, false
+}
+
+// DetectLanguage guesses the language of the submission in dir from the files it contains
+func DetectLanguage(dir string) (Language, bool) {
+	for _, language := range Languages {
+		if language.detect(dir) {
+			return language, true
+		}
+	}
+
+	return Language{}, false
+}
+
+func languageNames() string {
+	names := make([]string, 0, len(Languages))
+	for _, language := range Languages {
+		names = append(names, language.Name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func fileExists(dir string, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+func hasFileWithExtension(dir string, extension string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*"+extension))
+	return len(matches) > 0
+}
@@ -0,0 +1,60 @@
+package tester_context
+
+import (
+	"fmt"
+
+	"github.com/bootllm/tester-utils/internal"
+	"github.com/bootllm/tester-utils/run_history"
+)
+
+// Re-run modes, selected with BOOTLLM_RERUN
+const (
+	// RerunFailed runs only the selected stages that didn't pass in previous runs
+	RerunFailed = "failed"
+
+	// RerunResume runs the selected stages starting at the first one that didn't pass in previous runs
+	RerunResume = "resume"
+)
+
+// filterByRunHistory keeps the test cases that still need to run according to the run history in submissionDir.
+//
+// A stage only counts as passed if it passed with the current submission files: any change to the files invalidates
+// earlier results, since it can break stages that used to pass. Files generated by earlier runs don't count as changes.
+func filterByRunHistory(mode string, submissionDir string, testCases []TesterContextTestCase) ([]TesterContextTestCase, error) {
+	if mode != RerunFailed && mode != RerunResume {
+		return nil, fmt.Errorf("invalid BOOTLLM_RERUN value %q, expected %q or %q", mode, RerunFailed, RerunResume)
+	}
+
+	// A missing or unreadable history means no stage is known to pass, so everything runs
+	history, err := run_history.Load(submissionDir)
+	if err != nil {
+		history = run_history.History{}
+	}
+
+	submissionHash, err := run_history.HashSubmission(submissionDir, history.GeneratedFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash submission files: %v", err)
+	}
+
+	remaining := []TesterContextTestCase{}
+	for i, testCase := range testCases {
+		if history.PassedWith(testCase.Slug, submissionHash) {
+			continue
+		}
+
+		if mode == RerunResume {
+			remaining = testCases[i:]
+			break
+		}
+
+		remaining = append(remaining, testCase)
+	}
+
+	if len(remaining) == 0 {
+		return nil, &internal.UserError{
+			Message: fmt.Sprintf("All selected stages passed in the last run, and no files changed since. Run without --%s to run them again.", mode),
+		}
+	}
+
+	return remaining, nil
+}
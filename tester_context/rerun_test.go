@@ -0,0 +1,121 @@
+package tester_context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootllm/tester-utils/internal"
+	"github.com/bootllm/tester-utils/run_history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRunHistory 在 dir 中写入运行记录，statuses 按 slug 给出每个 stage 的结果
+func writeRunHistory(t *testing.T, dir string, statuses map[string]run_history.Status) {
+	hash, err := run_history.HashSubmission(dir)
+	require.NoError(t, err)
+
+	history := run_history.History{}
+	for _, slug := range []string{"hello", "mario-less", "mario-more", "cash", "credit"} {
+		if status, ok := statuses[slug]; ok {
+			history.Record(run_history.StageResult{Slug: slug, Status: status, SubmissionHash: hash})
+		}
+	}
+
+	require.NoError(t, history.Save(dir))
+}
+
+func contextSlugs(t *testing.T, env map[string]string) []string {
+	context, err := GetTesterContext(env, selectionTestDefinition())
+	require.NoError(t, err)
+
+	slugs := []string{}
+	for _, testCase := range context.TestCases {
+		slugs = append(slugs, testCase.Slug)
+	}
+
+	return slugs
+}
+
+func TestRerunFailed(t *testing.T) {
+	dir := t.TempDir()
+	writeRunHistory(t, dir, map[string]run_history.Status{
+		"hello":      run_history.StatusPassed,
+		"mario-less": run_history.StatusFailed,
+		"mario-more": run_history.StatusPassed,
+		"cash":       run_history.StatusNotRun,
+	})
+
+	// credit 没有记录，也需要运行
+	slugs := contextSlugs(t, map[string]string{"BOOTLLM_REPOSITORY_DIR": dir, "BOOTLLM_RERUN": RerunFailed})
+	assert.Equal(t, []string{"mario-less", "cash", "credit"}, slugs)
+
+	// 与其他选择条件组合
+	slugs = contextSlugs(t, map[string]string{"BOOTLLM_REPOSITORY_DIR": dir, "BOOTLLM_RERUN": RerunFailed, "BOOTLLM_STAGE": "mario-*"})
+	assert.Equal(t, []string{"mario-less"}, slugs)
+}
+
+func TestRerunResume(t *testing.T) {
+	dir := t.TempDir()
+	writeRunHistory(t, dir, map[string]run_history.Status{
+		"hello":      run_history.StatusPassed,
+		"mario-less": run_history.StatusFailed,
+		"mario-more": run_history.StatusPassed,
+	})
+
+	slugs := contextSlugs(t, map[string]string{"BOOTLLM_REPOSITORY_DIR": dir, "BOOTLLM_RERUN": RerunResume})
+	assert.Equal(t, []string{"mario-less", "mario-more", "cash", "credit"}, slugs)
+}
+
+// TestRerunAfterFilesChange 测试提交文件变化后，之前的通过记录失效
+func TestRerunAfterFilesChange(t *testing.T) {
+	dir := t.TempDir()
+	writeRunHistory(t, dir, map[string]run_history.Status{
+		"hello":      run_history.StatusPassed,
+		"mario-less": run_history.StatusFailed,
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mario.c"), []byte("int main() {}"), 0644))
+
+	slugs := contextSlugs(t, map[string]string{"BOOTLLM_REPOSITORY_DIR": dir, "BOOTLLM_RERUN": RerunFailed})
+	assert.Equal(t, []string{"hello", "mario-less", "mario-more", "cash", "credit"}, slugs)
+}
+
+func TestRerunNothingToRun(t *testing.T) {
+	dir := t.TempDir()
+	writeRunHistory(t, dir, map[string]run_history.Status{"hello": run_history.StatusPassed})
+
+	_, err := GetTesterContext(map[string]string{
+		"BOOTLLM_REPOSITORY_DIR": dir,
+		"BOOTLLM_RERUN":          RerunFailed,
+		"BOOTLLM_STAGE":          "hello",
+	}, selectionTestDefinition())
+
+	assert.IsType(t, &internal.UserError{}, err)
+	assert.Contains(t, err.Error(), "Run without --failed to run them again.")
+}
+
+func TestRerunIgnoredForWorkerRuns(t *testing.T) {
+	dir := t.TempDir()
+	writeRunHistory(t, dir, map[string]run_history.Status{"hello": run_history.StatusPassed})
+
+	context, err := GetTesterContext(map[string]string{
+		"BOOTLLM_REPOSITORY_DIR":  dir,
+		"BOOTLLM_RERUN":           RerunFailed,
+		"BOOTLLM_TEST_CASES_JSON": `[{"slug":"hello","tester_log_prefix":"stage-1","title":"Hello"}]`,
+	}, selectionTestDefinition())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(context.TestCases))
+	assert.False(t, context.ShouldRecordRunHistory)
+}
+
+func TestInvalidRerunMode(t *testing.T) {
+	_, err := GetTesterContext(map[string]string{
+		"BOOTLLM_REPOSITORY_DIR": t.TempDir(),
+		"BOOTLLM_RERUN":          "everything",
+	}, selectionTestDefinition())
+
+	assert.ErrorContains(t, err, `invalid BOOTLLM_RERUN value "everything"`)
+}
@@ -0,0 +1,191 @@
+package tester_context
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/bootllm/tester-utils/internal"
+	"github.com/bootllm/tester-utils/tester_definition"
+)
+
+// StageSelection describes which stages to run. Empty fields don't restrict the selection.
+//
+// Stages and Exclude accept slugs ("hello"), globs ("mario-*") and regexes ("/^mario-(less|more)$/"). From, To and
+// Until accept slugs only.
+type StageSelection struct {
+	// Stages selects stages matching any of these patterns
+	Stages []string
+
+	// From selects stages starting at this slug (inclusive)
+	From string
+
+	// To selects stages up to this slug (inclusive)
+	To string
+
+	// Until selects all stages up to this slug (inclusive), like the platform does. Can't be combined with To.
+	Until string
+
+	// Exclude removes stages matching any of these patterns
+	Exclude []string
+}
+
+// IsEmpty returns true if the selection doesn't restrict which stages to run
+func (s StageSelection) IsEmpty() bool {
+	return len(s.Stages) == 0 && s.From == "" && s.To == "" && s.Until == "" && len(s.Exclude) == 0
+}
+
+// ParseStagePatterns splits a comma-separated list of stage patterns. Example: "hello, mario-*" -> ["hello", "mario-*"]
+func ParseStagePatterns(value string) []string {
+	patterns := []string{}
+
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns
+}
+
+// SelectStages returns the test cases of definition matching selection, in definition order
+func SelectStages(selection StageSelection, definition tester_definition.TesterDefinition) ([]tester_definition.TestCase, error) {
+	if selection.To != "" && selection.Until != "" {
+		return nil, fmt.Errorf("--to and --until can't be used together")
+	}
+
+	if len(definition.TestCases) == 0 {
+		return nil, fmt.Errorf("no test cases to run")
+	}
+
+	slugs := make([]string, len(definition.TestCases))
+	for i, testCase := range definition.TestCases {
+		slugs[i] = testCase.Slug
+	}
+
+	fromIndex, toIndex := 0, len(slugs)-1
+
+	if selection.From != "" {
+		index, err := stageIndex(selection.From, slugs)
+		if err != nil {
+			return nil, err
+		}
+		fromIndex = index
+	}
+
+	if to := selection.To + selection.Until; to != "" {
+		index, err := stageIndex(to, slugs)
+		if err != nil {
+			return nil, err
+		}
+		toIndex = index
+	}
+
+	if fromIndex > toIndex {
+		return nil, fmt.Errorf("stage %q comes after stage %q, so the range is empty", slugs[fromIndex], slugs[toIndex])
+	}
+
+	included, err := matchAll(selection.Stages, slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := matchAll(selection.Exclude, slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []tester_definition.TestCase
+	for i, testCase := range definition.TestCases {
+		if i < fromIndex || i > toIndex {
+			continue
+		}
+
+		if len(selection.Stages) > 0 && !included[i] {
+			continue
+		}
+
+		if excluded[i] {
+			continue
+		}
+
+		selected = append(selected, testCase)
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no stages match the selection")
+	}
+
+	return selected, nil
+}
+
+// stageIndex returns the index of slug in slugs, or an error with a suggestion if it isn't found
+func stageIndex(slug string, slugs []string) (int, error) {
+	for i, candidate := range slugs {
+		if candidate == slug {
+			return i, nil
+		}
+	}
+
+	return 0, unknownStageError(slug, slugs)
+}
+
+// matchAll returns the indices of slugs matching any of patterns. Every pattern must match at least one slug.
+func matchAll(patterns []string, slugs []string) (map[int]bool, error) {
+	matched := map[int]bool{}
+
+	for _, pattern := range patterns {
+		matches, err := stagePatternMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for i, slug := range slugs {
+			if matches(slug) {
+				matched[i] = true
+				found = true
+			}
+		}
+
+		if !found {
+			if isStagePattern(pattern) {
+				return nil, fmt.Errorf("no stages match pattern %q", pattern)
+			}
+
+			return nil, unknownStageError(pattern, slugs)
+		}
+	}
+
+	return matched, nil
+}
+
+// stagePatternMatcher returns a function that reports whether a slug matches pattern
+func stagePatternMatcher(pattern string) (func(slug string) bool, error) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage regex %q: %v", pattern, err)
+		}
+
+		return re.MatchString, nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid stage pattern %q: %v", pattern, err)
+	}
+
+	return func(slug string) bool {
+		matched, _ := path.Match(pattern, slug)
+		return matched
+	}, nil
+}
+
+func isStagePattern(pattern string) bool {
+	return strings.HasPrefix(pattern, "/") || strings.ContainsAny(pattern, "*?[")
+}
+
+func unknownStageError(slug string, slugs []string) error {
+	return fmt.Errorf("stage %q not found in tester definition.%s", slug, internal.DidYouMean(slug, slugs))
+}
@@ -0,0 +1,129 @@
+package tester_context
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bootllm/tester-utils/internal"
+	"github.com/bootllm/tester-utils/test_case_harness"
+	"github.com/bootllm/tester-utils/tester_definition"
+	"github.com/stretchr/testify/assert"
+)
+
+func selectionTestDefinition() tester_definition.TesterDefinition {
+	testCase := func(slug string) tester_definition.TestCase {
+		return tester_definition.TestCase{Slug: slug, Timeout: 10 * time.Second, TestFunc: func(h *test_case_harness.TestCaseHarness) error { return nil }}
+	}
+
+	return tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			testCase("hello"),
+			testCase("mario-less"),
+			testCase("mario-more"),
+			testCase("cash"),
+			testCase("credit"),
+		},
+	}
+}
+
+func selectedSlugs(t *testing.T, selection StageSelection) []string {
+	testCases, err := SelectStages(selection, selectionTestDefinition())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	slugs := []string{}
+	for _, testCase := range testCases {
+		slugs = append(slugs, testCase.Slug)
+	}
+
+	return slugs
+}
+
+func TestParseStagePatterns(t *testing.T) {
+	assert.Equal(t, []string{"hello", "mario-*"}, ParseStagePatterns("hello, mario-*"))
+	assert.Equal(t, []string{"hello"}, ParseStagePatterns("hello,,"))
+	assert.Equal(t, []string{}, ParseStagePatterns(""))
+}
+
+func TestSelectStages(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection StageSelection
+		expected  []string
+	}{
+		{"单个 slug", StageSelection{Stages: []string{"cash"}}, []string{"cash"}},
+		{"逗号列表保持定义顺序", StageSelection{Stages: []string{"credit", "hello"}}, []string{"hello", "credit"}},
+		{"glob", StageSelection{Stages: []string{"mario-*"}}, []string{"mario-less", "mario-more"}},
+		{"正则", StageSelection{Stages: []string{"/^c/"}}, []string{"cash", "credit"}},
+		{"范围", StageSelection{From: "mario-less", To: "cash"}, []string{"mario-less", "mario-more", "cash"}},
+		{"只有 from", StageSelection{From: "cash"}, []string{"cash", "credit"}},
+		{"until", StageSelection{Until: "mario-more"}, []string{"hello", "mario-less", "mario-more"}},
+		{"排除", StageSelection{Until: "credit", Exclude: []string{"mario-*"}}, []string{"hello", "cash", "credit"}},
+		{"模式与范围取交集", StageSelection{Stages: []string{"/r/"}, To: "cash"}, []string{"mario-less", "mario-more"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, selectedSlugs(t, tt.selection))
+		})
+	}
+}
+
+func TestSelectStages_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection StageSelection
+		expected  string
+	}{
+		{"未知 slug 给出建议", StageSelection{Stages: []string{"helo"}}, `stage "helo" not found in tester definition. Did you mean "hello"?`},
+		{"未知范围起点", StageSelection{From: "mario"}, `stage "mario" not found in tester definition.`},
+		{"未知 until", StageSelection{Until: "credt"}, `Did you mean "credit"?`},
+		{"glob 无匹配", StageSelection{Stages: []string{"runoff-*"}}, `no stages match pattern "runoff-*"`},
+		{"无效正则", StageSelection{Stages: []string{"/(/"}}, `invalid stage regex "/(/"`},
+		{"无效 glob", StageSelection{Stages: []string{"[a"}}, `invalid stage pattern "[a"`},
+		{"范围颠倒", StageSelection{From: "cash", To: "hello"}, `stage "cash" comes after stage "hello", so the range is empty`},
+		{"to 与 until 冲突", StageSelection{To: "cash", Until: "cash"}, "--to and --until can't be used together"},
+		{"全部被排除", StageSelection{Stages: []string{"hello"}, Exclude: []string{"hello"}}, "no stages match the selection"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SelectStages(tt.selection, selectionTestDefinition())
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestSelectionFromEnv 测试通过环境变量选择 stage，log prefix 保留原序号
+func TestSelectionFromEnv(t *testing.T) {
+	context, err := GetTesterContext(map[string]string{
+		"BOOTLLM_REPOSITORY_DIR": "./test_helpers/valid_app_dir",
+		"BOOTLLM_STAGE_FROM":     "mario-less",
+		"BOOTLLM_STAGE_UNTIL":    "credit",
+		"BOOTLLM_STAGE_EXCLUDE":  "mario-more, cash",
+	}, selectionTestDefinition())
+
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, 2, len(context.TestCases))
+	assert.Equal(t, "mario-less", context.TestCases[0].Slug)
+	assert.Equal(t, "stage-2", context.TestCases[0].TesterLogPrefix)
+	assert.Equal(t, "credit", context.TestCases[1].Slug)
+	assert.Equal(t, "stage-5", context.TestCases[1].TesterLogPrefix)
+}
+
+// TestSelectionErrorIsUserError 测试选择错误作为用户错误返回
+func TestSelectionErrorIsUserError(t *testing.T) {
+	_, err := GetTesterContext(map[string]string{
+		"BOOTLLM_REPOSITORY_DIR": "./test_helpers/valid_app_dir",
+		"BOOTLLM_STAGE":          "mario-les",
+	}, selectionTestDefinition())
+
+	assert.IsType(t, &internal.UserError{}, err)
+	assert.Contains(t, err.Error(), `Did you mean "mario-less"?`)
+}
@@ -8,9 +8,10 @@ import (
 	"path"
 	"strings"
 
+	"github.com/bootllm/tester-utils/i18n"
 	"github.com/bootllm/tester-utils/internal"
 	"github.com/bootllm/tester-utils/tester_definition"
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 // TesterContextTestCase represents one element in the BOOTLLM_TEST_CASES environment variable
@@ -33,26 +34,75 @@ type TesterContext struct {
 	// ExecutablePath is the path to the executable (may be empty if ExecutableFileName is not set)
 	ExecutablePath string
 
+	// Language is the language of the submission, declared in bootllm.yml or auto-detected. Empty if unknown. Only a
+	// declared language sets default build and run commands, a detected one is just reported.
+	Language string
+
+	// BuildCommand is run (using sh) in SubmissionDir before the tests. Empty if no build is needed.
+	BuildCommand string
+
+	// RunCommand is run (using sh) in SubmissionDir to start the program. Empty if ExecutablePath should be used.
+	RunCommand string
+
+	// Locale is the language of tester messages, from BOOTLLM_LOCALE or bootllm.yml. Defaults to English.
+	Locale i18n.Locale
+
+	// ShouldRecordRunHistory is true for local runs, whose results are saved for --failed and --resume. Runs
+	// scheduled by the worker (BOOTLLM_TEST_CASES_JSON) don't write to the submission directory.
+	ShouldRecordRunHistory bool
+
+	// ConfigWarnings are problems in bootllm.yml that don't stop the run, like deprecated values
+	ConfigWarnings []string
+
 	IsDebug                      bool
 	TestCases                    []TesterContextTestCase
 	ShouldSkipAntiCheatTestCases bool
 }
 
+// yamlConfig is the contents of bootllm.yml. Keys must also be listed in yamlSchema.
 type yamlConfig struct {
-	Debug bool `yaml:"debug"`
+	Debug    bool   `yaml:"debug"`
+	Language string `yaml:"language"`
+	Build    string `yaml:"build"`
+	Run      string `yaml:"run"`
+	Locale   string `yaml:"locale"`
+
+	// warnings are about deprecated values that are still accepted
+	warnings []string
 }
 
 func (c TesterContext) Print() {
 	fmt.Println("Debug =", c.IsDebug)
+
+	if c.Language != "" {
+		fmt.Println("Language =", c.Language)
+	}
+	if c.BuildCommand != "" {
+		fmt.Println("Build =", c.BuildCommand)
+	}
+	if c.RunCommand != "" {
+		fmt.Println("Run =", c.RunCommand)
+	}
+	if c.Locale != i18n.English {
+		fmt.Println("Locale =", c.Locale)
+	}
 }
 
 // GetTesterContext parses flags and returns a Context object
 // 支持三种模式：
 // 1. BOOTLLM_TEST_CASES_JSON - 完整 JSON 格式（兼容 worker 调度）
-// 2. BOOTLLM_STAGE - 指定单个 stage slug（调试用）
+// 2. 按条件选择 stage（调试用）：
+//   - BOOTLLM_STAGE - 逗号分隔的 slug、glob（mario-*）或正则（/^mario/）
+//   - BOOTLLM_STAGE_FROM / BOOTLLM_STAGE_TO - 按范围选择（包含两端）
+//   - BOOTLLM_STAGE_UNTIL - 从第一个 stage 运行到指定 stage（与平台行为一致）
+//   - BOOTLLM_STAGE_EXCLUDE - 排除匹配的 stage，格式同 BOOTLLM_STAGE
+//   - BOOTLLM_RERUN - failed（只运行上次未通过的 stage）或 resume（从第一个未通过的 stage 继续），
+//     可与以上条件组合
+//
 // 3. 无环境变量 - 运行所有测试（默认行为）
 //
 // BOOTLLM_REPOSITORY_DIR 默认为当前目录 "."
+// BOOTLLM_LOCALE 优先于 bootllm.yml 中的 locale
 func GetTesterContext(env map[string]string, definition tester_definition.TesterDefinition) (TesterContext, error) {
 	submissionDir, ok := env["BOOTLLM_REPOSITORY_DIR"]
 	if !ok {
@@ -63,16 +113,24 @@ func GetTesterContext(env map[string]string, definition tester_definition.Tester
 	var testCases []TesterContextTestCase
 	var err error
 
-	// 优先级：JSON > STAGE > 全部运行
+	stageSelection := StageSelection{
+		Stages:  ParseStagePatterns(env["BOOTLLM_STAGE"]),
+		From:    env["BOOTLLM_STAGE_FROM"],
+		To:      env["BOOTLLM_STAGE_TO"],
+		Until:   env["BOOTLLM_STAGE_UNTIL"],
+		Exclude: ParseStagePatterns(env["BOOTLLM_STAGE_EXCLUDE"]),
+	}
+
+	// 优先级：JSON > 选择条件 > 全部运行
 	if testCasesJson, ok := env["BOOTLLM_TEST_CASES_JSON"]; ok {
 		// 模式1：完整 JSON 格式（兼容 worker）
 		testCases, err = parseTestCasesFromJSON(testCasesJson)
 		if err != nil {
 			return TesterContext{}, err
 		}
-	} else if stageSlug, ok := env["BOOTLLM_STAGE"]; ok {
-		// 模式2：单个 stage（调试用）
-		testCases, err = buildTestCasesForStage(stageSlug, definition)
+	} else if !stageSelection.IsEmpty() {
+		// 模式2：按条件选择 stage（调试用）
+		testCases, err = buildTestCasesForSelection(stageSelection, definition)
 		if err != nil {
 			return TesterContext{}, err
 		}
@@ -81,6 +139,15 @@ func GetTesterContext(env map[string]string, definition tester_definition.Tester
 		testCases = buildTestCasesForAll(definition)
 	}
 
+	_, isWorkerRun := env["BOOTLLM_TEST_CASES_JSON"]
+
+	if rerunMode := env["BOOTLLM_RERUN"]; rerunMode != "" && !isWorkerRun {
+		testCases, err = filterByRunHistory(rerunMode, submissionDir, testCases)
+		if err != nil {
+			return TesterContext{}, err
+		}
+	}
+
 	if len(testCases) == 0 {
 		return TesterContext{}, fmt.Errorf("no test cases to run")
 	}
@@ -113,15 +180,85 @@ func GetTesterContext(env map[string]string, definition tester_definition.Tester
 		return TesterContext{}, err
 	}
 
+	language, buildCommand, runCommand, err := resolveCommands(submissionDir, yamlConfig)
+	if err != nil {
+		return TesterContext{}, err
+	}
+
+	locale, err := resolveLocale(env, yamlConfig)
+	if err != nil {
+		return TesterContext{}, err
+	}
+
 	return TesterContext{
 		SubmissionDir:                submissionDir,
 		ExecutablePath:               executablePath,
+		Language:                     language,
+		BuildCommand:                 buildCommand,
+		RunCommand:                   runCommand,
+		Locale:                       locale,
+		ConfigWarnings:               yamlConfig.warnings,
 		IsDebug:                      yamlConfig.Debug,
 		TestCases:                    testCases,
 		ShouldSkipAntiCheatTestCases: shouldSkipAntiCheatTestCases,
+		ShouldRecordRunHistory:       !isWorkerRun,
 	}, nil
 }
 
+// resolveLocale 确定 tester 消息的语言：BOOTLLM_LOCALE 优先，其次是 bootllm.yml 中的 locale，默认英文
+func resolveLocale(env map[string]string, config yamlConfig) (i18n.Locale, error) {
+	if value := env["BOOTLLM_LOCALE"]; value != "" {
+		locale, err := i18n.ParseLocale(value)
+		if err != nil {
+			return "", &internal.UserError{Message: fmt.Sprintf("Invalid BOOTLLM_LOCALE: %v", err)}
+		}
+
+		return locale, nil
+	}
+
+	if config.Locale != "" {
+		locale, err := i18n.ParseLocale(config.Locale)
+		if err != nil {
+			return "", &internal.UserError{Message: fmt.Sprintf("Invalid locale in bootllm.yml: %v", err)}
+		}
+
+		return locale, nil
+	}
+
+	return i18n.English, nil
+}
+
+// resolveCommands 确定提交的语言以及构建、运行命令
+// bootllm.yml 中的 build / run 优先；否则在声明了 language 时使用该语言的默认命令。
+// 自动检测到的语言只用于 Language 字段，不会触发默认的构建/运行命令，
+// 以免改变只使用 SubmissionDir 的 tester 的行为。
+func resolveCommands(submissionDir string, config yamlConfig) (language string, buildCommand string, runCommand string, err error) {
+	if config.Language != "" {
+		declaredLanguage, ok := LanguageByName(config.Language)
+		if !ok {
+			return "", "", "", &internal.UserError{
+				Message: fmt.Sprintf("Unknown language %q in bootllm.yml. Supported languages: %s", config.Language, languageNames()),
+			}
+		}
+
+		language = declaredLanguage.Name
+		buildCommand = declaredLanguage.BuildCommand
+		runCommand = declaredLanguage.RunCommand
+	} else if detectedLanguage, ok := DetectLanguage(submissionDir); ok {
+		language = detectedLanguage.Name
+	}
+
+	if config.Build != "" {
+		buildCommand = config.Build
+	}
+
+	if config.Run != "" {
+		runCommand = config.Run
+	}
+
+	return language, buildCommand, runCommand, nil
+}
+
 // parseTestCasesFromJSON 从 JSON 字符串解析测试用例
 func parseTestCasesFromJSON(jsonStr string) ([]TesterContextTestCase, error) {
 	testCases := []TesterContextTestCase{}
@@ -144,21 +281,26 @@ func parseTestCasesFromJSON(jsonStr string) ([]TesterContextTestCase, error) {
 	return testCases, nil
 }
 
-// buildTestCasesForStage 为单个 stage 构建测试用例
-func buildTestCasesForStage(stageSlug string, definition tester_definition.TesterDefinition) ([]TesterContextTestCase, error) {
-	// 找到对应的测试用例
-	for i, tc := range definition.TestCases {
-		if tc.Slug == stageSlug {
-			return []TesterContextTestCase{
-				{
-					Slug:            tc.Slug,
-					TesterLogPrefix: fmt.Sprintf("stage-%d", i+1),
-					Title:           formatTitle(tc.Slug),
-				},
-			}, nil
+// buildTestCasesForSelection 为选中的 stage 构建测试用例，log prefix 保留 stage 在定义中的序号
+func buildTestCasesForSelection(selection StageSelection, definition tester_definition.TesterDefinition) ([]TesterContextTestCase, error) {
+	selected, err := SelectStages(selection, definition)
+	if err != nil {
+		return nil, &internal.UserError{Message: err.Error()}
+	}
+
+	selectedSlugs := map[string]bool{}
+	for _, tc := range selected {
+		selectedSlugs[tc.Slug] = true
+	}
+
+	testCases := []TesterContextTestCase{}
+	for _, tc := range buildTestCasesForAll(definition) {
+		if selectedSlugs[tc.Slug] {
+			testCases = append(testCases, tc)
 		}
 	}
-	return nil, fmt.Errorf("stage %q not found in tester definition", stageSlug)
+
+	return testCases, nil
 }
 
 // buildTestCasesForAll 为所有 stage 构建测试用例
@@ -168,12 +310,21 @@ func buildTestCasesForAll(definition tester_definition.TesterDefinition) []Teste
 		testCases = append(testCases, TesterContextTestCase{
 			Slug:            tc.Slug,
 			TesterLogPrefix: fmt.Sprintf("stage-%d", i+1),
-			Title:           formatTitle(tc.Slug),
+			Title:           StageTitle(tc),
 		})
 	}
 	return testCases
 }
 
+// StageTitle 返回测试用例的标题，未设置 Title 时由 slug 生成
+func StageTitle(testCase tester_definition.TestCase) string {
+	if testCase.Title != "" {
+		return testCase.Title
+	}
+
+	return formatTitle(testCase.Slug)
+}
+
 // formatTitle 将 slug 转换为可读标题
 // "mario-less" -> "Mario Less"
 func formatTitle(slug string) string {
@@ -200,11 +351,24 @@ func readFromYAML(configPath string) (yamlConfig, error) {
 		}
 	}
 
-	if err := yaml.Unmarshal(fileContents, c); err != nil {
+	var document yaml.Node
+	if err := yaml.Unmarshal(fileContents, &document); err != nil {
+		return yamlConfig{}, &internal.UserError{
+			Message: fmt.Sprintf("Error parsing bootllm.yml: %s", err),
+		}
+	}
+
+	warnings, err := validateYAMLConfig(&document)
+	if err != nil {
+		return yamlConfig{}, err
+	}
+
+	if err := document.Decode(c); err != nil {
 		return yamlConfig{}, &internal.UserError{
 			Message: fmt.Sprintf("Error parsing bootllm.yml: %s", err),
 		}
 	}
 
+	c.warnings = warnings
 	return *c, nil
 }
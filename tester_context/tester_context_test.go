@@ -2,12 +2,17 @@ package tester_context
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/bootllm/tester-utils/i18n"
+	"github.com/bootllm/tester-utils/internal"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestDefaultRepositoryDir 测试默认目录为当前目录
@@ -178,3 +183,203 @@ func TestCorrectExecutable(t *testing.T) {
 		assert.Equal(t, context.ExecutablePath, fmt.Sprintf("test_helpers/%s/%s", tt.submissionDir, tt.expectedExecutable))
 	}
 }
+
+// getContextForDir 使用给定目录中的文件构建 TesterContext
+func getContextForDir(t *testing.T, files map[string]string) (TesterContext, error) {
+	dir := t.TempDir()
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return GetTesterContext(map[string]string{
+		"BOOTLLM_TEST_CASES_JSON": `[{ "slug": "test", "tester_log_prefix": "test", "title": "Test"}]`,
+		"BOOTLLM_REPOSITORY_DIR":  dir,
+	}, tester_definition.TesterDefinition{})
+}
+
+// TestLanguageFromConfig 测试 bootllm.yml 中声明的语言及默认构建/运行命令
+func TestLanguageFromConfig(t *testing.T) {
+	context, err := getContextForDir(t, map[string]string{"bootllm.yml": "language: C\n"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, "c", context.Language)
+	assert.Equal(t, "cc -o main *.c -lm", context.BuildCommand)
+	assert.Equal(t, "./main", context.RunCommand)
+}
+
+// TestBuildAndRunFromConfig 测试 bootllm.yml 中的 build / run 覆盖语言默认命令
+func TestBuildAndRunFromConfig(t *testing.T) {
+	context, err := getContextForDir(t, map[string]string{
+		"bootllm.yml": "language: go\nbuild: go build -o app ./cmd/app\nrun: ./app --verbose\n",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, "go", context.Language)
+	assert.Equal(t, "go build -o app ./cmd/app", context.BuildCommand)
+	assert.Equal(t, "./app --verbose", context.RunCommand)
+}
+
+// TestLanguageDetection 测试自动检测语言（不会启用默认构建/运行命令）
+func TestLanguageDetection(t *testing.T) {
+	tests := []struct {
+		files    map[string]string
+		expected string
+	}{
+		{map[string]string{"main.py": ""}, "python"},
+		{map[string]string{"hello.c": ""}, "c"},
+		{map[string]string{"go.mod": "", "main.go": "", "helper.c": ""}, "go"},
+		{map[string]string{"Cargo.toml": ""}, "rust"},
+		{map[string]string{"README.md": ""}, ""},
+	}
+
+	for _, tt := range tests {
+		context, err := getContextForDir(t, tt.files)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		assert.Equal(t, tt.expected, context.Language, "files=%v", tt.files)
+		assert.Equal(t, "", context.BuildCommand)
+		assert.Equal(t, "", context.RunCommand)
+	}
+}
+
+// TestUnknownLanguage 测试未知语言返回用户错误
+func TestUnknownLanguage(t *testing.T) {
+	_, err := getContextForDir(t, map[string]string{"bootllm.yml": "language: cobol\n"})
+
+	var userError *internal.UserError
+	if assert.ErrorAs(t, err, &userError) {
+		assert.Equal(t, `Unknown language "cobol" in bootllm.yml. Supported languages: c, go, python, rust`, userError.Message)
+	}
+}
+
+// TestDeprecatedYAMLBooleans 测试 yaml.v2 接受的 yes/no/on/off 仍然可用，但会给出警告
+func TestDeprecatedYAMLBooleans(t *testing.T) {
+	context, err := getContextForDir(t, map[string]string{"bootllm.yml": "debug: yes\n"})
+	assert.NoError(t, err)
+	assert.True(t, context.IsDebug)
+	assert.Equal(t, []string{"line 1, column 8: debug: yes is deprecated, use debug: true instead"}, context.ConfigWarnings)
+
+	context, err = getContextForDir(t, map[string]string{"bootllm.yml": "debug: off\n"})
+	assert.NoError(t, err)
+	assert.False(t, context.IsDebug)
+	assert.Equal(t, []string{"line 1, column 8: debug: off is deprecated, use debug: false instead"}, context.ConfigWarnings)
+
+	// Quoted values were strings in yaml.v2 too
+	_, err = getContextForDir(t, map[string]string{"bootllm.yml": "debug: \"yes\"\n"})
+	assert.Error(t, err)
+}
+
+// TestInvalidYAMLConfig 测试 bootllm.yml 的 schema 校验错误信息
+func TestInvalidYAMLConfig(t *testing.T) {
+	tests := []struct {
+		config          string
+		expectedMessage string
+	}{
+		{
+			"debgu: true\n",
+			"Invalid bootllm.yml:\n  line 1, column 1: unknown key \"debgu\". Did you mean \"debug\"?",
+		},
+		{
+			"timeout: 10\n",
+			"Invalid bootllm.yml:\n  line 1, column 1: unknown key \"timeout\". Allowed keys: debug, language, build, run, locale",
+		},
+		{
+			"# comment\ndebug: maybe\nlanguage: 3\n",
+			"Invalid bootllm.yml:\n  line 2, column 8: \"debug\" must be true or false, got \"maybe\"\n  line 3, column 11: \"language\" must be a string, got the number 3. Wrap it in quotes: language: \"3\"",
+		},
+		{
+			"run:\n  - ./main\n",
+			"Invalid bootllm.yml:\n  line 2, column 3: \"run\" must be a string, got a list",
+		},
+		{
+			"debug: true\ndebug: false\n",
+			"Invalid bootllm.yml:\n  line 2, column 1: duplicate key \"debug\"",
+		},
+		{
+			"- debug\n",
+			"Invalid bootllm.yml:\n  line 1, column 1: expected keys and values (like \"debug: true\"), got a list",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := getContextForDir(t, map[string]string{"bootllm.yml": tt.config})
+
+		var userError *internal.UserError
+		if assert.ErrorAs(t, err, &userError, "config=%q", tt.config) {
+			assert.Equal(t, tt.expectedMessage, userError.Message, "config=%q", tt.config)
+		}
+	}
+}
+
+// TestValidYAMLConfig 测试合法的 bootllm.yml（包括空文件和空值）
+func TestValidYAMLConfig(t *testing.T) {
+	for _, config := range []string{"", "# only comments\n", "debug: true\nrun:\n", "debug: false\nlanguage: python\nbuild: \"true\"\n"} {
+		_, err := getContextForDir(t, map[string]string{"bootllm.yml": config})
+		assert.NoError(t, err, "config=%q", config)
+	}
+}
+
+// TestYAMLSyntaxError 测试 YAML 语法错误
+func TestYAMLSyntaxError(t *testing.T) {
+	_, err := getContextForDir(t, map[string]string{"bootllm.yml": "debug: true\n  run: x\n"})
+
+	var userError *internal.UserError
+	if assert.ErrorAs(t, err, &userError) {
+		assert.Contains(t, userError.Message, "Error parsing bootllm.yml: yaml: line 2")
+	}
+}
+
+// TestCustomTitle 测试测试用例自定义的 Title 优先于由 slug 生成的标题
+func TestCustomTitle(t *testing.T) {
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "hello", Title: "Print Hello World", TestFunc: func(h *test_case_harness.TestCaseHarness) error { return nil }},
+			{Slug: "mario-less", TestFunc: func(h *test_case_harness.TestCaseHarness) error { return nil }},
+		},
+	}
+
+	context, err := GetTesterContext(map[string]string{"BOOTLLM_REPOSITORY_DIR": "./test_helpers/valid_app_dir"}, definition)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, "Print Hello World", context.TestCases[0].Title)
+	assert.Equal(t, "Mario Less", context.TestCases[1].Title)
+}
+
+// TestLocale 测试 tester 消息的语言：BOOTLLM_LOCALE 优先于 bootllm.yml，默认英文
+func TestLocale(t *testing.T) {
+	context, err := getContextForDir(t, map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, i18n.English, context.Locale)
+
+	context, err = getContextForDir(t, map[string]string{"bootllm.yml": "locale: zh_CN\n"})
+	require.NoError(t, err)
+	assert.Equal(t, i18n.Chinese, context.Locale)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bootllm.yml"), []byte("locale: zh\n"), 0644))
+
+	context, err = GetTesterContext(map[string]string{"BOOTLLM_REPOSITORY_DIR": dir, "BOOTLLM_LOCALE": "en"}, selectionTestDefinition())
+	require.NoError(t, err)
+	assert.Equal(t, i18n.English, context.Locale)
+}
+
+// TestInvalidLocale 测试不支持的语言返回用户错误
+func TestInvalidLocale(t *testing.T) {
+	_, err := getContextForDir(t, map[string]string{"bootllm.yml": "locale: klingon\n"})
+	assert.IsType(t, &internal.UserError{}, err)
+	assert.EqualError(t, err, `Invalid locale in bootllm.yml: unsupported locale "klingon" (supported: en, zh)`)
+
+	_, err = GetTesterContext(map[string]string{"BOOTLLM_REPOSITORY_DIR": t.TempDir(), "BOOTLLM_LOCALE": "fr"}, selectionTestDefinition())
+	assert.IsType(t, &internal.UserError{}, err)
+	assert.ErrorContains(t, err, "Invalid BOOTLLM_LOCALE")
+}
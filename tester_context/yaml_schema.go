@@ -0,0 +1,211 @@
+package tester_context
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bootllm/tester-utils/internal"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlFieldKind is the type of value expected for a key in bootllm.yml
+type yamlFieldKind string
+
+const (
+	yamlBoolean yamlFieldKind = "boolean"
+	yamlString  yamlFieldKind = "string"
+)
+
+// yamlSchema lists all keys allowed in bootllm.yml. Keep in sync with yamlConfig.
+var yamlSchema = []struct {
+	key  string
+	kind yamlFieldKind
+}{
+	{"debug", yamlBoolean},
+	{"language", yamlString},
+	{"build", yamlString},
+	{"run", yamlString},
+	{"locale", yamlString},
+}
+
+// yaml11Booleans are the YAML 1.1 booleans that yaml.v2 accepted. They're strings in YAML 1.2, but yaml.v3 still
+// decodes them into bool fields.
+var yaml11Booleans = map[string]string{
+	"y": "true", "Y": "true", "yes": "true", "Yes": "true", "YES": "true", "on": "true", "On": "true", "ON": "true",
+	"n": "false", "N": "false", "no": "false", "No": "false", "NO": "false", "off": "false", "Off": "false", "OFF": "false",
+}
+
+// yamlSchemaError is a problem found at a specific position in bootllm.yml
+type yamlSchemaError struct {
+	line    int
+	column  int
+	message string
+}
+
+func (e yamlSchemaError) String() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.line, e.column, e.message)
+}
+
+// validateYAMLConfig checks the parsed bootllm.yml document against yamlSchema, and returns a UserError listing
+// every problem found (unknown keys, duplicate keys and values of the wrong type).
+//
+// Deprecated values that are still accepted (YAML 1.1 booleans like "yes") are returned as warnings.
+func validateYAMLConfig(document *yaml.Node) (warnings []string, err error) {
+	// An empty file is a valid (empty) config
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, newYAMLSchemaUserError([]yamlSchemaError{{
+			line:    root.Line,
+			column:  root.Column,
+			message: fmt.Sprintf(`expected keys and values (like "debug: true"), got %s`, describeYAMLNode(root)),
+		}})
+	}
+
+	var errors []yamlSchemaError
+	seenKeys := map[string]bool{}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], root.Content[i+1]
+		key := keyNode.Value
+
+		kind, ok := yamlSchemaKind(key)
+		if !ok {
+			errors = append(errors, yamlSchemaError{
+				line:    keyNode.Line,
+				column:  keyNode.Column,
+				message: fmt.Sprintf("unknown key %q.%s", key, unknownKeyHint(key)),
+			})
+			continue
+		}
+
+		if seenKeys[key] {
+			errors = append(errors, yamlSchemaError{
+				line:    keyNode.Line,
+				column:  keyNode.Column,
+				message: fmt.Sprintf("duplicate key %q", key),
+			})
+			continue
+		}
+		seenKeys[key] = true
+
+		if replacement, ok := deprecatedYAMLValue(kind, valueNode); ok {
+			warnings = append(warnings, yamlSchemaError{
+				line:    valueNode.Line,
+				column:  valueNode.Column,
+				message: fmt.Sprintf("%s: %s is deprecated, use %s: %s instead", key, valueNode.Value, key, replacement),
+			}.String())
+			continue
+		}
+
+		if message, ok := checkYAMLValue(key, kind, valueNode); !ok {
+			errors = append(errors, yamlSchemaError{
+				line:    valueNode.Line,
+				column:  valueNode.Column,
+				message: message,
+			})
+		}
+	}
+
+	if len(errors) > 0 {
+		return warnings, newYAMLSchemaUserError(errors)
+	}
+
+	return warnings, nil
+}
+
+// deprecatedYAMLValue returns the replacement for value if it's deprecated but still accepted for kind
+func deprecatedYAMLValue(kind yamlFieldKind, value *yaml.Node) (string, bool) {
+	// Quoted values were strings in yaml.v2 too
+	if kind != yamlBoolean || value.Kind != yaml.ScalarNode || value.Style != 0 || value.Tag != "!!str" {
+		return "", false
+	}
+
+	replacement, ok := yaml11Booleans[value.Value]
+	return replacement, ok
+}
+
+// checkYAMLValue checks that value has the expected kind. Empty values (null) are allowed and mean "not set".
+func checkYAMLValue(key string, kind yamlFieldKind, value *yaml.Node) (string, bool) {
+	if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+		return "", true
+	}
+
+	switch kind {
+	case yamlBoolean:
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!bool" {
+			return "", true
+		}
+
+		return fmt.Sprintf("%q must be true or false, got %s", key, describeYAMLNode(value)), false
+	case yamlString:
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!str" {
+			return "", true
+		}
+
+		message := fmt.Sprintf("%q must be a string, got %s", key, describeYAMLNode(value))
+		if value.Kind == yaml.ScalarNode {
+			message += fmt.Sprintf(`. Wrap it in quotes: %s: "%s"`, key, value.Value)
+		}
+
+		return message, false
+	}
+
+	return "", true
+}
+
+func yamlSchemaKind(key string) (yamlFieldKind, bool) {
+	for _, field := range yamlSchema {
+		if field.key == key {
+			return field.kind, true
+		}
+	}
+
+	return "", false
+}
+
+func unknownKeyHint(key string) string {
+	keys := make([]string, len(yamlSchema))
+	for i, field := range yamlSchema {
+		keys[i] = field.key
+	}
+
+	if hint := internal.DidYouMean(key, keys); hint != "" {
+		return hint
+	}
+
+	return fmt.Sprintf(" Allowed keys: %s", strings.Join(keys, ", "))
+}
+
+// describeYAMLNode describes a node for error messages, e.g. `"yes"`, `a list` or `the number 1`
+func describeYAMLNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.AliasNode:
+		return "an alias"
+	}
+
+	switch node.Tag {
+	case "!!int", "!!float":
+		return "the number " + node.Value
+	case "!!bool":
+		return "the boolean " + node.Value
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+func newYAMLSchemaUserError(errors []yamlSchemaError) *internal.UserError {
+	lines := []string{"Invalid bootllm.yml:"}
+	for _, err := range errors {
+		lines = append(lines, "  "+err.String())
+	}
+
+	return &internal.UserError{Message: strings.Join(lines, "\n")}
+}
@@ -3,6 +3,8 @@ package tester_definition
 import (
 	"time"
 
+	"github.com/bootllm/tester-utils/hints"
+	"github.com/bootllm/tester-utils/i18n"
 	"github.com/bootllm/tester-utils/test_case_harness"
 )
 
@@ -15,11 +17,20 @@ type TestCase struct {
 	// Slug is the unique identifier for this test case. For now, it must match the slug of the stage from the course's YAML definition.
 	Slug string
 
+	// Title is the human-readable name of the test case. Example: "Print Hello World". Optional, derived from Slug if empty.
+	Title string
+
+	// Description explains what the test case checks. Optional, shown by `--list`.
+	Description string
+
 	// TestFunc is the function that'll be run against the user's code.
 	TestFunc func(testCaseHarness *test_case_harness.TestCaseHarness) error
 
 	// Timeout is the maximum amount of time that the test case can run for.
 	Timeout time.Duration
+
+	// Hints are shown when this test case fails with a matching error. Checked before TesterDefinition.Hints.
+	Hints []hints.Rule
 }
 
 func (t TestCase) CustomOrDefaultTimeout() time.Duration {
@@ -35,8 +46,23 @@ type TesterDefinition struct {
 	ExecutableFileName       string
 	LegacyExecutableFileName string
 
+	// RequiredCommands are commands the tester itself needs on PATH, e.g. "clang" for runner.CompileC. Checked by
+	// `tester doctor`, along with the commands used by bootllm.yml.
+	RequiredCommands []string
+
 	TestCases          []TestCase
 	AntiCheatTestCases []TestCase
+
+	// CollapsePassedProgramOutput hides the output of the user's program for stages that pass (a single line says how
+	// many lines were hidden), to keep successful runs short. The output is always shown in debug mode.
+	CollapsePassedProgramOutput bool
+
+	// Hints are shown when any test case fails with a matching error
+	Hints []hints.Rule
+
+	// Messages are translations of the tester's own messages (used with i18n.T), by locale. They're registered before
+	// the tests run, and override built-in translations with the same English message.
+	Messages map[i18n.Locale]map[string]string
 }
 
 func (t TesterDefinition) TestCaseBySlug(slug string) TestCase {
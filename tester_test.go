@@ -1,11 +1,23 @@
 package tester_utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/bootllm/tester-utils/events"
+	"github.com/bootllm/tester-utils/hints"
+	"github.com/bootllm/tester-utils/i18n"
+	"github.com/bootllm/tester-utils/redact"
+	"github.com/bootllm/tester-utils/run_history"
+	"github.com/bootllm/tester-utils/runner"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 	"github.com/stretchr/testify/assert"
@@ -64,3 +76,310 @@ func TestOneStageFails(t *testing.T) {
 	exitCode := RunCLI(env, definition)
 	assert.Equal(t, exitCode, 1)
 }
+
+func TestBuildAndRunCommandsFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	config := "language: python\nbuild: echo 'print(\"hello\", *__import__(\"sys\").argv[1:])' > main.py\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bootllm.yml"), []byte(config), 0644))
+
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "test-1", TestFunc: func(harness *test_case_harness.TestCaseHarness) error {
+				if harness.Language != "python" {
+					return fmt.Errorf("expected language python, got %q", harness.Language)
+				}
+
+				result, err := harness.Executable.Run("world")
+				if err != nil {
+					return err
+				}
+
+				if string(result.Stdout) != "hello world\n" {
+					return fmt.Errorf("unexpected output %q", result.Stdout)
+				}
+
+				return nil
+			}},
+		},
+	}
+
+	env := map[string]string{
+		"BOOTLLM_REPOSITORY_DIR":  dir,
+		"BOOTLLM_TEST_CASES_JSON": buildTestCasesJson([]string{"test-1"}),
+	}
+	assert.Equal(t, 0, RunCLI(env, definition))
+}
+
+func TestBuildFailure(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bootllm.yml"), []byte("build: exit 3\n"), 0644))
+
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "test-1", TestFunc: passFunc},
+		},
+	}
+
+	env := map[string]string{
+		"BOOTLLM_REPOSITORY_DIR":  dir,
+		"BOOTLLM_TEST_CASES_JSON": buildTestCasesJson([]string{"test-1"}),
+	}
+	assert.Equal(t, 1, RunCLI(env, definition))
+}
+
+func TestRunHistoryAndRerunFailed(t *testing.T) {
+	dir := t.TempDir()
+	ranSlugs := []string{}
+	recordingFunc := func(slug string, err error) func(harness *test_case_harness.TestCaseHarness) error {
+		return func(harness *test_case_harness.TestCaseHarness) error {
+			ranSlugs = append(ranSlugs, slug)
+			return err
+		}
+	}
+
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "test-1", TestFunc: recordingFunc("test-1", nil)},
+			{Slug: "test-2", TestFunc: recordingFunc("test-2", errors.New("fail"))},
+			{Slug: "test-3", TestFunc: recordingFunc("test-3", nil)},
+		},
+	}
+
+	assert.Equal(t, ExitFailure, RunCLI(map[string]string{"BOOTLLM_REPOSITORY_DIR": dir}, definition))
+
+	history, err := run_history.Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []run_history.Status{run_history.StatusPassed, run_history.StatusFailed, run_history.StatusNotRun}, []run_history.Status{
+		history.Stages[0].Status, history.Stages[1].Status, history.Stages[2].Status,
+	})
+	assert.Equal(t, "fail", history.Stages[1].Error)
+	assert.Equal(t, 1, history.Stages[1].ConsecutiveFailures)
+
+	ranSlugs = nil
+	assert.Equal(t, ExitFailure, RunCLI(map[string]string{"BOOTLLM_REPOSITORY_DIR": dir, "BOOTLLM_RERUN": "failed"}, definition))
+	assert.Equal(t, []string{"test-2"}, ranSlugs)
+
+	history, err = run_history.Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, history.Stages[1].ConsecutiveFailures)
+	assert.Equal(t, run_history.StatusPassed, history.Stages[0].Status, "stages that didn't run keep their results")
+}
+
+func TestRerunFailedIgnoresGeneratedFiles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.c"), []byte("int main() {}"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bootllm.yml"), []byte("build: date +%s%N > app\n"), 0644))
+
+	ranSlugs := []string{}
+	writingFunc := func(slug string, err error) func(harness *test_case_harness.TestCaseHarness) error {
+		return func(harness *test_case_harness.TestCaseHarness) error {
+			ranSlugs = append(ranSlugs, slug)
+			// Like a build, stages can write different files on every run
+			return errors.Join(os.WriteFile(filepath.Join(dir, slug+".log"), []byte(time.Now().String()), 0644), err)
+		}
+	}
+
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "test-1", TestFunc: writingFunc("test-1", nil)},
+			{Slug: "test-2", TestFunc: writingFunc("test-2", errors.New("fail"))},
+		},
+	}
+
+	assert.Equal(t, ExitFailure, RunCLI(map[string]string{"BOOTLLM_REPOSITORY_DIR": dir}, definition))
+
+	for range 2 {
+		ranSlugs = nil
+		assert.Equal(t, ExitFailure, RunCLI(map[string]string{"BOOTLLM_REPOSITORY_DIR": dir, "BOOTLLM_RERUN": "failed"}, definition))
+		assert.Equal(t, []string{"test-2"}, ranSlugs, "files written by the build and the stages aren't changes")
+	}
+
+	history, err := run_history.Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app", "test-1.log", "test-2.log"}, history.GeneratedFiles)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.c"), []byte("int main() { return 0; }"), 0644))
+
+	ranSlugs = nil
+	assert.Equal(t, ExitFailure, RunCLI(map[string]string{"BOOTLLM_REPOSITORY_DIR": dir, "BOOTLLM_RERUN": "failed"}, definition))
+	assert.Equal(t, []string{"test-1", "test-2"}, ranSlugs)
+}
+
+func TestRunHistoryStoresPlainErrors(t *testing.T) {
+	dir := t.TempDir()
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "test-1", TestFunc: func(harness *test_case_harness.TestCaseHarness) error {
+				return &runner.Mismatch{Expected: "a", Actual: "b", ShowDiff: true}
+			}},
+		},
+	}
+
+	assert.Equal(t, ExitFailure, RunCLI(map[string]string{"BOOTLLM_REPOSITORY_DIR": dir}, definition))
+
+	history, err := run_history.Load(dir)
+	assert.NoError(t, err)
+	assert.Contains(t, history.Stages[0].Error, "+b")
+	assert.NotContains(t, history.Stages[0].Error, "\x1b[")
+}
+
+// captureStdout returns what f writes to os.Stdout
+func captureStdout(t *testing.T, f func()) string {
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	originalStdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = originalStdout }()
+
+	f()
+
+	output, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(output)
+}
+
+func TestHintsRevealedProgressively(t *testing.T) {
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{{Slug: "test-1", TestFunc: failFunc}},
+		Hints:     []hints.Rule{hints.OnMessage("fail", "Read the spec again", "Return nil from the test")},
+	}
+	env := map[string]string{"BOOTLLM_REPOSITORY_DIR": t.TempDir()}
+
+	output := captureStdout(t, func() { assert.Equal(t, ExitFailure, RunCLI(env, definition)) })
+	assert.Contains(t, output, "Hint 1/2: Read the spec again")
+	assert.Contains(t, output, "Another hint will be shown if this stage fails again.")
+	assert.NotContains(t, output, "Return nil from the test")
+
+	output = captureStdout(t, func() { assert.Equal(t, ExitFailure, RunCLI(env, definition)) })
+	assert.Contains(t, output, "Hint 1/2: Read the spec again")
+	assert.Contains(t, output, "Hint 2/2: Return nil from the test")
+	assert.NotContains(t, output, "Another hint")
+}
+
+func TestLocalizedMessages(t *testing.T) {
+	t.Cleanup(func() { i18n.SetLocale(i18n.English) })
+
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{{Slug: "test-1", TestFunc: func(harness *test_case_harness.TestCaseHarness) error {
+			return i18n.Errorf("expected %d coins", 4)
+		}}},
+		Messages: map[i18n.Locale]map[string]string{
+			i18n.Chinese: {"expected %d coins": "应为 %d 枚硬币"},
+		},
+	}
+	env := map[string]string{"BOOTLLM_REPOSITORY_DIR": t.TempDir(), "BOOTLLM_LOCALE": "zh"}
+
+	output := captureStdout(t, func() { assert.Equal(t, ExitFailure, RunCLI(env, definition)) })
+	assert.Contains(t, output, "正在运行 Test 1 的测试")
+	assert.Contains(t, output, "应为 4 枚硬币")
+	assert.Contains(t, output, "测试失败")
+}
+
+func TestSecretsRedacted(t *testing.T) {
+	t.Cleanup(redact.Reset)
+
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{{Slug: "test-1", TestFunc: func(harness *test_case_harness.TestCaseHarness) error {
+			harness.Logger.Infof("using token %s", "s3cr3t-token")
+			return fmt.Errorf("request with s3cr3t-token failed")
+		}}},
+	}
+	env := map[string]string{"BOOTLLM_REPOSITORY_DIR": t.TempDir(), "BOOTLLM_SECRET_TOKEN": "s3cr3t-token"}
+
+	output := captureStdout(t, func() { assert.Equal(t, ExitFailure, RunCLI(env, definition)) })
+	assert.NotContains(t, output, "s3cr3t-token")
+	assert.Contains(t, output, "using token [REDACTED]")
+	assert.Contains(t, output, "request with [REDACTED] failed")
+}
+
+func TestEventStream(t *testing.T) {
+	var buf bytes.Buffer
+	events.Enable(&buf)
+	t.Cleanup(events.Disable)
+
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "test-1", TestFunc: passFunc},
+			{Slug: "test-2", TestFunc: failFunc},
+			{Slug: "test-3", TestFunc: passFunc},
+		},
+		Hints: []hints.Rule{hints.OnMessage("fail", "Read the spec again")},
+	}
+	env := map[string]string{"BOOTLLM_REPOSITORY_DIR": t.TempDir()}
+
+	stdout := captureStdout(t, func() { assert.Equal(t, ExitFailure, RunCLI(env, definition)) })
+	assert.Empty(t, stdout, "logs are only written as events")
+
+	decoded := []events.Event{}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		var event events.Event
+		if assert.NoError(t, json.Unmarshal([]byte(line), &event), "line=%q", line) {
+			decoded = append(decoded, event)
+		}
+	}
+
+	types := []string{}
+	for _, event := range decoded {
+		types = append(types, event.Type+":"+event.Stage)
+	}
+	assert.Equal(t, []string{
+		"stage_started:test-1", "log:test-1", "log:test-1",
+		"stage_started:test-2", "log:test-2", "log:test-2", "log:test-2", "log:test-2", "stage_failed:test-2",
+		"run_finished:",
+	}, types)
+
+	assert.Equal(t, events.Event{Type: events.TypeLog, Stage: "test-1", Level: "info", Prefix: "[stage-1]", Message: "Running tests for Test 1"}, withoutTime(decoded[1]))
+	assert.Equal(t, "fail", decoded[8].Error)
+	assert.Equal(t, []string{"Read the spec again"}, decoded[8].Hints)
+
+	finished := decoded[9]
+	assert.Equal(t, events.StatusFailed, finished.Status)
+	assert.Equal(t, &events.Summary{Passed: 1, Failed: 1, NotRun: 1}, finished.Summary)
+	assert.Equal(t, []string{"test-1:passed", "test-2:failed", "test-3:not_run"}, []string{
+		finished.Stages[0].Slug + ":" + finished.Stages[0].Status,
+		finished.Stages[1].Slug + ":" + finished.Stages[1].Status,
+		finished.Stages[2].Slug + ":" + finished.Stages[2].Status,
+	})
+}
+
+func withoutTime(event events.Event) events.Event {
+	event.Time = time.Time{}
+	return event
+}
+
+func TestCollapsePassedProgramOutput(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bootllm.yml"), []byte("run: echo one; echo two\n"), 0644))
+
+	runProgram := func(harness *test_case_harness.TestCaseHarness) error {
+		_, err := harness.Executable.Run()
+		return err
+	}
+
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "test-1", TestFunc: runProgram},
+			{Slug: "test-2", TestFunc: func(harness *test_case_harness.TestCaseHarness) error {
+				runProgram(harness)
+				return errors.New("fail")
+			}},
+		},
+		CollapsePassedProgramOutput: true,
+	}
+	env := map[string]string{"BOOTLLM_REPOSITORY_DIR": dir}
+
+	output := captureStdout(t, func() { assert.Equal(t, ExitFailure, RunCLI(env, definition)) })
+	output = regexp.MustCompile(`\x1b\[[0-9;]*m`).ReplaceAllString(output, "")
+
+	// Program output of the passed stage is collapsed, the failed stage shows everything
+	assert.Contains(t, output, "[your_program] (2 lines of output hidden since the stage passed")
+	assert.Equal(t, 1, strings.Count(output, "[your_program] one\n"))
+	assert.Less(t, strings.Index(output, "[stage-2] Running tests"), strings.Index(output, "[your_program] one"))
+}
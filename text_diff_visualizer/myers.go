@@ -0,0 +1,173 @@
+package text_diff_visualizer
+
+// Operation is what happens to a line to turn the expected text into the actual text
+type Operation int
+
+const (
+	// Equal lines are in both texts
+	Equal Operation = iota
+	// Delete lines are only in the expected text (missing from the actual text)
+	Delete
+	// Insert lines are only in the actual text (unexpected)
+	Insert
+)
+
+// Line is a line of a diff
+type Line struct {
+	Operation Operation
+	Text      string
+
+	// ExpectedNumber is the line number (starting at 1) in the expected text, 0 for Insert lines
+	ExpectedNumber int
+
+	// ActualNumber is the line number (starting at 1) in the actual text, 0 for Delete lines
+	ActualNumber int
+}
+
+// maxEditDistance bounds the work done by DiffLines. Texts that need more edits than this are shown as entirely
+// replaced, which is correct (if not minimal) and keeps memory usage small for huge outputs.
+const maxEditDistance = 1000
+
+// DiffLines returns a minimal line diff of expected and actual, using Myers' algorithm
+// (http://www.xmailserver.org/diff2.pdf)
+func DiffLines(expected []string, actual []string) []Line {
+	prefixLength := 0
+	for prefixLength < len(expected) && prefixLength < len(actual) && expected[prefixLength] == actual[prefixLength] {
+		prefixLength++
+	}
+
+	suffixLength := 0
+	for suffixLength < len(expected)-prefixLength && suffixLength < len(actual)-prefixLength &&
+		expected[len(expected)-1-suffixLength] == actual[len(actual)-1-suffixLength] {
+		suffixLength++
+	}
+
+	lines := make([]Line, 0, len(expected)+len(actual))
+	for i := 0; i < prefixLength; i++ {
+		lines = append(lines, Line{Operation: Equal, Text: expected[i], ExpectedNumber: i + 1, ActualNumber: i + 1})
+	}
+
+	middle := myers(expected[prefixLength:len(expected)-suffixLength], actual[prefixLength:len(actual)-suffixLength])
+	for _, line := range middle {
+		if line.ExpectedNumber != 0 {
+			line.ExpectedNumber += prefixLength
+		}
+		if line.ActualNumber != 0 {
+			line.ActualNumber += prefixLength
+		}
+		lines = append(lines, line)
+	}
+
+	for i := suffixLength; i > 0; i-- {
+		lines = append(lines, Line{
+			Operation:      Equal,
+			Text:           expected[len(expected)-i],
+			ExpectedNumber: len(expected) - i + 1,
+			ActualNumber:   len(actual) - i + 1,
+		})
+	}
+
+	return lines
+}
+
+// myers diffs a and b. Line numbers in the result are relative to a and b.
+func myers(a []string, b []string) []Line {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b)
+	}
+
+	max := min(n+m, maxEditDistance)
+	offset := max + 1
+	v := make([]int, 2*max+3) // v[offset+k] is the furthest x reached on diagonal k
+
+	// trace[d] holds v[k] for k in [-d-1, d+1] before step d, which is all backtracking needs
+	trace := [][]int{}
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int{}, v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+
+	return replaceAll(a, b)
+}
+
+// backtrack walks trace from the end of both texts back to the start, returning the lines of the diff
+func backtrack(a []string, b []string, trace [][]int) []Line {
+	x, y := len(a), len(b)
+	reversed := []Line{}
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+
+		k := x - y
+		var previousK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+
+		previousX := at(previousK)
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			reversed = append(reversed, Line{Operation: Equal, Text: a[x-1], ExpectedNumber: x, ActualNumber: y})
+			x--
+			y--
+		}
+
+		if d == 0 {
+			break
+		}
+
+		if x == previousX {
+			reversed = append(reversed, Line{Operation: Insert, Text: b[y-1], ActualNumber: y})
+			y--
+		} else {
+			reversed = append(reversed, Line{Operation: Delete, Text: a[x-1], ExpectedNumber: x})
+			x--
+		}
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+
+	return lines
+}
+
+// replaceAll returns a diff deleting all of a and inserting all of b
+func replaceAll(a []string, b []string) []Line {
+	lines := make([]Line, 0, len(a)+len(b))
+	for i, text := range a {
+		lines = append(lines, Line{Operation: Delete, Text: text, ExpectedNumber: i + 1})
+	}
+	for i, text := range b {
+		lines = append(lines, Line{Operation: Insert, Text: text, ActualNumber: i + 1})
+	}
+
+	return lines
+}
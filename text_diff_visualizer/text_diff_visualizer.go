@@ -0,0 +1,181 @@
+// Package text_diff_visualizer shows the difference between two texts as a unified diff of their lines, for outputs
+// that are meant to be read as text (see bytes_diff_visualizer for binary data). VisualizeWhitespaceDiff shows
+// differences in whitespace, which are invisible in a regular diff.
+package text_diff_visualizer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// DefaultContextLines is the number of unchanged lines shown around each change
+const DefaultContextLines = 3
+
+// Hunk is a group of changed lines along with the unchanged lines around them
+type Hunk struct {
+	ExpectedStart int // First line of the hunk in the expected text (starting at 1)
+	ExpectedCount int
+	ActualStart   int // First line of the hunk in the actual text (starting at 1)
+	ActualCount   int
+	Lines         []Line
+}
+
+// Header returns the hunk's header, like "@@ -1,3 +1,4 @@"
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.ExpectedStart, h.ExpectedCount, h.ActualStart, h.ActualCount)
+}
+
+// Diff returns the line diff of expected and actual
+func Diff(expected string, actual string) []Line {
+	return DiffLines(splitLines(expected), splitLines(actual))
+}
+
+// Hunks groups the changes in lines, with up to contextLines unchanged lines before and after each change. Changes
+// closer than 2*contextLines lines are put in the same hunk. A negative contextLines is treated as 0.
+func Hunks(lines []Line, contextLines int) []Hunk {
+	contextLines = max(contextLines, 0)
+	hunks := []Hunk{}
+
+	for start := 0; start < len(lines); {
+		firstChange := start
+		for firstChange < len(lines) && lines[firstChange].Operation == Equal {
+			firstChange++
+		}
+		if firstChange == len(lines) {
+			break
+		}
+
+		// Extend the hunk while the next change is close enough for the contexts to touch
+		end := firstChange
+		for end < len(lines) {
+			nextEqualRunEnd := end
+			for nextEqualRunEnd < len(lines) && lines[nextEqualRunEnd].Operation != Equal {
+				nextEqualRunEnd++
+			}
+			end = nextEqualRunEnd
+
+			nextChange := end
+			for nextChange < len(lines) && lines[nextChange].Operation == Equal {
+				nextChange++
+			}
+			if nextChange == len(lines) || nextChange-end > 2*contextLines {
+				break
+			}
+			end = nextChange
+		}
+
+		hunkStart := max(start, firstChange-contextLines)
+		hunkEnd := min(len(lines), end+contextLines)
+		hunks = append(hunks, newHunk(lines, hunkStart, hunkEnd))
+		start = hunkEnd
+	}
+
+	return hunks
+}
+
+// newHunk returns the hunk made of lines[start:end]
+func newHunk(lines []Line, start int, end int) Hunk {
+	hunk := Hunk{Lines: lines[start:end]}
+
+	// Like diff -u, an empty side starts at the line before the hunk
+	for _, line := range lines[:start] {
+		if line.ExpectedNumber != 0 {
+			hunk.ExpectedStart = line.ExpectedNumber
+		}
+		if line.ActualNumber != 0 {
+			hunk.ActualStart = line.ActualNumber
+		}
+	}
+
+	firstExpected, firstActual := 0, 0
+	for _, line := range hunk.Lines {
+		if line.ExpectedNumber != 0 {
+			hunk.ExpectedCount++
+			if firstExpected == 0 {
+				firstExpected = line.ExpectedNumber
+			}
+		}
+		if line.ActualNumber != 0 {
+			hunk.ActualCount++
+			if firstActual == 0 {
+				firstActual = line.ActualNumber
+			}
+		}
+	}
+
+	if firstExpected != 0 {
+		hunk.ExpectedStart = firstExpected
+	}
+	if firstActual != 0 {
+		hunk.ActualStart = firstActual
+	}
+
+	return hunk
+}
+
+// VisualizeTextDiff visualizes the difference between two texts as a unified diff with line numbers, returning lines
+// to be presented to the user. Lines only in expected are prefixed with "-", lines only in actual with "+".
+//
+// The lines will include ANSI escape codes to colorize the output.
+func VisualizeTextDiff(actual string, expected string) []string {
+	return VisualizeTextDiffWithContext(actual, expected, DefaultContextLines)
+}
+
+// VisualizeTextDiffWithContext is like VisualizeTextDiff, showing contextLines unchanged lines around each change
+func VisualizeTextDiffWithContext(actual string, expected string, contextLines int) []string {
+	if actual == expected {
+		return []string{}
+	}
+
+	lines := Diff(expected, actual)
+	numberWidth := len(strconv.Itoa(max(len(splitLines(expected)), len(splitLines(actual)))))
+
+	output := []string{
+		colorizeString(color.FgHiGreen, "--- expected"),
+		colorizeString(color.FgHiRed, "+++ actual"),
+	}
+
+	for _, hunk := range Hunks(lines, contextLines) {
+		output = append(output, colorizeString(color.FgCyan, hunk.Header()))
+
+		for _, line := range hunk.Lines {
+			numbers := formatLineNumber(line.ExpectedNumber, numberWidth) + " " + formatLineNumber(line.ActualNumber, numberWidth)
+
+			switch line.Operation {
+			case Equal:
+				output = append(output, fmt.Sprintf("%s |  %s", numbers, line.Text))
+			case Delete:
+				output = append(output, numbers+" | "+colorizeString(color.FgHiGreen, "-"+line.Text))
+			case Insert:
+				output = append(output, numbers+" | "+colorizeString(color.FgHiRed, "+"+line.Text))
+			}
+		}
+	}
+
+	return output
+}
+
+// splitLines splits s on "\n". The empty string has no lines.
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	return strings.Split(s, "\n")
+}
+
+// formatLineNumber right-aligns number in width columns, blank for 0
+func formatLineNumber(number int, width int) string {
+	if number == 0 {
+		return strings.Repeat(" ", width)
+	}
+
+	return fmt.Sprintf("%*d", width, number)
+}
+
+func colorizeString(colorToUse color.Attribute, msg string) string {
+	return color.New(colorToUse).Sprint(msg)
+}
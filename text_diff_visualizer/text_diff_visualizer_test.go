@@ -0,0 +1,136 @@
+package text_diff_visualizer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+var ansiEscapeCodes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// operations returns a compact representation of lines, like "=a -b +c"
+func operations(lines []Line) string {
+	symbols := map[Operation]string{Equal: "=", Delete: "-", Insert: "+"}
+
+	parts := []string{}
+	for _, line := range lines {
+		parts = append(parts, symbols[line.Operation]+line.Text)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func TestDiffLines(t *testing.T) {
+	testCases := []struct {
+		expected []string
+		actual   []string
+		diff     string
+	}{
+		{[]string{}, []string{}, ""},
+		{[]string{"a"}, []string{"a"}, "=a"},
+		{[]string{"a"}, []string{}, "-a"},
+		{[]string{}, []string{"a"}, "+a"},
+		{[]string{"a", "b", "c"}, []string{"a", "x", "c"}, "=a -b +x =c"},
+		{[]string{"a", "b", "c", "a", "b", "b", "a"}, []string{"c", "b", "a", "b", "a", "c"}, "-a -b =c +b =a =b -b =a +c"},
+		{[]string{"a", "b"}, []string{"a", "b", "c"}, "=a =b +c"},
+		{[]string{"x", "a", "b"}, []string{"a", "b"}, "-x =a =b"},
+	}
+
+	for _, testCase := range testCases {
+		lines := DiffLines(testCase.expected, testCase.actual)
+		assert.Equal(t, testCase.diff, operations(lines), "expected=%q actual=%q", testCase.expected, testCase.actual)
+
+		// Applying the diff gives back both texts
+		expected, actual := []string{}, []string{}
+		for _, line := range lines {
+			if line.Operation != Insert {
+				expected = append(expected, line.Text)
+				assert.Equal(t, len(expected), line.ExpectedNumber)
+			}
+			if line.Operation != Delete {
+				actual = append(actual, line.Text)
+				assert.Equal(t, len(actual), line.ActualNumber)
+			}
+		}
+		assert.Equal(t, testCase.expected, expected)
+		assert.Equal(t, testCase.actual, actual)
+	}
+}
+
+func TestDiffLinesMaxEditDistance(t *testing.T) {
+	expected, actual := []string{}, []string{}
+	for i := 0; i < 3*maxEditDistance; i++ {
+		expected = append(expected, fmt.Sprintf("expected %d", i))
+		actual = append(actual, fmt.Sprintf("actual %d", i))
+	}
+
+	lines := DiffLines(expected, actual)
+	assert.Len(t, lines, 6*maxEditDistance)
+	assert.Equal(t, Delete, lines[0].Operation)
+	assert.Equal(t, Insert, lines[len(lines)-1].Operation)
+}
+
+func TestHunks(t *testing.T) {
+	expected := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	actual := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+
+	hunks := Hunks(Diff(expected, actual), 2)
+	assert.Len(t, hunks, 2)
+	assert.Equal(t, "@@ -1,5 +1,5 @@", hunks[0].Header())
+	assert.Equal(t, "=1 =2 -3 +three =4 =5", operations(hunks[0].Lines))
+	assert.Equal(t, "@@ -11,2 +11,3 @@", hunks[1].Header())
+	assert.Equal(t, "=11 =12 +13", operations(hunks[1].Lines))
+
+	// Changes with overlapping contexts are merged
+	hunks = Hunks(Diff("a\nb\nc\nd", "A\nb\nc\nD"), 1)
+	assert.Len(t, hunks, 1)
+	assert.Equal(t, "@@ -1,4 +1,4 @@", hunks[0].Header())
+
+	// An empty side starts at the line before the hunk
+	hunks = Hunks(Diff("a\nb\nc", "a\nb\nc\nd"), 0)
+	assert.Equal(t, "@@ -3,0 +4,1 @@", hunks[0].Header())
+
+	// A negative context is treated as 0
+	hunks = Hunks(Diff("a\nb\nc", "a\nB\nc"), -1)
+	assert.Len(t, hunks, 1)
+	assert.Equal(t, "-b +B", operations(hunks[0].Lines))
+
+	assert.Empty(t, Hunks(Diff("same", "same"), 3))
+}
+
+func TestVisualizeTextDiff(t *testing.T) {
+	assert.Empty(t, VisualizeTextDiff("same\n", "same\n"))
+
+	result := VisualizeTextDiff("#\n##\n###\n####\n", "   #\n  ##\n ###\n####\n")
+	for i, line := range result {
+		result[i] = ansiEscapeCodes.ReplaceAllString(line, "")
+	}
+
+	assert.Equal(t, []string{
+		"--- expected",
+		"+++ actual",
+		"@@ -1,5 +1,5 @@",
+		"1   | -   #",
+		"2   | -  ##",
+		"3   | - ###",
+		"  1 | +#",
+		"  2 | +##",
+		"  3 | +###",
+		"4 4 |  ####",
+		"5 5 |  ",
+	}, result)
+}
+
+func TestVisualizeTextDiffColors(t *testing.T) {
+	previousNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = previousNoColor }()
+
+	result := VisualizeTextDiff("b", "a")
+	assert.Equal(t, "1   | \x1b[92m-a\x1b[0m", result[3])
+	assert.Equal(t, "  1 | \x1b[91m+b\x1b[0m", result[4])
+}
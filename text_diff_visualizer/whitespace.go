@@ -0,0 +1,254 @@
+package text_diff_visualizer
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bootllm/tester-utils/i18n"
+	"github.com/fatih/color"
+)
+
+var whitespaceReplacer = strings.NewReplacer(" ", "·", "\t", "→", "\r", "␍", "\n", "↵")
+
+// VisualizeWhitespace makes whitespace in s visible: spaces become "·", tabs "→", carriage returns "␍" and newlines "↵"
+func VisualizeWhitespace(s string) string {
+	return whitespaceReplacer.Replace(s)
+}
+
+// OnlyWhitespaceDiffers returns true if actual and expected are different, but equal once all whitespace is removed
+func OnlyWhitespaceDiffers(actual string, expected string) bool {
+	return actual != expected && removeWhitespace(actual) == removeWhitespace(expected)
+}
+
+// VisualizeWhitespaceDiff is like VisualizeTextDiff, for differences that are hard to see: whitespace is made visible
+// (see VisualizeWhitespace), the first differing character of each changed line is highlighted and pointed at, and
+// the diff is preceded by explanations of the differences (see ExplainWhitespaceDifferences).
+//
+// The lines will include ANSI escape codes to colorize the output.
+func VisualizeWhitespaceDiff(actual string, expected string) []string {
+	if actual == expected {
+		return []string{}
+	}
+
+	expectedLines, actualLines := splitLinesKeepEnds(expected), splitLinesKeepEnds(actual)
+	numberWidth := len(strconv.Itoa(max(len(expectedLines), len(actualLines))))
+	gutter := strings.Repeat(" ", 2*numberWidth+1) + " |  "
+
+	output := ExplainWhitespaceDifferences(actual, expected)
+	output = append(output,
+		colorizeString(color.FgHiGreen, "--- expected"),
+		colorizeString(color.FgHiRed, "+++ actual"),
+	)
+
+	for _, hunk := range Hunks(DiffLines(expectedLines, actualLines), DefaultContextLines) {
+		output = append(output, colorizeString(color.FgCyan, hunk.Header()))
+		pairs := pairChangedLines(hunk.Lines)
+
+		for i, line := range hunk.Lines {
+			numbers := formatLineNumber(line.ExpectedNumber, numberWidth) + " " + formatLineNumber(line.ActualNumber, numberWidth)
+			text := []rune(VisualizeWhitespace(line.Text))
+
+			if line.Operation == Equal {
+				output = append(output, numbers+" |  "+string(text))
+				continue
+			}
+
+			lineColor, sign := color.FgHiGreen, "-"
+			if line.Operation == Insert {
+				lineColor, sign = color.FgHiRed, "+"
+			}
+
+			pair, isPaired := pairs[i]
+			if !isPaired {
+				output = append(output, numbers+" | "+colorizeString(lineColor, sign+string(text)))
+				continue
+			}
+
+			column := firstDifference(text, []rune(VisualizeWhitespace(hunk.Lines[pair].Text)))
+			output = append(output, numbers+" | "+highlightRune(lineColor, sign, text, column))
+
+			if line.Operation == Insert {
+				output = append(output, gutter+strings.Repeat(" ", column)+"^")
+			}
+		}
+	}
+
+	return output
+}
+
+// ExplainWhitespaceDifferences describes the whitespace differences between actual and expected, like
+// "expected trailing newline" or "extra spaces at end of line 3". Line numbers are those of actual. Lines that differ
+// in more than whitespace aren't explained.
+func ExplainWhitespaceDifferences(actual string, expected string) []string {
+	explanations := []string{}
+
+	if strings.HasSuffix(expected, "\n") && !strings.HasSuffix(actual, "\n") {
+		explanations = append(explanations, i18n.T("expected trailing newline"))
+	} else if !strings.HasSuffix(expected, "\n") && strings.HasSuffix(actual, "\n") {
+		explanations = append(explanations, i18n.T("unexpected trailing newline"))
+	}
+
+	expectedLines := splitLines(strings.TrimSuffix(expected, "\n"))
+	actualLines := splitLines(strings.TrimSuffix(actual, "\n"))
+
+	for _, line := range DiffLines(normalizeWhitespace(expectedLines), normalizeWhitespace(actualLines)) {
+		switch line.Operation {
+		case Equal:
+			explanations = append(explanations, explainLine(actualLines[line.ActualNumber-1], expectedLines[line.ExpectedNumber-1], line.ActualNumber)...)
+		case Insert:
+			if line.Text == "" {
+				explanations = append(explanations, i18n.T("unexpected blank line %d", line.ActualNumber))
+			}
+		case Delete:
+			if line.Text == "" {
+				explanations = append(explanations, i18n.T("missing blank line (line %d of the expected output)", line.ExpectedNumber))
+			}
+		}
+	}
+
+	return explanations
+}
+
+// explainLine describes the differences between two lines that only differ in whitespace
+func explainLine(actual string, expected string, number int) []string {
+	if actual == expected {
+		return nil
+	}
+
+	explanations := []string{}
+
+	if strings.HasSuffix(actual, "\r") && !strings.HasSuffix(expected, "\r") {
+		explanations = append(explanations, i18n.T("line %d ends with \\r\\n (Windows line ending), expected \\n", number))
+	} else if !strings.HasSuffix(actual, "\r") && strings.HasSuffix(expected, "\r") {
+		explanations = append(explanations, i18n.T("line %d ends with \\n, expected \\r\\n", number))
+	}
+	actual, expected = strings.TrimSuffix(actual, "\r"), strings.TrimSuffix(expected, "\r")
+
+	if strings.TrimSpace(actual) == "" && strings.TrimSpace(expected) == "" {
+		if len(actual) > len(expected) {
+			explanations = append(explanations, i18n.T("extra spaces on blank line %d", number))
+		} else if len(actual) < len(expected) {
+			explanations = append(explanations, i18n.T("missing spaces on blank line %d", number))
+		}
+
+		return explanations
+	}
+
+	actualIndent, expectedIndent := leadingWhitespace(actual), leadingWhitespace(expected)
+	switch {
+	case strings.Contains(actualIndent, "\t") && !strings.Contains(expectedIndent, "\t"):
+		explanations = append(explanations, i18n.T("line %d is indented with tabs, expected spaces", number))
+	case !strings.Contains(actualIndent, "\t") && strings.Contains(expectedIndent, "\t"):
+		explanations = append(explanations, i18n.T("line %d is indented with spaces, expected tabs", number))
+	case len(actualIndent) > len(expectedIndent):
+		explanations = append(explanations, i18n.T("extra spaces at start of line %d", number))
+	case len(actualIndent) < len(expectedIndent):
+		explanations = append(explanations, i18n.T("missing spaces at start of line %d", number))
+	}
+
+	actualTrailing, expectedTrailing := trailingWhitespace(actual), trailingWhitespace(expected)
+	switch {
+	case len(actualTrailing) > len(expectedTrailing):
+		explanations = append(explanations, i18n.T("extra spaces at end of line %d", number))
+	case len(actualTrailing) < len(expectedTrailing):
+		explanations = append(explanations, i18n.T("missing spaces at end of line %d", number))
+	}
+
+	actualMiddle := strings.TrimRightFunc(strings.TrimPrefix(actual, actualIndent), unicode.IsSpace)
+	expectedMiddle := strings.TrimRightFunc(strings.TrimPrefix(expected, expectedIndent), unicode.IsSpace)
+	if actualMiddle != expectedMiddle {
+		explanations = append(explanations, i18n.T("spaces between words differ on line %d", number))
+	}
+
+	return explanations
+}
+
+// pairChangedLines pairs each deleted line with the inserted line at the same position in the same block of changes,
+// so that changed lines can be compared character by character. Returns the index of the other line of each pair.
+func pairChangedLines(lines []Line) map[int]int {
+	pairs := map[int]int{}
+
+	for start := 0; start < len(lines); {
+		if lines[start].Operation == Equal {
+			start++
+			continue
+		}
+
+		deleted, inserted := []int{}, []int{}
+		end := start
+		for ; end < len(lines) && lines[end].Operation != Equal; end++ {
+			if lines[end].Operation == Delete {
+				deleted = append(deleted, end)
+			} else {
+				inserted = append(inserted, end)
+			}
+		}
+
+		for i := 0; i < len(deleted) && i < len(inserted); i++ {
+			pairs[deleted[i]] = inserted[i]
+			pairs[inserted[i]] = deleted[i]
+		}
+
+		start = end
+	}
+
+	return pairs
+}
+
+// highlightRune colorizes sign and text, with the rune at column (if any) in reverse video
+func highlightRune(lineColor color.Attribute, sign string, text []rune, column int) string {
+	if column >= len(text) {
+		return colorizeString(lineColor, sign+string(text))
+	}
+
+	highlighted := colorizeString(lineColor, sign+string(text[:column])) +
+		color.New(lineColor, color.ReverseVideo).Sprint(string(text[column]))
+	if column+1 < len(text) {
+		highlighted += colorizeString(lineColor, string(text[column+1:]))
+	}
+
+	return highlighted
+}
+
+// firstDifference returns the index of the first rune that differs between a and b
+func firstDifference(a []rune, b []rune) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
+
+// splitLinesKeepEnds splits s after each "\n", keeping it at the end of the lines
+func splitLinesKeepEnds(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// normalizeWhitespace returns lines with leading and trailing whitespace removed and inner whitespace collapsed
+func normalizeWhitespace(lines []string) []string {
+	normalized := make([]string, len(lines))
+	for i, line := range lines {
+		normalized[i] = strings.Join(strings.Fields(line), " ")
+	}
+
+	return normalized
+}
+
+func removeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+func leadingWhitespace(s string) string {
+	return s[:len(s)-len(strings.TrimLeftFunc(s, unicode.IsSpace))]
+}
+
+func trailingWhitespace(s string) string {
+	return s[len(strings.TrimRightFunc(s, unicode.IsSpace)):]
+}
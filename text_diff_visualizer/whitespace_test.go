@@ -0,0 +1,79 @@
+package text_diff_visualizer
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisualizeWhitespace(t *testing.T) {
+	assert.Equal(t, "a·b→c␍↵", VisualizeWhitespace("a b\tc\r\n"))
+}
+
+func TestOnlyWhitespaceDiffers(t *testing.T) {
+	assert.True(t, OnlyWhitespaceDiffers("a \nb", "a\nb\n"))
+	assert.False(t, OnlyWhitespaceDiffers("a\nb", "a\nb"))
+	assert.False(t, OnlyWhitespaceDiffers("a\nb", "a\nc"))
+}
+
+func TestExplainWhitespaceDifferences(t *testing.T) {
+	testCases := []struct {
+		actual       string
+		expected     string
+		explanations []string
+	}{
+		{"hello", "hello\n", []string{"expected trailing newline"}},
+		{"hello\n", "hello", []string{"unexpected trailing newline"}},
+		{"a\nb  \nc\n", "a\nb\nc\n", []string{"extra spaces at end of line 2"}},
+		{"a\nb\n", "a\nb \n", []string{"missing spaces at end of line 2"}},
+		{"\tif\n", "    if\n", []string{"line 1 is indented with tabs, expected spaces"}},
+		{"    if\n", "\tif\n", []string{"line 1 is indented with spaces, expected tabs"}},
+		{"   #\n", "  #\n", []string{"extra spaces at start of line 1"}},
+		{"a\r\nb\r\n", "a\nb\n", []string{"line 1 ends with \\r\\n (Windows line ending), expected \\n", "line 2 ends with \\r\\n (Windows line ending), expected \\n"}},
+		{"a  b\n", "a b\n", []string{"spaces between words differ on line 1"}},
+		{"a\n  \nb\n", "a\n\nb\n", []string{"extra spaces on blank line 2"}},
+		{"a\n\nb\n", "a\nb\n", []string{"unexpected blank line 2"}},
+		{"a\nb\n", "a\n\nb\n", []string{"missing blank line (line 2 of the expected output)"}},
+		{"a\nx \n", "a\nb\n", []string{}},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.explanations, ExplainWhitespaceDifferences(testCase.actual, testCase.expected), "actual=%q expected=%q", testCase.actual, testCase.expected)
+	}
+}
+
+func TestVisualizeWhitespaceDiff(t *testing.T) {
+	assert.Empty(t, VisualizeWhitespaceDiff("same", "same"))
+
+	result := VisualizeWhitespaceDiff("a\nb  \nc", "a\nb\nc\n")
+	for i, line := range result {
+		result[i] = ansiEscapeCodes.ReplaceAllString(line, "")
+	}
+
+	assert.Equal(t, []string{
+		"expected trailing newline",
+		"extra spaces at end of line 2",
+		"--- expected",
+		"+++ actual",
+		"@@ -1,3 +1,3 @@",
+		"1 1 |  a↵",
+		"2   | -b↵",
+		"3   | -c↵",
+		"  2 | +b··↵",
+		"    |   ^",
+		"  3 | +c",
+		"    |   ^",
+	}, result)
+}
+
+func TestVisualizeWhitespaceDiffHighlight(t *testing.T) {
+	previousNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = previousNoColor }()
+
+	result := VisualizeWhitespaceDiff("ab ", "ab")
+	assert.Equal(t, "1   | \x1b[92m-ab\x1b[0m", result[4])
+	assert.Equal(t, "  1 | \x1b[91m+ab\x1b[0m\x1b[91;7m·\x1b[0;27m", result[5])
+	assert.Equal(t, "    |    ^", result[6])
+}
@@ -0,0 +1,245 @@
+package udp_client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bootllm/tester-utils/inspectable_byte_string"
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/tcp_client"
+)
+
+// DefaultTimeout is the default time to wait for a datagram in Expect* methods
+const DefaultTimeout = 2 * time.Second
+
+// maxDatagramSize is large enough to hold any UDP payload
+const maxDatagramSize = 65535
+
+// Client is a datagram client (UDP or Unix datagram socket) that talks to the program under test.
+//
+// It mirrors the API of tcp_client.Client, but assertions work on whole datagrams instead of a byte stream. All
+// traffic is logged through the given Logger (nothing is logged if it's nil).
+//
+//	client, err := udp_client.Dial("localhost:5353", harness.Logger)
+//	if err != nil {
+//	    return err
+//	}
+//	defer client.Close()
+//
+//	if err := client.Send([]byte("ping")); err != nil {
+//	    return err
+//	}
+//	return client.ExpectDatagram([]byte("pong"))
+type Client struct {
+	// Timeout is the maximum time Expect* methods wait for a datagram. Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	conn   net.Conn
+	logger *logger.Logger
+
+	// localSocketPath is the path of the socket bound for replies when using Unix datagram sockets
+	localSocketPath string
+}
+
+// Dial creates a UDP client that sends datagrams to address (e.g. "localhost:5353")
+func Dial(address string, l *logger.Logger) (*Client, error) {
+	l = quietIfNil(l)
+	l.Debugf("Opening UDP socket to %s...", address)
+
+	conn, err := net.DialTimeout("udp", address, DefaultTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open UDP socket to %s: %v", address, err)
+	}
+
+	return NewClient(conn, l), nil
+}
+
+// DialUnix creates a client that sends datagrams to a program listening on a Unix datagram socket at path.
+//
+// The client binds its own temporary socket so that the program can reply, it's removed on Close.
+func DialUnix(path string, l *logger.Logger) (*Client, error) {
+	l = quietIfNil(l)
+	l.Debugf("Opening Unix datagram socket to %s...", path)
+
+	tempDir, err := os.MkdirTemp("", "udp-client")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+
+	localSocketPath := filepath.Join(tempDir, "client.sock")
+
+	conn, err := net.DialUnix("unixgram", &net.UnixAddr{Name: localSocketPath, Net: "unixgram"}, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return nil, fmt.Errorf("failed to open Unix datagram socket to %s: %v", path, err)
+	}
+
+	client := NewClient(conn, l)
+	client.localSocketPath = localSocketPath
+	return client, nil
+}
+
+// NewClient wraps an existing datagram connection
+func NewClient(conn net.Conn, logger *logger.Logger) *Client {
+	return &Client{
+		Timeout: DefaultTimeout,
+		conn:    conn,
+		logger:  quietIfNil(logger),
+	}
+}
+
+// quietIfNil returns l, or a logger that doesn't print anything if l is nil
+func quietIfNil(l *logger.Logger) *logger.Logger {
+	if l == nil {
+		return logger.GetQuietLogger("")
+	}
+
+	return l
+}
+
+// Conn returns the underlying connection
+func (c *Client) Conn() net.Conn {
+	return c.conn
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	err := c.conn.Close()
+
+	if c.localSocketPath != "" {
+		os.RemoveAll(filepath.Dir(c.localSocketPath))
+	}
+
+	return err
+}
+
+// Send sends data as a single datagram
+func (c *Client) Send(data []byte) error {
+	c.logger.Infof("Sent datagram %s", formatBytes(data))
+
+	if _, err := c.conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send datagram %s: %v", formatBytes(data), err)
+	}
+
+	return nil
+}
+
+// Receive waits for the next datagram and returns its payload
+func (c *Client) Receive() ([]byte, error) {
+	return c.receive(c.Timeout)
+}
+
+// ExpectDatagram waits for the next datagram and checks that its payload matches expected exactly.
+//
+// On mismatch, the error highlights the offset of the first byte that differs.
+func (c *Client) ExpectDatagram(expected []byte) error {
+	received, err := c.receive(c.Timeout)
+	if err != nil {
+		return wrapReceiveError(err, fmt.Sprintf("waiting for datagram %s", formatBytes(expected)), c.Timeout)
+	}
+
+	if mismatchError := tcp_client.NewMismatchError(expected, received); mismatchError != nil {
+		return mismatchError
+	}
+
+	c.logger.Successf("Received datagram %s", formatBytes(received))
+	return nil
+}
+
+// ExpectDatagramsUnordered waits for len(expected) datagrams and checks that their payloads match expected, in any
+// order. This is useful for protocols where replies to concurrent requests may be reordered.
+func (c *Client) ExpectDatagramsUnordered(expected [][]byte) error {
+	remaining := append([][]byte{}, expected...)
+	deadline := time.Now().Add(c.Timeout)
+
+	for len(remaining) > 0 {
+		received, err := c.receive(time.Until(deadline))
+		if err != nil {
+			return wrapReceiveError(err, fmt.Sprintf("waiting for %d more datagram(s): %s", len(remaining), formatDatagrams(remaining)), c.Timeout)
+		}
+
+		index := indexOfDatagram(remaining, received)
+		if index == -1 {
+			return fmt.Errorf("received unexpected datagram %s\nStill expecting: %s", formatBytes(received), formatDatagrams(remaining))
+		}
+
+		c.logger.Successf("Received datagram %s", formatBytes(received))
+		remaining = append(remaining[:index], remaining[index+1:]...)
+	}
+
+	return nil
+}
+
+// ExpectNoDatagram checks that no datagram arrives within duration
+func (c *Client) ExpectNoDatagram(duration time.Duration) error {
+	received, err := c.receive(duration)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		c.logger.Successf("No datagram received in %v", duration)
+		return nil
+	}
+
+	if err != nil {
+		return wrapReceiveError(err, "checking that no datagram is received", duration)
+	}
+
+	return fmt.Errorf("expected no datagram within %v, received %s", duration, formatBytes(received))
+}
+
+func (c *Client) receive(timeout time.Duration) ([]byte, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, maxDatagramSize)
+
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Debugf("Received datagram: %s", formatBytes(buf[:n]))
+	return buf[:n], nil
+}
+
+// wrapReceiveError converts low-level read errors into user-friendly ones
+func wrapReceiveError(err error, action string, timeout time.Duration) error {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return fmt.Errorf("timed out after %v %s\nNo reply received.", timeout, action)
+	}
+
+	// A UDP "connection refused" means an ICMP port unreachable was received: nothing is listening
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("error while %s: %v\nIs the program listening on the expected address?", action, err)
+	}
+
+	return fmt.Errorf("error while %s: %v", action, err)
+}
+
+func indexOfDatagram(datagrams [][]byte, datagram []byte) int {
+	for i, candidate := range datagrams {
+		if string(candidate) == string(datagram) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func formatDatagrams(datagrams [][]byte) string {
+	formatted := make([]string, len(datagrams))
+	for i, datagram := range datagrams {
+		formatted[i] = formatBytes(datagram)
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+func formatBytes(data []byte) string {
+	return inspectable_byte_string.NewInspectableByteString(data).FormattedString()
+}
@@ -0,0 +1,150 @@
+package udp_client
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/tcp_client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer starts a UDP server that calls handler for every datagram and sends back the replies it returns
+func startServer(t *testing.T, handler func(datagram []byte) [][]byte) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go serve(conn, handler)
+
+	return conn.LocalAddr().String()
+}
+
+func serve(conn net.PacketConn, handler func(datagram []byte) [][]byte) {
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		for _, reply := range handler(buf[:n]) {
+			conn.WriteTo(reply, addr)
+		}
+	}
+}
+
+func dialTo(t *testing.T, address string) *Client {
+	client, err := Dial(address, logger.GetLogger(true, "[test] "))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func echoHandler(datagram []byte) [][]byte {
+	return [][]byte{datagram}
+}
+
+func TestExpectDatagram(t *testing.T) {
+	client := dialTo(t, startServer(t, echoHandler))
+
+	require.NoError(t, client.Send([]byte("ping")))
+	assert.NoError(t, client.ExpectDatagram([]byte("ping")))
+}
+
+func TestExpectDatagram_Mismatch(t *testing.T) {
+	client := dialTo(t, startServer(t, echoHandler))
+
+	require.NoError(t, client.Send([]byte("pong\n")))
+	err := client.ExpectDatagram([]byte("pong"))
+
+	var mismatchError *tcp_client.MismatchError
+	require.ErrorAs(t, err, &mismatchError)
+	assert.Equal(t, 4, mismatchError.Offset)
+}
+
+func TestExpectDatagram_Timeout(t *testing.T) {
+	client := dialTo(t, startServer(t, func(datagram []byte) [][]byte { return nil }))
+	client.Timeout = 100 * time.Millisecond
+
+	require.NoError(t, client.Send([]byte("ping")))
+	err := client.ExpectDatagram([]byte("pong"))
+	require.Error(t, err)
+	assert.Equal(t, "timed out after 100ms waiting for datagram \"pong\"\nNo reply received.", err.Error())
+}
+
+func TestExpectDatagramsUnordered(t *testing.T) {
+	client := dialTo(t, startServer(t, func(datagram []byte) [][]byte {
+		return [][]byte{[]byte("b"), []byte("a"), []byte("c")}
+	}))
+
+	require.NoError(t, client.Send([]byte("go")))
+	assert.NoError(t, client.ExpectDatagramsUnordered([][]byte{[]byte("a"), []byte("b"), []byte("c")}))
+}
+
+func TestExpectDatagramsUnordered_Unexpected(t *testing.T) {
+	client := dialTo(t, startServer(t, func(datagram []byte) [][]byte {
+		return [][]byte{[]byte("b"), []byte("x")}
+	}))
+
+	require.NoError(t, client.Send([]byte("go")))
+	err := client.ExpectDatagramsUnordered([][]byte{[]byte("a"), []byte("b")})
+	require.Error(t, err)
+	assert.Equal(t, "received unexpected datagram \"x\"\nStill expecting: \"a\"", err.Error())
+}
+
+func TestExpectNoDatagram(t *testing.T) {
+	client := dialTo(t, startServer(t, func(datagram []byte) [][]byte {
+		if string(datagram) == "quiet" {
+			return nil
+		}
+		return [][]byte{datagram}
+	}))
+
+	require.NoError(t, client.Send([]byte("quiet")))
+	assert.NoError(t, client.ExpectNoDatagram(100*time.Millisecond))
+
+	require.NoError(t, client.Send([]byte("loud")))
+	assert.EqualError(t, client.ExpectNoDatagram(time.Second), "expected no datagram within 1s, received \"loud\"")
+}
+
+func TestDialUnix(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "server.sock")
+
+	conn, err := net.ListenPacket("unixgram", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go serve(conn, echoHandler)
+
+	client, err := DialUnix(socketPath, logger.GetLogger(true, "[test] "))
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.Send([]byte("hello")))
+	assert.NoError(t, client.ExpectDatagram([]byte("hello")))
+}
+
+func TestDial_NilLogger(t *testing.T) {
+	client, err := Dial(startServer(t, echoHandler), nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.Send([]byte("ping")))
+	assert.NoError(t, client.ExpectDatagram([]byte("ping")))
+}
+
+func TestExpectDatagram_NothingListening(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := conn.LocalAddr().String()
+	conn.Close()
+
+	client := dialTo(t, address)
+	require.NoError(t, client.Send([]byte("ping")))
+	err = client.ExpectDatagram([]byte("pong"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Is the program listening on the expected address?")
+}
@@ -0,0 +1,164 @@
+package tester_utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bootllm/tester-utils/build"
+	"github.com/bootllm/tester-utils/executable"
+	"github.com/bootllm/tester-utils/internal"
+	"github.com/bootllm/tester-utils/random"
+	"github.com/bootllm/tester-utils/redact"
+	"github.com/bootllm/tester-utils/tester_definition"
+	"github.com/bootllm/tester-utils/watcher"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// watchDebounce is how long to wait after the last change before re-running, so that saving several files (or an
+// editor writing one file in several steps) only triggers one run
+const watchDebounce = 300 * time.Millisecond
+
+// clearScreen moves the cursor to the top-left corner and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// runWatch runs the selected stages, then re-runs them (after rebuilding) whenever a file in the submission directory
+// changes. Returns when the watcher fails; users stop it with Ctrl+C, which exits the process.
+func runWatch(env map[string]string, definition tester_definition.TesterDefinition) int {
+	w, err := watcher.New(submissionDirFromEnv(env))
+	if err != nil {
+		fmt.Printf("Can't watch %s for changes: %v\n", submissionDirFromEnv(env), err)
+		return ExitFailure
+	}
+	defer w.Close()
+	defer build.RemoveTemporaryCache()
+
+	exitOnSignal(func() {
+		w.Close()
+		build.RemoveTemporaryCache()
+	})
+
+	waitForChanges := func() ([]string, error) {
+		// Files written by the build or by the program itself shouldn't trigger another run
+		w.Drain()
+		return w.WaitForChanges(watchDebounce)
+	}
+
+	return watchLoop(os.Stdout, env, definition, w.Dir(), waitForChanges, isatty.IsTerminal(os.Stdout.Fd()))
+}
+
+// exitOnSignal exits the process on SIGINT or SIGTERM, after killing the programs started by the running stage and
+// calling cleanup. Programs run in their own process groups, so they wouldn't receive the signal and would be left
+// running otherwise.
+func exitOnSignal(cleanup func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		executable.KillRunningProcessGroups()
+		cleanup()
+
+		// Like shells, exit with 128 + the signal number
+		os.Exit(128 + int(sig.(syscall.Signal)))
+	}()
+}
+
+// watchLoop runs the tester, prints a summary and waits for changes, until waitForChanges returns an error
+func watchLoop(w io.Writer, env map[string]string, definition tester_definition.TesterDefinition, dir string, waitForChanges func() ([]string, error), shouldClearScreen bool) int {
+	random.Init()
+
+	var changedPaths []string
+
+	for {
+		if shouldClearScreen {
+			fmt.Fprint(w, clearScreen)
+		}
+
+		if len(changedPaths) > 0 {
+			fmt.Fprintf(w, "Changed: %s\n\n", strings.Join(changedPaths, ", "))
+		}
+
+		tester, err := newTester(env, definition)
+		if err != nil {
+			// Errors in bootllm.yml can be fixed without restarting
+			fmt.Fprintln(w, err.Error())
+		} else {
+			tester.printDebugContext()
+			tester.printConfigWarnings()
+			printWatchSummary(w, tester.run())
+		}
+
+		fmt.Fprintf(w, "\nWatching %s for changes. Press Ctrl+C to stop.\n", dir)
+
+		changedPaths, err = waitForChanges()
+		if errors.Is(err, watcher.ErrClosed) {
+			return ExitSuccess
+		}
+		if err != nil {
+			fmt.Fprintf(w, "Stopped watching for changes: %v\n", err)
+			return ExitFailure
+		}
+	}
+}
+
+// printWatchSummary prints one line per stage, followed by the totals. Example:
+//
+//	✓ hello (0.12s)
+//	✗ mario-less: expected "#" on line 1, got "##"
+//	· mario-more (not run)
+//
+//	1 passed, 1 failed, 1 not run
+func printWatchSummary(w io.Writer, report runReport) {
+	passedStyle := color.New(color.FgHiGreen).SprintFunc()
+	failedStyle := color.New(color.FgHiRed).SprintFunc()
+	notRunStyle := color.New(color.FgHiBlack).SprintFunc()
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("─", 40))
+
+	if report.buildFailed {
+		fmt.Fprintln(w, failedStyle("✗ Build failed"))
+	}
+
+	passed, failed := 0, 0
+	for _, stage := range report.stages {
+		slug := stage.Step.TestCase.Slug
+
+		if stage.Passed() {
+			passed++
+			fmt.Fprintln(w, passedStyle(fmt.Sprintf("✓ %s (%.2fs)", slug, stage.Duration.Seconds())))
+			continue
+		}
+
+		failed++
+		fmt.Fprintln(w, failedStyle(fmt.Sprintf("✗ %s: %s", slug, firstLine(stage.Err))))
+	}
+
+	for _, testCase := range report.notRun {
+		fmt.Fprintln(w, notRunStyle(fmt.Sprintf("· %s (not run)", testCase.Slug)))
+	}
+
+	if report.antiCheatFailed {
+		fmt.Fprintln(w, failedStyle("✗ Anti-cheat checks failed"))
+	}
+
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d not run\n", passed, failed, len(report.notRun))
+}
+
+// firstLine returns the first line of err's message, so that each stage takes a single line in the summary
+func firstLine(err error) string {
+	message := err.Error()
+	if userError, ok := err.(*internal.UserError); ok {
+		message = userError.Message
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(redact.String(message)), "\n")
+	return line
+}
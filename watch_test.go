@@ -0,0 +1,73 @@
+package tester_utils
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bootllm/tester-utils/tester_definition"
+	"github.com/bootllm/tester-utils/watcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchLoop(t *testing.T) {
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{
+			{Slug: "test-1", TestFunc: passFunc},
+			{Slug: "test-2", TestFunc: failFunc},
+			{Slug: "test-3", TestFunc: passFunc},
+		},
+	}
+	env := map[string]string{"BOOTLLM_REPOSITORY_DIR": t.TempDir()}
+
+	waits := 0
+	waitForChanges := func() ([]string, error) {
+		waits++
+		if waits == 1 {
+			return []string{"main.c"}, nil
+		}
+
+		return nil, watcher.ErrClosed
+	}
+
+	var buf bytes.Buffer
+	exitCode := watchLoop(&buf, env, definition, "/submission", waitForChanges, true)
+
+	assert.Equal(t, ExitSuccess, exitCode)
+	assert.Equal(t, 2, waits)
+
+	output := buf.String()
+	assert.Equal(t, 2, strings.Count(output, clearScreen))
+	assert.Contains(t, output, "Changed: main.c")
+	assert.Contains(t, output, "✓ test-1 (")
+	assert.Contains(t, output, "✗ test-2: fail")
+	assert.Contains(t, output, "· test-3 (not run)")
+	assert.Contains(t, output, "1 passed, 1 failed, 1 not run")
+	assert.Contains(t, output, "Watching /submission for changes.")
+}
+
+func TestWatchLoop_WatcherError(t *testing.T) {
+	definition := tester_definition.TesterDefinition{
+		TestCases: []tester_definition.TestCase{{Slug: "test-1", TestFunc: passFunc}},
+	}
+	env := map[string]string{"BOOTLLM_REPOSITORY_DIR": t.TempDir()}
+
+	var buf bytes.Buffer
+	exitCode := watchLoop(&buf, env, definition, "/submission", func() ([]string, error) {
+		return nil, errors.New("too many open files")
+	}, false)
+
+	assert.Equal(t, ExitFailure, exitCode)
+	assert.NotContains(t, buf.String(), clearScreen)
+	assert.Contains(t, buf.String(), "Stopped watching for changes: too many open files")
+}
+
+func TestParseArgs_Watch(t *testing.T) {
+	args, err := ParseArgs([]string{"--watch", "hello"})
+	assert.NoError(t, err)
+	assert.Equal(t, CLIArgs{Command: CommandRun, Stage: "hello", Watch: true}, args)
+
+	_, err = ParseArgs([]string{"list", "--watch"})
+	assert.EqualError(t, err, "--watch can't be used with the list command")
+}
@@ -0,0 +1,161 @@
+// Package watcher reports changes to the files in a directory tree. It uses inotify on Linux, and polls the directory
+// tree on other platforms.
+package watcher
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by WaitForChanges once the watcher is closed
+var ErrClosed = errors.New("watcher closed")
+
+// Watcher watches a directory tree for changes. Hidden files and directories (like .git) and editor backup files are
+// ignored.
+type Watcher struct {
+	dir string
+
+	changes chan string // Paths of changed files, relative to dir
+	errors  chan error
+	done    chan struct{}
+
+	closeOnce sync.Once
+	platform  *platformWatcher
+}
+
+// New starts watching dir and all its subdirectories
+func New(dir string) (*Watcher, error) {
+	absoluteDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		dir:     absoluteDir,
+		changes: make(chan string, 256),
+		errors:  make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+
+	if err := w.start(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Dir returns the absolute path of the watched directory
+func (w *Watcher) Dir() string {
+	return w.dir
+}
+
+// Changes returns a channel that receives the path (relative to Dir) of every changed file
+func (w *Watcher) Changes() <-chan string {
+	return w.changes
+}
+
+// WaitForChanges blocks until a file changes, then keeps collecting changes until none happen for debounce. Returns
+// the changed paths (relative to Dir), sorted.
+func (w *Watcher) WaitForChanges(debounce time.Duration) ([]string, error) {
+	changed := map[string]bool{}
+
+	select {
+	case path := <-w.changes:
+		changed[path] = true
+	case err := <-w.errors:
+		return nil, err
+	case <-w.done:
+		return nil, ErrClosed
+	}
+
+	timer := time.NewTimer(debounce)
+	defer timer.Stop()
+
+	for {
+		select {
+		case path := <-w.changes:
+			changed[path] = true
+			timer.Reset(debounce)
+		case err := <-w.errors:
+			return nil, err
+		case <-w.done:
+			return nil, ErrClosed
+		case <-timer.C:
+			paths := make([]string, 0, len(changed))
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+
+			return paths, nil
+		}
+	}
+}
+
+// Drain discards changes that haven't been received yet. Useful after running a build, which writes files that
+// shouldn't trigger another run.
+func (w *Watcher) Drain() {
+	for {
+		select {
+		case <-w.changes:
+		default:
+			return
+		}
+	}
+}
+
+// Close stops watching. Pending and future calls to WaitForChanges return ErrClosed.
+func (w *Watcher) Close() error {
+	var err error
+
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.stop()
+	})
+
+	return err
+}
+
+// notify reports a change to path (an absolute path) unless it's ignored. Changes are dropped if the buffer is full:
+// a run is already pending in that case.
+func (w *Watcher) notify(path string) {
+	relativePath, err := filepath.Rel(w.dir, path)
+	if err != nil || shouldIgnore(relativePath) {
+		return
+	}
+
+	select {
+	case w.changes <- relativePath:
+	default:
+	}
+}
+
+func (w *Watcher) reportError(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+// shouldIgnore returns true for paths (relative to the watched directory) that shouldn't trigger a run: hidden files
+// and directories, and backup or swap files written by editors.
+func shouldIgnore(relativePath string) bool {
+	if relativePath == "." {
+		return false
+	}
+
+	for _, part := range strings.Split(filepath.ToSlash(relativePath), "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+
+	name := filepath.Base(relativePath)
+
+	// Vim writes "4913" to check if a directory is writable
+	return strings.HasSuffix(name, "~") || name == "4913"
+}
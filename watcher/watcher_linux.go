@@ -0,0 +1,134 @@
+//go:build linux
+
+package watcher
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_DELETE | unix.IN_MOVED_FROM |
+	unix.IN_MOVED_TO | unix.IN_ATTRIB
+
+// platformWatcher watches directories with inotify. Watches are added for every directory, since inotify isn't
+// recursive.
+type platformWatcher struct {
+	fd      int
+	file    *os.File       // Wraps fd so that reads go through the runtime poller, and Close interrupts them
+	watches map[int]string // Watch descriptor -> absolute directory path
+}
+
+func (w *Watcher) start() error {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return err
+	}
+
+	w.platform = &platformWatcher{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		watches: map[int]string{},
+	}
+
+	if err := w.addTree(w.dir); err != nil {
+		w.platform.file.Close()
+		return err
+	}
+
+	go w.readEvents()
+
+	return nil
+}
+
+func (w *Watcher) stop() error {
+	return w.platform.file.Close()
+}
+
+// addTree adds a watch for dir and all its subdirectories that aren't ignored
+func (w *Watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// The directory may have been removed since it was created
+			if errors.Is(err, fs.ErrNotExist) && path != w.dir {
+				return nil
+			}
+
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if relativePath, _ := filepath.Rel(w.dir, path); shouldIgnore(relativePath) {
+			return filepath.SkipDir
+		}
+
+		wd, err := unix.InotifyAddWatch(w.platform.fd, path, inotifyMask)
+		if err != nil {
+			return err
+		}
+
+		w.platform.watches[wd] = path
+		return nil
+	})
+}
+
+func (w *Watcher) readEvents() {
+	buffer := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+
+	for {
+		n, err := w.platform.file.Read(buffer)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				w.reportError(err)
+			}
+
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			name := strings.TrimRight(string(buffer[nameStart:nameStart+int(event.Len)]), "\x00")
+			offset = nameStart + int(event.Len)
+
+			w.handleEvent(event, name)
+		}
+	}
+}
+
+func (w *Watcher) handleEvent(event *unix.InotifyEvent, name string) {
+	// The kernel dropped events, so we don't know what changed
+	if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+		w.notify(w.dir)
+		return
+	}
+
+	dir, ok := w.platform.watches[int(event.Wd)]
+	if !ok {
+		return
+	}
+
+	// The watched directory was removed
+	if event.Mask&unix.IN_IGNORED != 0 {
+		delete(w.platform.watches, int(event.Wd))
+		return
+	}
+
+	path := filepath.Join(dir, name)
+
+	if event.Mask&unix.IN_ISDIR != 0 && event.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+		if err := w.addTree(path); err != nil {
+			w.reportError(err)
+		}
+	}
+
+	w.notify(path)
+}
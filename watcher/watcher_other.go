@@ -0,0 +1,111 @@
+//go:build !linux
+
+package watcher
+
+import (
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval is how often the directory tree is scanned on platforms without inotify support
+const pollInterval = 500 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// platformWatcher polls the directory tree on non-Linux platforms
+type platformWatcher struct {
+	files map[string]fileState // Absolute path -> state at the last scan
+}
+
+func (w *Watcher) start() error {
+	files, err := w.scan()
+	if err != nil {
+		return err
+	}
+
+	w.platform = &platformWatcher{files: files}
+
+	go w.poll()
+
+	return nil
+}
+
+// stop is a no-op, since poll stops when w.done is closed
+func (w *Watcher) stop() error {
+	return nil
+}
+
+func (w *Watcher) poll() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		files, err := w.scan()
+		if err != nil {
+			w.reportError(err)
+			return
+		}
+
+		for path, state := range files {
+			if previousState, ok := w.platform.files[path]; !ok || previousState != state {
+				w.notify(path)
+			}
+		}
+
+		for path := range w.platform.files {
+			if _, ok := files[path]; !ok {
+				w.notify(path)
+			}
+		}
+
+		w.platform.files = files
+	}
+}
+
+// scan returns the state of every file in the directory tree that isn't ignored
+func (w *Watcher) scan() (map[string]fileState, error) {
+	files := map[string]fileState{}
+
+	err := filepath.WalkDir(w.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files may be removed while scanning
+			if path != w.dir {
+				return nil
+			}
+
+			return err
+		}
+
+		if relativePath, _ := filepath.Rel(w.dir, path); shouldIgnore(relativePath) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+
+	return files, err
+}
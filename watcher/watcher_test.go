@@ -0,0 +1,116 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDebounce = 200 * time.Millisecond
+
+func newTestWatcher(t *testing.T) (*Watcher, string) {
+	dir := t.TempDir()
+
+	w, err := New(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { w.Close() })
+
+	return w, dir
+}
+
+func waitForChanges(t *testing.T, w *Watcher) []string {
+	type result struct {
+		paths []string
+		err   error
+	}
+
+	resultChannel := make(chan result, 1)
+	go func() {
+		paths, err := w.WaitForChanges(testDebounce)
+		resultChannel <- result{paths, err}
+	}()
+
+	select {
+	case r := <-resultChannel:
+		require.NoError(t, r.err)
+		return r.paths
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for changes")
+		return nil
+	}
+}
+
+func TestWaitForChanges(t *testing.T) {
+	w, dir := newTestWatcher(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.c"), []byte("int main() {}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "helpers.h"), []byte(""), 0644))
+
+	assert.Equal(t, []string{"helpers.h", "main.c"}, waitForChanges(t, w))
+}
+
+func TestWatchesNewSubdirectories(t *testing.T) {
+	w, dir := newTestWatcher(t)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "src"), 0755))
+	waitForChanges(t, w)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "lib.c"), []byte(""), 0644))
+	assert.Contains(t, waitForChanges(t, w), filepath.Join("src", "lib.c"))
+}
+
+func TestIgnoresHiddenFiles(t *testing.T) {
+	w, dir := newTestWatcher(t)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "index"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".main.c.swp"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.c"), []byte(""), 0644))
+
+	assert.Equal(t, []string{"main.c"}, waitForChanges(t, w))
+}
+
+func TestDrain(t *testing.T) {
+	w, dir := newTestWatcher(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main"), []byte("binary"), 0755))
+	time.Sleep(testDebounce)
+	w.Drain()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.c"), []byte(""), 0644))
+	assert.Equal(t, []string{"main.c"}, waitForChanges(t, w))
+}
+
+func TestCloseUnblocksWaitForChanges(t *testing.T) {
+	w, _ := newTestWatcher(t)
+
+	errChannel := make(chan error, 1)
+	go func() {
+		_, err := w.WaitForChanges(testDebounce)
+		errChannel <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, w.Close())
+
+	select {
+	case err := <-errChannel:
+		assert.ErrorIs(t, err, ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("WaitForChanges didn't return after Close")
+	}
+}
+
+func TestShouldIgnore(t *testing.T) {
+	assert.False(t, shouldIgnore("."))
+	assert.False(t, shouldIgnore("main.c"))
+	assert.False(t, shouldIgnore("src/main.c"))
+	assert.True(t, shouldIgnore(".git"))
+	assert.True(t, shouldIgnore(".bootllm/last-run.json"))
+	assert.True(t, shouldIgnore("main.c~"))
+	assert.True(t, shouldIgnore("4913"))
+}